@@ -0,0 +1,170 @@
+/*
+dicejack.go
+
+Dice blackjack: keep rolling a die and adding
+it to a running total, stopping as close to a
+target total as possible without going over
+*/
+package games
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+	"github.com/romansod/roll-dice/internal/utilities"
+)
+
+const ErrInvalidJackTarget string = "invalid target total: must be at least %d"
+
+// Default target total
+const JackTarget int = 21
+
+// Input asking for another roll
+const HitCmd string = "h"
+
+// Input keeping the current total
+const StandCmd string = "s"
+
+type DiceJack struct {
+	target int           // total to get as close to as possible
+	nSides int           // number of sides of the die rolled
+	total  int           // sum of the rolls so far
+	rolls  []int         // value of every roll so far
+	prng   func(int) int // dice roller, returns a number in [0, n)
+}
+
+// Initialize private fields
+//
+//	Params
+//		target int         : total to get close to without going over. Ex: 21
+//		nSides int         : number of sides of the die. Ex: 6
+//		prng func(int) int : dice roller, nil uses probgen.RandNumGen
+//	Returns
+//		*DiceJack : new DiceJack object
+func NewDiceJack(target int, nSides int, prng func(int) int) *DiceJack {
+	if prng == nil {
+		prng = probgen.RandNumGen
+	}
+
+	return &DiceJack{
+		target: target,
+		nSides: nSides,
+		rolls:  []int{},
+		prng:   prng,
+	}
+}
+
+// Check whether the target total can be played, ie more than one roll
+//
+//	Params
+//		target int : total to get close to
+//		nSides int : number of sides of the die
+//	Returns
+//		bool : true if the target is above the highest single roll
+func ValidJackTarget(target int, nSides int) bool {
+	return target > nSides
+}
+
+// Roll the die and add it to the total
+//
+//	Returns
+//		int : the die value in the range [1, nSides]
+func (diceJack *DiceJack) hit() int {
+	roll := probgen.ExecuteOneRollActionWith(diceJack.nSides, diceJack.prng) + 1
+	diceJack.rolls = append(diceJack.rolls, roll)
+	diceJack.total += roll
+
+	return roll
+}
+
+// Check whether the total went over the target
+//
+//	Returns
+//		bool : true if the total is above the target
+func (diceJack DiceJack) IsBust() bool {
+	return diceJack.total > diceJack.target
+}
+
+// How far the total stopped from the target. A bust has no distance
+//
+//	Returns
+//		int  : target minus total, 0 when exactly on target
+//		bool : false if the total went over the target
+func (diceJack DiceJack) Distance() (int, bool) {
+	if diceJack.IsBust() {
+		return -1, false
+	}
+
+	return diceJack.target - diceJack.total, true
+}
+
+// Main driver for playing Dice Jack. Rolls until the player stands, busts or
+// reaches the target exactly
+func (diceJack *DiceJack) Run() {
+	diceJack.RunWith(os.Stdin)
+}
+
+// Main driver for playing Dice Jack, reading all input from the given reader
+//
+//	Params
+//		stdin io.Reader : holds user input
+func (diceJack *DiceJack) RunWith(stdin io.Reader) {
+	for {
+		roll := diceJack.hit()
+		fmt.Printf("\nRolled %d, total %d of %d\n", roll, diceJack.total, diceJack.target)
+
+		if diceJack.IsBust() {
+			fmt.Printf("\nBust! %d is over %d\n", diceJack.total, diceJack.target)
+			return
+		}
+
+		if diceJack.total == diceJack.target {
+			fmt.Printf("\nExactly %d!\n", diceJack.target)
+			return
+		}
+
+		done, hit := chooseHit(stdin)
+		if done || !hit {
+			distance, _ := diceJack.Distance()
+			fmt.Printf("\nStanding on %d, %d away from %d\n", diceJack.total, distance, diceJack.target)
+			return
+		}
+	}
+}
+
+// Prompt whether the user wants another roll. Will handle invalid inputs
+// and prompt for input again, up to utilities.MaxAttempts times
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool : true if user indicates they are done
+//		bool : true to roll again, false to stand
+func chooseHit(stdin io.Reader) (bool, bool) {
+	for attempts := 1; ; attempts++ {
+		fmt.Printf("Hit or stand? [%s/%s]\n", HitCmd, StandCmd)
+		done, input := utilities.ProcessInputStr(stdin)
+
+		// Inform caller we are done
+		if done {
+			return true, false
+		}
+
+		switch strings.ToLower(input) {
+		case HitCmd:
+			return false, true
+		case StandCmd:
+			return false, false
+		}
+
+		fmt.Printf("input error: expected '%s' or '%s'\n", HitCmd, StandCmd)
+		if err := utilities.CheckAttempts(attempts); err != nil {
+			// Too many invalid inputs, treat as done
+			fmt.Print(err.Error() + "\n")
+			return true, false
+		}
+	}
+}
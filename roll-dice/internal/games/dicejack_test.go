@@ -0,0 +1,61 @@
+package games
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+	"github.com/romansod/roll-dice/internal/testing_utils"
+)
+
+func TestDiceJackBust(t *testing.T) {
+	// Going over the target ends the game as a bust
+
+	diceJack := NewDiceJack(JackTarget, probgen.D6, fixedRolls(6, 6, 6, 5))
+	origStdout, r, w := testing_utils.RedirectStdout()
+	diceJack.RunWith(bytes.NewBufferString("h\nh\nh\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQSlice(t, []int{6, 6, 6, 5}, diceJack.rolls)
+	testing_utils.AssertEQi(t, 23, diceJack.total)
+	testing_utils.AssertEQb(t, true, diceJack.IsBust())
+	_, ok := diceJack.Distance()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nBust! 23 is over 21\n"))
+
+	// The target itself is not a bust
+	diceJack = NewDiceJack(12, probgen.D6, fixedRolls(6, 6))
+	diceJack.hit()
+	diceJack.hit()
+	testing_utils.AssertEQb(t, false, diceJack.IsBust())
+}
+
+func TestDiceJackTarget(t *testing.T) {
+	// Reaching the target exactly stands automatically
+
+	diceJack := NewDiceJack(JackTarget, probgen.D6, fixedRolls(6, 5, 4, 6))
+	origStdout, r, w := testing_utils.RedirectStdout()
+	diceJack.RunWith(bytes.NewBufferString("h\nH\nh\ns\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQi(t, 21, diceJack.total)
+	distance, ok := diceJack.Distance()
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertEQi(t, 0, distance)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nExactly 21!\n"))
+
+	// Standing short of the target
+	diceJack = NewDiceJack(JackTarget, probgen.D6, fixedRolls(6, 5))
+	origStdout, r, w = testing_utils.RedirectStdout()
+	diceJack.RunWith(bytes.NewBufferString("x\nh\ns\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	distance, _ = diceJack.Distance()
+	testing_utils.AssertEQi(t, 10, distance)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "input error: expected 'h' or 's'\n"))
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nStanding on 11, 10 away from 21\n"))
+
+	testing_utils.AssertEQb(t, true, ValidJackTarget(JackTarget, probgen.D6))
+	testing_utils.AssertEQb(t, false, ValidJackTarget(probgen.D6, probgen.D6))
+}
@@ -0,0 +1,180 @@
+/*
+practice.go
+
+Mental math drill on a Shut the Box board:
+find every combination of open slots that
+adds up to a dealt target
+*/
+package games
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/romansod/roll-dice/internal/utilities"
+)
+
+const ErrAlreadyFound string = "combination already found"
+
+type Practice struct {
+	gameState int     // board the combinations are found on
+	boxSize   int     // total number of slots
+	target    int     // sum every combination must reach
+	solutions [][]int // every legal combination, see FindAllSolutions
+	found     []bool  // parallel to solutions, true once entered
+}
+
+// Initialize private fields for a known board and target
+//
+//	Params
+//		gstate int : game state bitset of the board
+//		size int   : total number of slots
+//		target int : sum every combination must reach. Ex: 9
+//	Returns
+//		*Practice : new Practice object
+func NewPractice(gstate int, size int, target int) *Practice {
+	solutions := FindAllSolutions(gstate, target)
+
+	return &Practice{
+		gameState: gstate,
+		boxSize:   size,
+		target:    target,
+		solutions: solutions,
+		found:     make([]bool, len(solutions)),
+	}
+}
+
+// Deal a random board and a target rolled with two dice, dealing again
+// until the target has at least one combination
+//
+//	Params
+//		size int           : total number of slots
+//		prng func(int) int : dealer, nil uses the real generator
+//	Returns
+//		*Practice : new Practice object
+func DealPractice(size int, prng func(int) int) *Practice {
+	if prng == nil {
+		prng = rand.Intn
+	}
+
+	for {
+		gstate := prng(OpenBoxOf(size)) + 1
+		target := prng(6) + prng(6) + 2
+
+		practice := NewPractice(gstate, size, target)
+		if len(practice.solutions) > 0 {
+			return practice
+		}
+	}
+}
+
+// Mark the combination entered as found
+//
+//	Params
+//		update string : open slots entered like a move. Ex: "137" or "1,10"
+//	Returns
+//		error : why the combination is not legal, or ErrAlreadyFound
+func (practice *Practice) guess(update string) error {
+	digits, err := validateProposedUpdate(practice.gameState, practice.boxSize, update, practice.target)
+	if err != nil {
+		return err
+	}
+
+	sort.Ints(digits)
+	i := slices.IndexFunc(practice.solutions, func(solution []int) bool {
+		return slices.Equal(solution, digits)
+	})
+
+	// Every legal combination is one of the solutions
+	if practice.found[i] {
+		return errors.New(ErrAlreadyFound)
+	}
+
+	practice.found[i] = true
+	return nil
+}
+
+// How many of the combinations were found
+//
+//	Returns
+//		int : combinations found
+//		int : combinations in total
+func (practice Practice) Coverage() (int, int) {
+	found := 0
+	for _, f := range practice.found {
+		if f {
+			found++
+		}
+	}
+
+	return found, len(practice.solutions)
+}
+
+// Print how many combinations were found and every one that was missed
+//
+// Ex:
+//
+//	Found 2 of 3 combinations
+//
+//	Missed:
+//
+//	27
+func (practice Practice) printCoverage() {
+	found, total := practice.Coverage()
+	fmt.Printf("\nFound %d of %d combinations\n", found, total)
+	if found == total {
+		return
+	}
+
+	fmt.Print("\nMissed:\n\n")
+	for i, solution := range practice.solutions {
+		if !practice.found[i] {
+			fmt.Print(SolutionToInput(solution) + "\n")
+		}
+	}
+}
+
+// Main driver for the practice drill. Reads combinations until every one is
+// found or the user is done, then reports the coverage
+func (practice *Practice) Run() {
+	practice.RunWith(os.Stdin)
+}
+
+// Main driver for the practice drill, reading all input from the given
+// reader
+//
+//	Params
+//		stdin io.Reader : holds user input
+func (practice *Practice) RunWith(stdin io.Reader) {
+	defer func() { practice.printCoverage() }()
+
+	fmt.Printf(
+		"\n%s\n\nTarget sum is '%d' . Please enter every combination of open slots adding up to it, one per line (empty line when done):\n",
+		AssembleSlotsToDisplay(practice.gameState, practice.boxSize),
+		practice.target)
+
+	for {
+		found, total := practice.Coverage()
+		if found == total {
+			fmt.Print("\nAll combinations found!\n")
+			return
+		}
+
+		done, input := utilities.ProcessInputStr(stdin)
+		if done {
+			return
+		}
+
+		if err := practice.guess(input); err != nil {
+			fmt.Printf("%s\n", err.Error())
+			continue
+		}
+
+		fmt.Printf("Found %d of %d\n", found+1, total)
+	}
+}
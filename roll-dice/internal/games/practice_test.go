@@ -0,0 +1,64 @@
+package games
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/romansod/roll-dice/internal/testing_utils"
+)
+
+func TestPracticeCoverage(t *testing.T) {
+	// Slots 1 to 5 open for target 7 has three combinations: 25, 34 and 124
+
+	board := ConvertSlotsToGameState("[1][2][3][4][5][_][_][_][_]", SizeBox)
+	practice := NewPractice(board, SizeBox, 7)
+	found, total := practice.Coverage()
+	testing_utils.AssertEQi(t, 0, found)
+	testing_utils.AssertEQi(t, 3, total)
+
+	// Entered in any order, repeats and illegal combinations are not counted
+	origStdout, r, w := testing_utils.RedirectStdout()
+	practice.RunWith(bytes.NewBufferString("52\n3,4\n43\n9\n12\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	found, _ = practice.Coverage()
+	testing_utils.AssertEQi(t, 2, found)
+	testing_utils.AssertEQ(t, "[true true false]", fmt.Sprint(practice.found))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Target sum is '7'"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Found 1 of 3\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Found 2 of 3\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, ErrAlreadyFound+"\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "slot 9 is already closed. Please try again\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, fmt.Sprintf(ErrNotEqTarget, 3, 7)+"\n"))
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nFound 2 of 3 combinations\n\nMissed:\n\n124\n"))
+
+	// Finding every combination ends the drill without an empty line
+	practice = NewPractice(board, SizeBox, 7)
+	origStdout, r, w = testing_utils.RedirectStdout()
+	practice.RunWith(bytes.NewBufferString("124\n25\n34\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nAll combinations found!\n\nFound 3 of 3 combinations\n"))
+}
+
+func TestDealPractice(t *testing.T) {
+	// Deals again until the target has a combination:
+	//
+	// only slot 1 open for target 12 has none
+	// only slot 9 open for target 9 has one
+
+	deals := []int{0, 5, 5, 255, 3, 4}
+	i := 0
+	practice := DealPractice(SizeBox, func(int) int {
+		deal := deals[i]
+		i++
+		return deal
+	})
+
+	testing_utils.AssertEQi(t, len(deals), i)
+	testing_utils.AssertEQ(t, "[_][_][_][_][_][_][_][_][9]", AssembleSlotsToDisplay(practice.gameState, SizeBox))
+	testing_utils.AssertEQi(t, 9, practice.target)
+	testing_utils.AssertEQ(t, "[[9]]", fmt.Sprint(practice.solutions))
+}
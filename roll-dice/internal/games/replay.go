@@ -0,0 +1,132 @@
+/*
+replay.go
+
+Recording of a Shut the Box game's rolls
+and inputs, replayed deterministically
+*/
+package games
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const ErrReplayPlayers string = "invalid game record: no players"
+const ErrReplayRolls string = "invalid game record: ran out of rolls after %d"
+
+// Everything needed to play a game again exactly as it was played
+type GameRecord struct {
+	Players  []string `json:"players"`
+	AI       []bool   `json:"ai,omitempty"`
+	BoxSize  int      `json:"box_size"`
+	DiceMode DiceMode `json:"dice_mode,omitempty"`
+	NumDice  int      `json:"num_dice,omitempty"`
+	Rounds   int      `json:"rounds,omitempty"`
+	Rolls    []int    `json:"rolls"`  // every die value in the order rolled
+	Inputs   []string `json:"inputs"` // every input line in the order entered
+}
+
+// Play the game as RunWith does, while recording every die rolled and
+// every input line read for ReplayGame
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		GameRecord : the game as played
+func (shutTheBox ShutTheBox) RecordGame(stdin io.Reader) GameRecord {
+	record := GameRecord{
+		Players:  shutTheBox.players,
+		AI:       shutTheBox.ai,
+		BoxSize:  shutTheBox.boxSize,
+		DiceMode: shutTheBox.diceMode,
+		NumDice:  shutTheBox.numDice,
+		Rounds:   shutTheBox.rounds,
+		Rolls:    []int{},
+	}
+
+	prng := shutTheBox.prng
+	shutTheBox.prng = func(n int) int {
+		roll := prng(n)
+		record.Rolls = append(record.Rolls, GetSlotValue(roll))
+		return roll
+	}
+
+	// Input is read one byte at a time, so only consumed lines are recorded
+	var input bytes.Buffer
+	shutTheBox.RunWith(io.TeeReader(stdin, &input))
+
+	// Lines ending in CRLF are recorded without the carriage return
+	record.Inputs = []string{}
+	if input.Len() > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(input.String(), "\n"), "\n") {
+			record.Inputs = append(record.Inputs, strings.TrimSuffix(line, "\r"))
+		}
+	}
+
+	return record
+}
+
+// Play a recorded game again with its rolls and inputs, displaying it as it
+// is played
+//
+//	Params
+//		rec GameRecord : the game to replay, see RecordGame
+//	Returns
+//		[]string : every game state displayed, in order. Ex: "[1][_][3]..."
+//		error    : an invalid record, or one with fewer rolls than played
+func ReplayGame(rec GameRecord) ([]string, error) {
+	if len(rec.Players) == 0 {
+		return nil, errors.New(ErrReplayPlayers)
+	}
+
+	if !ValidBoxSize(rec.BoxSize) {
+		return nil, fmt.Errorf(ErrInvalidBoxSize, SizeBox, MaxSizeBox)
+	}
+
+	// Records without a number of dice used the default
+	if rec.NumDice == 0 {
+		rec.NumDice = NumDice
+	}
+
+	if !ValidNumDice(rec.NumDice) {
+		return nil, fmt.Errorf(ErrInvalidNumDice, NumDice, MaxNumDice)
+	}
+
+	// Every roll is replayed in order, any roll past the end is invalid
+	rolled := 0
+	prng := func(int) int {
+		rolled++
+		if rolled > len(rec.Rolls) {
+			return 0
+		}
+
+		return GetValueSlot(rec.Rolls[rolled-1])
+	}
+
+	shutTheBox := NewShutBox(rec.Players, rec.BoxSize, rec.DiceMode, rec.NumDice, prng)
+	// Records without AI players omit the flags
+	if len(rec.AI) > 0 {
+		if err := shutTheBox.SetAI(rec.AI); err != nil {
+			return nil, err
+		}
+	}
+	shutTheBox.SetRounds(rec.Rounds)
+
+	states := []string{}
+	shutTheBox.states = &states
+
+	input := ""
+	for _, line := range rec.Inputs {
+		input += line + "\n"
+	}
+	shutTheBox.RunWith(strings.NewReader(input))
+
+	if rolled > len(rec.Rolls) {
+		return states, fmt.Errorf(ErrReplayRolls, len(rec.Rolls))
+	}
+
+	return states, nil
+}
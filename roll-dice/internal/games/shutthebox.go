@@ -6,10 +6,18 @@ ShutTheBox is a game which uses dice rolls
 package games
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/bits"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/romansod/roll-dice/internal/probgen"
 	"github.com/romansod/roll-dice/internal/utilities"
@@ -17,40 +25,488 @@ import (
 
 /// Errors
 
-const ErrInvDigit string = "invalid digit input not in range [1,9]"
+const ErrInvDigit string = "invalid digit input not in range [1,%d]"
+const ErrDuplicateDigit string = "invalid input: digit %d entered more than once"
 const ErrNotEqTarget string = "input '%d' does not add up to target '%d'"
+const ErrInvalidBoxSize string = "invalid box size: must be in range [%d,%d]"
+const ErrNothingToUndo string = "nothing to undo for this roll"
+const ErrSavedGameState string = "invalid saved game: game state '%d' does not fit in a box of size %d"
+const ErrSavedPlayer string = "invalid saved game: player index '%d' not in range [0,%d)"
+const ErrSavedScores string = "invalid saved game: %d scores for %d players"
+const ErrSavedDiceMode string = "invalid saved game: unknown dice mode '%d'"
+const ErrInvalidDiceMode string = "invalid dice mode: expected 'a', '1' or 'h'"
+const ErrInvalidNumDice string = "invalid number of dice: must be in range [%d,%d]"
+const ErrAIPlayers string = "invalid AI players: %d flags for %d players"
 
-// Total number of slots
+// Default total number of slots
 const SizeBox int = 9
 
-// Initial open box
+// Largest supported total number of slots, as in the 1-12 variant
+const MaxSizeBox int = 12
+
+// Default number of D6 summed for the target
+const NumDice int = 2
+
+// Largest supported number of D6 summed for the target
+const MaxNumDice int = 3
+
+// Initial open box of the default size
 const OpenBox int = (1 << SizeBox) - 1
 
 // Fully shut box
 const ShutBox int = 0
 
+// Lowest slot value which must be shut before rolling a single die
+const HighSlot int = 7
+
+// Input requesting every solution for the current target
+const HintCmd string = "hint"
+
+// Input taking back the move of the current roll before rolling again
+const UndoCmd string = "undo"
+
+// Input requesting the chance of each 2d6 target
+const StatsCmd string = "stats"
+
+// Input after a win continuing with the next player in the rotation
+const NextCmd string = "next"
+
+// Input after a win starting a new game with the same players, from player 1
+const RestartCmd string = "restart"
+
+// Input after a win starting a new game with a new list of players
+const ChangeCmd string = "change"
+
+// Input after a win ending the game
+const StopCmd string = "stop"
+
 // Slot display for formatting
 const Slot string = "[%s]"
 
 // Empty slot display value
 const EmptySlot string = "_"
 
+// How each slot is drawn: an open slot shows its value and a closed slot
+// the empty marker, both between the brackets
+//
+//	Ex: {"[", "]", "_"} -> [1][_][3]
+//	Ex: {"(", ")", " "} -> (1)( )(3)
+//	Ex: {"[", "]", "X"} -> [1][X][3]
+type SlotStyle struct {
+	Open  string // left bracket
+	Close string // right bracket
+	Empty string // closed slot marker
+}
+
+// Default slot style, see Slot and EmptySlot
+var DefaultSlotStyle = SlotStyle{Open: "[", Close: "]", Empty: EmptySlot}
+
+// Color of the slots closed by the last move, when highlighted
+const ClosedColor = probgen.ColorYellow
+
 type ShutTheBox struct {
-	gameState int      // game state stored as 9 bits
-	players   []string // names of the players for this game
-	player_i  int      // current player
+	gameState   int              // game state stored as boxSize bits
+	boxSize     int              // total number of slots
+	players     []string         // names of the players for this game
+	player_i    int              // current player
+	scores      []int            // accumulated score of each player, lowest is best
+	prng        func(int) int    // dice roller, returns a number in [0, n)
+	ai          []bool           // whether each player's turns are played automatically
+	undoStack   []int            // game states before each update of the current roll
+	rounds      int              // rounds in a match, 0 plays until the players stop
+	roundScores [][]int          // score of each player in every round so far
+	diceMode    DiceMode         // how many dice are rolled each roll
+	numDice     int              // number of D6 summed for a full roll
+	verbose     bool             // print each die and the move made every roll
+	states      *[]string        // every game state displayed, when replaying
+	style       SlotStyle        // how the slots are drawn
+	clock       func() time.Time // turn timer, nil when moves are not timed
+	moveTimes   []time.Duration  // time each player took to move, when timed
+	stats       *matchStats      // counters of the current match
+	setup       PlayerSetup      // prompts for new players, nil when they cannot change
+	highlight   bool             // color the slots closed by the last move
+	lastClosed  int              // bits closed by the last update of the current turn
+}
+
+// Prompt for a new list of players, and whether each is played by the AI
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool     : true if user indicates they are done
+//		[]string : names of the players
+//		[]bool   : parallel to the players, true for an AI player
+type PlayerSetup func(stdin io.Reader) (bool, []string, []bool)
+
+// What to do after a player shuts the box outside of a match
+type afterWin int
+
+const (
+	afterWinStop    afterWin = iota // end the game
+	afterWinNext                    // next player in the rotation
+	afterWinRestart                 // new game from player 1
+	afterWinChange                  // new game with new players
+)
+
+// Strategy used by the AI to pick among the legal moves
+type Strategy int
+
+const (
+	StrategyHighest Strategy = iota // close the highest value slots
+	StrategyMost                    // close the most slots
+)
+
+// Counters of a match, reset at the start of every RunWith
+type matchStats struct {
+	rolls     int   // rolls made by every player
+	busts     int   // turns ending with no legal move
+	shuts     []int // turns ending with the box shut, per player
+	remaining int   // sum of the slots left open by every bust
+}
+
+// Number of D6 rolled for the whole game
+type DiceMode int
+
+const (
+	DiceHybrid DiceMode = iota // all dice, one die allowed once the high slots are shut
+	DiceAll                    // always all dice
+	DiceOne                    // always one die
+)
+
+// Exported form of an in-progress game persisted by SaveGame
+//
+// NOTE: the dice roller is not saved, a resumed game rolls randomly
+type SavedGame struct {
+	GameState   int      `json:"game_state"`
+	BoxSize     int      `json:"box_size"`
+	Players     []string `json:"players"`
+	PlayerI     int      `json:"player_i"`
+	Scores      []int    `json:"scores"`
+	AI          []bool   `json:"ai,omitempty"`
+	Rounds      int      `json:"rounds,omitempty"`
+	RoundScores [][]int  `json:"round_scores,omitempty"`
+	DiceMode    DiceMode `json:"dice_mode,omitempty"`
+	NumDice     int      `json:"num_dice,omitempty"`
+}
+
+// Placement of a player on the scoreboard
+type Rank struct {
+	Place  int    // shared by players with the same score
+	Player string // name of the player
+	Score  int    // accumulated score of the player
 }
 
 // Initialize private fields
 //
+//	Params
+//		allPlayers []string : names of the players
+//		boxSize int         : total number of slots. Ex: 9 or 12
+//		diceMode DiceMode   : number of dice rolled for the whole game
+//		numDice int         : number of D6 summed for a full roll. Ex: 2 or 3
+//		prng func(int) int  : dice roller, nil uses probgen.RandNumGen
 //	Returns
 //		*ShutTheBox : new ShutTheBox object
-func NewShutBox(allPlayers []string) *ShutTheBox {
+func NewShutBox(allPlayers []string, boxSize int, diceMode DiceMode, numDice int, prng func(int) int) *ShutTheBox {
+	if prng == nil {
+		prng = probgen.RandNumGen
+	}
+
 	return &ShutTheBox{
-		gameState: OpenBox, // game state stored as 9 bits
+		gameState: OpenBoxOf(boxSize),
+		boxSize:   boxSize,
 		players:   allPlayers,
 		player_i:  0,
+		scores:    make([]int, len(allPlayers)),
+		ai:        make([]bool, len(allPlayers)),
+		diceMode:  diceMode,
+		numDice:   numDice,
+		prng:      prng,
+		style:     DefaultSlotStyle,
+		stats:     &matchStats{shuts: make([]int, len(allPlayers))},
+	}
+}
+
+// Initialize a match of a fixed number of rounds in the default box. Every
+// player takes one turn per round and the lowest total score wins
+//
+//	Params
+//		allPlayers []string : names of the players
+//		rounds int          : number of rounds in the match
+//	Returns
+//		*ShutTheBox : new ShutTheBox object
+func NewShutBoxMatch(allPlayers []string, rounds int) *ShutTheBox {
+	shutTheBox := NewShutBox(allPlayers, SizeBox, DiceHybrid, NumDice, nil)
+	shutTheBox.SetRounds(rounds)
+
+	return shutTheBox
+}
+
+// Play a match of a fixed number of rounds
+//
+//	Params
+//		rounds int : number of rounds in the match, 0 plays until the
+//		             players stop
+func (shutTheBox *ShutTheBox) SetRounds(rounds int) {
+	shutTheBox.rounds = rounds
+}
+
+// Print each individual die and the slots closed by every move, for
+// debugging and teaching
+//
+//	Params
+//		verbose bool : true to print the dice and moves
+func (shutTheBox *ShutTheBox) SetVerbose(verbose bool) {
+	shutTheBox.verbose = verbose
+}
+
+// Color the slots closed by the last move when the game state is printed.
+// Output stays plain when color is disabled, see probgen.UseColor
+//
+//	Params
+//		highlight bool : true to color the slots just closed
+func (shutTheBox *ShutTheBox) SetHighlight(highlight bool) {
+	shutTheBox.highlight = highlight
+}
+
+// Draw the slots with the given brackets and closed slot marker
+//
+//	Params
+//		style SlotStyle : how the slots are drawn. Ex: {"(", ")", " "}
+func (shutTheBox *ShutTheBox) SetSlotStyle(style SlotStyle) {
+	shutTheBox.style = style
+}
+
+// Time every move, from the roll to the valid move, and print each player's
+// total time when the game ends
+//
+//	Params
+//		clock func() time.Time : current time, ex: time.Now. nil stops timing
+func (shutTheBox *ShutTheBox) SetClock(clock func() time.Time) {
+	shutTheBox.clock = clock
+	shutTheBox.moveTimes = make([]time.Duration, len(shutTheBox.players))
+}
+
+// Add the time since the roll to the current player's move time, if timed
+//
+//	Params
+//		rolledAt time.Time : when the roll being moved on was made
+func (shutTheBox ShutTheBox) recordMoveTime(rolledAt time.Time) {
+	if shutTheBox.clock != nil {
+		shutTheBox.moveTimes[shutTheBox.player_i] += shutTheBox.clock().Sub(rolledAt)
+	}
+}
+
+// Print the total time each player took to move, in player order
+//
+// Ex:
+//
+// Time per player:
+//
+// p1 : 1m5s
+//
+// p2 : 48s
+func (shutTheBox ShutTheBox) printMoveTimes() {
+	fmt.Print("\nTime per player:\n\n")
+	for i, player := range shutTheBox.players {
+		fmt.Printf("%s : %s\n", player, shutTheBox.moveTimes[i])
+	}
+}
+
+// Average sum of the slots left open by every finished turn, a shut box
+// leaving 0
+//
+//	Returns
+//		float64 : average remaining score, 0 before any turn is finished
+func (stats matchStats) averageRemaining() float64 {
+	turns := stats.busts
+	for _, shuts := range stats.shuts {
+		turns += shuts
+	}
+
+	if turns == 0 {
+		return 0
+	}
+
+	return float64(stats.remaining) / float64(turns)
+}
+
+// Print the counters of the match, with the shut boxes in player order
+//
+// Ex:
+//
+// Match statistics:
+//
+// Rolls : 7
+// Busts : 2
+// Average remaining : 38.50
+//
+// Shut boxes:
+//
+// p1 : 0
+//
+// p2 : 1
+func (shutTheBox ShutTheBox) printMatchStats() {
+	stats := shutTheBox.stats
+	fmt.Printf(
+		"\nMatch statistics:\n\nRolls : %d\nBusts : %d\nAverage remaining : %.2f\n",
+		stats.rolls,
+		stats.busts,
+		stats.averageRemaining())
+
+	fmt.Print("\nShut boxes:\n\n")
+	for i, player := range shutTheBox.players {
+		fmt.Printf("%s : %d\n", player, stats.shuts[i])
+	}
+}
+
+// Allow the players to be changed after a win, prompting for them with the
+// given setup
+//
+//	Params
+//		setup PlayerSetup : prompts for the new players, nil stops changes
+func (shutTheBox *ShutTheBox) SetPlayerSetup(setup PlayerSetup) {
+	shutTheBox.setup = setup
+}
+
+// Start a new game from the first player with an open box, forgetting the
+// scores, times and counters of the previous game
+//
+//	Params
+//		players []string : names of the players for the new game
+//		ai []bool        : parallel to the players, true for an AI player
+func (shutTheBox *ShutTheBox) restart(players []string, ai []bool) {
+	shutTheBox.players = players
+	shutTheBox.ai = ai
+	shutTheBox.player_i = 0
+	shutTheBox.scores = make([]int, len(players))
+	shutTheBox.roundScores = nil
+	shutTheBox.resetBox()
+
+	*shutTheBox.stats = matchStats{shuts: make([]int, len(players))}
+	if shutTheBox.clock != nil {
+		shutTheBox.moveTimes = make([]time.Duration, len(players))
+	}
+}
+
+// Mark which players are played automatically by the AI. A slice of the
+// wrong length is rejected and the players are left as they were
+//
+//	Params
+//		ai []bool : parallel to the players, true for an AI player
+//	Returns
+//		error : ErrAIPlayers unless there is one flag per player
+func (shutTheBox *ShutTheBox) SetAI(ai []bool) error {
+	if len(ai) != len(shutTheBox.players) {
+		return fmt.Errorf(ErrAIPlayers, len(ai), len(shutTheBox.players))
+	}
+
+	shutTheBox.ai = ai
+	return nil
+}
+
+// Check whether the current player is played by the AI
+//
+//	Returns
+//		bool : true if the current player is an AI player
+func (shutTheBox ShutTheBox) isAI() bool {
+	return shutTheBox.ai[shutTheBox.player_i]
+}
+
+// Seed the dice roller so every game with the same challenge seed faces the
+// identical sequence of rolls
+//
+//	Params
+//		seed int64 : challenge seed shared by the competing players
+func (shutTheBox *ShutTheBox) SetChallengeSeed(seed int64) {
+	shutTheBox.prng = probgen.NewSeededPRNG(seed)
+}
+
+// Persist the in-progress game to a JSON file
+//
+//	Params
+//		path string : file to write the game to
+//	Returns
+//		error : any error encountered writing the file
+func (shutTheBox ShutTheBox) SaveGame(path string) error {
+	data, err := json.Marshal(SavedGame{
+		GameState:   shutTheBox.gameState,
+		BoxSize:     shutTheBox.boxSize,
+		Players:     shutTheBox.players,
+		PlayerI:     shutTheBox.player_i,
+		Scores:      shutTheBox.scores,
+		AI:          shutTheBox.ai,
+		Rounds:      shutTheBox.rounds,
+		RoundScores: shutTheBox.roundScores,
+		DiceMode:    shutTheBox.diceMode,
+		NumDice:     shutTheBox.numDice,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Restore a game persisted by SaveGame, validating that the game state fits
+// within the box and the current player is one of the players
+//
+//	Params
+//		path string : file to read the game from
+//	Returns
+//		*ShutTheBox : the restored game
+//		error       : any error encountered reading or validating the file
+func LoadGame(path string) (*ShutTheBox, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+
+	if !ValidBoxSize(saved.BoxSize) {
+		return nil, fmt.Errorf(ErrInvalidBoxSize, SizeBox, MaxSizeBox)
+	}
+
+	if saved.GameState < ShutBox || saved.GameState > OpenBoxOf(saved.BoxSize) {
+		return nil, fmt.Errorf(ErrSavedGameState, saved.GameState, saved.BoxSize)
+	}
+
+	if saved.PlayerI < 0 || saved.PlayerI >= len(saved.Players) {
+		return nil, fmt.Errorf(ErrSavedPlayer, saved.PlayerI, len(saved.Players))
+	}
+
+	if len(saved.Scores) != len(saved.Players) {
+		return nil, fmt.Errorf(ErrSavedScores, len(saved.Scores), len(saved.Players))
+	}
+
+	if saved.DiceMode < DiceHybrid || saved.DiceMode > DiceOne {
+		return nil, fmt.Errorf(ErrSavedDiceMode, saved.DiceMode)
+	}
+
+	// Games saved before the number of dice was configurable used the default
+	if saved.NumDice == 0 {
+		saved.NumDice = NumDice
+	}
+
+	if !ValidNumDice(saved.NumDice) {
+		return nil, fmt.Errorf(ErrInvalidNumDice, NumDice, MaxNumDice)
+	}
+
+	shutTheBox := NewShutBox(saved.Players, saved.BoxSize, saved.DiceMode, saved.NumDice, nil)
+	shutTheBox.gameState = saved.GameState
+	shutTheBox.player_i = saved.PlayerI
+	shutTheBox.scores = saved.Scores
+	if len(saved.AI) == len(saved.Players) {
+		shutTheBox.ai = saved.AI
 	}
+
+	shutTheBox.rounds = saved.Rounds
+	shutTheBox.roundScores = saved.RoundScores
+
+	return shutTheBox, nil
 }
 
 // Set current player to the next player
@@ -58,9 +514,29 @@ func (shutTheBox *ShutTheBox) nextPlayer() {
 	shutTheBox.player_i = (shutTheBox.player_i + 1) % len(shutTheBox.players)
 }
 
-// Fully open the box for the next turn
+// Fully open the box for the next turn, which can no longer undo the
+// updates of the previous turn
 func (shutTheBox *ShutTheBox) resetBox() {
-	shutTheBox.gameState = OpenBox
+	shutTheBox.gameState = OpenBoxOf(shutTheBox.boxSize)
+	shutTheBox.undoStack = nil
+	shutTheBox.lastClosed = 0
+}
+
+// Restore the game state from before the last update for the current roll
+//
+//	Returns
+//		error : ErrNothingToUndo if no update was made for this roll
+func (shutTheBox *ShutTheBox) undo() error {
+	last := len(shutTheBox.undoStack) - 1
+	if last < 0 {
+		return errors.New(ErrNothingToUndo)
+	}
+
+	shutTheBox.gameState = shutTheBox.undoStack[last]
+	shutTheBox.undoStack = shutTheBox.undoStack[:last]
+	shutTheBox.lastClosed = 0
+
+	return nil
 }
 
 // The next turn requires opening the box and selecting the next player
@@ -69,41 +545,162 @@ func (shutTheBox *ShutTheBox) nextTurn() {
 	shutTheBox.nextPlayer()
 }
 
+// Add the sum of the slots left open to the current player's score and
+// their score for the round. A shut box scores 0
+func (shutTheBox *ShutTheBox) scoreTurn() {
+	score := SumOpenSlots(shutTheBox.gameState)
+	shutTheBox.scores[shutTheBox.player_i] += score
+
+	// The first player starts a new round
+	if shutTheBox.player_i == 0 || len(shutTheBox.roundScores) == 0 {
+		shutTheBox.roundScores = append(shutTheBox.roundScores, make([]int, len(shutTheBox.players)))
+	}
+
+	shutTheBox.roundScores[len(shutTheBox.roundScores)-1][shutTheBox.player_i] = score
+}
+
+// Score the current player's turn and move on to the next turn. Once every
+// player has had a turn the scoreboard is printed, and once every round of
+// a match is played the final standings are printed
+//
+//	Returns
+//		bool : true if the match is over
+func (shutTheBox *ShutTheBox) finishTurn() bool {
+	shutTheBox.scoreTurn()
+
+	if shutTheBox.player_i == len(shutTheBox.players)-1 {
+		shutTheBox.printScoreboard()
+
+		if shutTheBox.rounds > 0 && len(shutTheBox.roundScores) >= shutTheBox.rounds {
+			shutTheBox.printStandings()
+			return true
+		}
+	}
+
+	shutTheBox.nextTurn()
+	return false
+}
+
 // Main driver for playing Shut the Box game. Handles turns and playing after
 // winning or losing
 func (shutTheBox ShutTheBox) Run() {
+	shutTheBox.RunWith(os.Stdin)
+}
+
+// Main driver for playing Shut the Box game, reading all input from the
+// given reader
+//
+//	Params
+//		stdin io.Reader : holds user input
+func (shutTheBox ShutTheBox) RunWith(stdin io.Reader) {
+	// Printed for the players at the end, who may change after a win
+	if shutTheBox.clock != nil {
+		defer func() { shutTheBox.printMoveTimes() }()
+	}
+
+	*shutTheBox.stats = matchStats{shuts: make([]int, len(shutTheBox.players))}
+	defer func() { shutTheBox.printMatchStats() }()
+
 	for {
 
 		shutTheBox.printGameState()
 
 		if shutTheBox.checkWinCondition() {
-			// Winner! Prompt to keep playing
-			if !continuePlaying() {
+			shutTheBox.stats.shuts[shutTheBox.player_i]++
+
+			// Winner! A match keeps playing until its last round,
+			// otherwise prompt how to keep playing
+			choice := afterWinNext
+			if shutTheBox.rounds == 0 {
+				var done bool
+				done, choice = chooseAfterWin(stdin, shutTheBox.setup != nil)
+				if done {
+					// Terminal State
+					return
+				}
+			}
+
+			switch choice {
+			case afterWinStop:
 				// Terminal State
 				return
+			case afterWinRestart:
+				// Same players, start again with player 1
+				shutTheBox.restart(shutTheBox.players, shutTheBox.ai)
+			case afterWinChange:
+				done, players, ai := shutTheBox.setup(stdin)
+				if done {
+					// Exit the driver and return to the menu
+					return
+				}
+				shutTheBox.restart(players, ai)
+			default:
+				// Keep playing, start with the next player
+				if shutTheBox.finishTurn() {
+					// Match over
+					return
+				}
 			}
-
-			// Keep playing, start with the next player
-			shutTheBox.nextTurn()
 			continue
 		}
 
-		// Roll for the player
-		roll1 := GetSlotValue(probgen.ExecuteAndDisplayOneRollAction(probgen.D6))
-		roll2 := GetSlotValue(probgen.ExecuteAndDisplayOneRollAction(probgen.D6))
-		// Compute the target
-		target := roll1 + roll2
+		game_done, numDice := shutTheBox.numDiceForRoll(stdin)
+		if game_done {
+			// Exit the driver and return to the menu
+			return
+		}
+
+		// Updates for an earlier target can not be taken back, otherwise
+		// closed slots could be reopened and spent again on this roll
+		shutTheBox.undoStack = nil
+
+		// Roll for the player and compute the target
+		dice := shutTheBox.rollDice(numDice)
+		target := sumDice(dice)
+		shutTheBox.stats.rolls++
+		if shutTheBox.verbose {
+			fmt.Printf("\nRolled %s (target %d)\n", joinValues(dice), target)
+		}
 
 		if !shutTheBox.checkSolutionExists(target) {
-			// Lost, next players turn
-			shutTheBox.nextTurn()
+			// Lost, score the open slots and next players turn
+			shutTheBox.stats.busts++
+			shutTheBox.stats.remaining += SumOpenSlots(shutTheBox.gameState)
+			if shutTheBox.finishTurn() {
+				// Match over
+				return
+			}
+			continue
+		}
+
+		// The move is timed once there is one to make
+		rolledAt := time.Time{}
+		if shutTheBox.clock != nil {
+			rolledAt = shutTheBox.clock()
+		}
+
+		// AI Action, a move always exists at this point
+		if shutTheBox.isAI() {
+			move, _ := autoMove(shutTheBox.gameState, target)
+			fmt.Printf("\nTarget sum is '%d' . %s closes '%s'\n", target, shutTheBox.players[shutTheBox.player_i], move)
+			if err := shutTheBox.updateGameState(move, target); err != nil {
+				// autoMove only picks legal moves, but never roll again for
+				// the same player on a failed update. End the turn instead
+				fmt.Print(err.Error() + "\n")
+				if shutTheBox.finishTurn() {
+					// Match over
+					return
+				}
+				continue
+			}
+			shutTheBox.recordMoveTime(rolledAt)
 			continue
 		}
 
 		// Player Action
-		for {
-			fmt.Printf("\nTarget sum is '%d' . Please enter open slots together:\n", target)
-			game_done, input_slots := utilities.ProcessInputStr(os.Stdin)
+		for attempts := 0; ; {
+			fmt.Printf("\nTarget sum is '%d' . Please enter open slots together or separated by commas or spaces (or '%s', '%s'):\n", target, HintCmd, StatsCmd)
+			game_done, input_slots := utilities.ProcessInputStr(stdin)
 
 			// User is done and wants to quit
 			if game_done {
@@ -111,22 +708,67 @@ func (shutTheBox ShutTheBox) Run() {
 				return
 			}
 
+			// User wants to see every possible solution
+			if input_slots == HintCmd {
+				shutTheBox.printHints(target)
+				continue
+			}
+
+			// User wants to see how likely each target is
+			if input_slots == StatsCmd {
+				printSumProbabilities(numDice)
+				continue
+			}
+
 			// Try to update the game state, or do nothing and try next iter
 			err := shutTheBox.updateGameState(input_slots, target)
 			if err != nil {
-				// Error feedback, retry
+				// Error feedback, retry until too many attempts
 				fmt.Print(err.Error())
+				attempts++
+				if err := utilities.CheckAttempts(attempts); err != nil {
+					// Exit the driver and return to the menu
+					fmt.Printf("\n%s\n", err.Error())
+					return
+				}
+
 				shutTheBox.printGameState()
 			} else {
-				// Update succeeded. Return to outer loop
-				break
+				if shutTheBox.verbose {
+					fmt.Printf(
+						"\n%s closes %s\n",
+						shutTheBox.players[shutTheBox.player_i],
+						joinValues(closedSlots(shutTheBox.undoStack[len(shutTheBox.undoStack)-1], shutTheBox.gameState)))
+				}
+
+				// The move can still be taken back until the next roll
+				fmt.Printf("\nEnter '%s' to take back the move, or anything else to keep it and roll again:\n", UndoCmd)
+				game_done, confirm := utilities.ProcessInputStr(stdin)
+				if game_done {
+					// Exit the driver and return to the menu
+					return
+				}
+
+				if confirm != UndoCmd {
+					// Update kept. Return to outer loop
+					shutTheBox.recordMoveTime(rolledAt)
+					break
+				}
+
+				// Only the move for this roll is on the stack
+				if err := shutTheBox.undo(); err != nil {
+					fmt.Print(err.Error())
+				}
+
+				shutTheBox.printGameState()
 			}
 		}
 	}
 }
 
 // Update the current game state with the provided arguments, unless an error
-// is encountered in which case no change persists
+// is encountered in which case no change persists. The slots closed are
+// remembered for highlighting, see SetHighlight
 //
 //	Params
 //		update string : proposed update. Ex: "137"
@@ -134,8 +776,11 @@ func (shutTheBox ShutTheBox) Run() {
 //	Returns
 //		error : any errors encountered
 func (shutTheBox *ShutTheBox) updateGameState(update string, target int) error {
-	proposedUpdate, err := processProposedUpdate(shutTheBox.gameState, update, target)
+	proposedUpdate, err := processProposedUpdate(
+		shutTheBox.gameState, shutTheBox.boxSize, update, target)
 	if err == nil {
+		shutTheBox.undoStack = append(shutTheBox.undoStack, shutTheBox.gameState)
+		shutTheBox.lastClosed = shutTheBox.gameState &^ proposedUpdate
 		shutTheBox.gameState = proposedUpdate
 	}
 
@@ -149,11 +794,105 @@ func (shutTheBox *ShutTheBox) updateGameState(update string, target int) error {
 // Player: p1
 //
 // [_][2][3][_][5][6][_][8][9]
+//
+// When highlighted, the slots closed by the last move are colored
 func (shutTheBox ShutTheBox) printGameState() {
+	display := shutTheBox.style.AssembleSlotsToDisplay(shutTheBox.gameState, shutTheBox.boxSize)
+	if shutTheBox.states != nil {
+		*shutTheBox.states = append(*shutTheBox.states, display)
+	}
+
+	// Replays record the plain display above
+	if shutTheBox.highlight {
+		display = shutTheBox.style.HighlightSlots(shutTheBox.gameState, shutTheBox.boxSize, shutTheBox.lastClosed)
+	}
+
 	fmt.Printf(
 		"\n\nPlayer: %s\n\n%s\n",
 		shutTheBox.players[shutTheBox.player_i],
-		AssembleSlotsToDisplay(shutTheBox.gameState))
+		display)
+}
+
+// Concise description of the game for logging: the current player and the
+// board in the game's slot style
+//
+//	Ex: "p2: [1][2][_][4][5][6][7][8][9]"
+//
+//	Returns
+//		string : the current player and board, only the board without players
+func (shutTheBox ShutTheBox) String() string {
+	board := shutTheBox.style.AssembleSlotsToDisplay(shutTheBox.gameState, shutTheBox.boxSize)
+	if shutTheBox.player_i >= len(shutTheBox.players) {
+		return board
+	}
+
+	return fmt.Sprintf("%s: %s", shutTheBox.players[shutTheBox.player_i], board)
+}
+
+// Print the players ranked by their accumulated scores, lowest first
+//
+// Ex: p2 and p3 shut the box, p1 left 12 open:
+//
+// Scoreboard:
+//
+// 1) p2 : 0
+// 1) p3 : 0
+// 3) p1 : 12
+func (shutTheBox ShutTheBox) printScoreboard() {
+	fmt.Print("\nScoreboard:\n\n")
+	for _, rank := range RankScores(shutTheBox.players, shutTheBox.scores) {
+		fmt.Printf("%d) %s : %d\n", rank.Place, rank.Player, rank.Score)
+	}
+}
+
+// Print the final standings of a match and its winners, sharing the win
+// on a tie
+//
+// Ex:
+//
+// # Match over after 3 rounds
+//
+// 1) p2 : 10
+// 1) p3 : 10
+// 3) p1 : 25
+//
+// Tied winners: p2, p3
+func (shutTheBox ShutTheBox) printStandings() {
+	fmt.Printf("\nMatch over after %d rounds\n\n", len(shutTheBox.roundScores))
+
+	ranks := MatchStandings(shutTheBox.players, shutTheBox.roundScores)
+	winners := []string{}
+	for _, rank := range ranks {
+		fmt.Printf("%d) %s : %d\n", rank.Place, rank.Player, rank.Score)
+		if rank.Place == 1 {
+			winners = append(winners, rank.Player)
+		}
+	}
+
+	if len(winners) == 1 {
+		fmt.Printf("\nWinner: %s\n", winners[0])
+	} else {
+		fmt.Printf("\nTied winners: %s\n", strings.Join(winners, ", "))
+	}
+}
+
+// Print every solution for the target in the current game state as the
+// input the player would enter
+//
+// Ex: open box and target 5:
+//
+// Possible solutions: 5, 14, 23
+//
+//	Params
+//		target int : the target sum of open slots in the game state
+func (shutTheBox ShutTheBox) printHints(target int) {
+	solutions := FindAllSolutions(shutTheBox.gameState, target)
+	inputs := make([]string, len(solutions))
+	for i, solution := range solutions {
+		inputs[i] = SolutionToInput(solution)
+	}
+
+	fmt.Printf("\nPossible solutions: %s\n", strings.Join(inputs, ", "))
 }
 
 // Check the win condition: box is shut
@@ -192,54 +931,134 @@ func (shutTheBox ShutTheBox) checkSolutionExists(target int) bool {
 	return true
 }
 
-// Verify the proposed update, apply slot by slot to the game state
-// and then compare the final result with the target to ensure the
-// target value is satisfied. Returned error indicates whether the
-// updated game state should be used or ignored
+// Verify the proposed update, then apply it slot by slot to the game state.
+// Returned error indicates whether the updated game state should be used or
+// ignored, see IsLegalMove
+//
+// Slots above 9 need more than one digit, so they are entered separated by
+// commas or spaces. Ex: "1,10" or "1 10"
 //
 //	Params
 //		gstate int    : game state to update
+//		size int      : total number of slots in the game state
 //		update string : proposed update. Ex: "137"
 //		target int    : target sum of update digits. Ex: 11
 //	Returns
 //		int   : updated game state, or -1 when errors are encountered
 //		error : any error encountered
-func processProposedUpdate(gstate int, update string, target int) (int, error) {
-	combinedDigits := 0
+func processProposedUpdate(gstate int, size int, update string, target int) (int, error) {
+	digits, err := validateProposedUpdate(gstate, size, update, target)
+	if err != nil {
+		return -1, err
+	}
 
-	// Empty input string is invalid
-	if update == "" {
-		return -1, errors.New(ErrInvDigit)
+	for _, digit_i := range digits {
+		SetBitEmpty(&gstate, GetValueSlot(digit_i))
 	}
 
-	for _, d := range update {
-		digit_i, err := strconv.Atoi(string(d))
+	return gstate, nil
+}
+
+// Check whether the proposed update is a legal move for the game state,
+// without applying it. Useful to give feedback on a typed move before it
+// is committed
+//
+//	Ex: open box, "137" for target 11 -> true, nil
+//	Ex: open box, "145" for target 6  -> false, ErrNotEqTarget
+//
+//	Params
+//		gstate int    : game state the move would update, left unchanged
+//		size int      : total number of slots in the game state
+//		update string : proposed update. Ex: "137"
+//		target int    : target sum of update digits. Ex: 11
+//	Returns
+//		bool  : true if the move is legal
+//		error : why the move is not legal, otherwise nil
+func IsLegalMove(gstate int, size int, update string, target int) (bool, error) {
+	_, err := validateProposedUpdate(gstate, size, update, target)
+
+	return err == nil, err
+}
+
+// Parse the digits of the proposed update and verify them against the game
+// state and the target: every digit is a slot of the box, entered once,
+// still open, and together they sum to the target
+//
+//	Params
+//		gstate int    : game state to check against
+//		size int      : total number of slots in the game state
+//		update string : proposed update. Ex: "137"
+//		target int    : target sum of update digits. Ex: 11
+//	Returns
+//		[]int : slot values of the update in the order entered
+//		error : the first check that failed, otherwise nil
+func validateProposedUpdate(gstate int, size int, update string, target int) ([]int, error) {
+	combinedDigits := 0
+
+	// Empty input string is invalid
+	if update == "" {
+		return nil, fmt.Errorf(ErrInvDigit, size)
+	}
+
+	digits := []int{}
+	for _, d := range splitSlots(update) {
+		digit_i, err := strconv.Atoi(d)
 
 		// Any error in the conversion or an invalid digit will
 		// cause immediate termination of execution
-		if err != nil || digit_i < 1 {
-			return -1, errors.New(ErrInvDigit)
+		if err != nil || digit_i < 1 || digit_i > size {
+			return nil, fmt.Errorf(ErrInvDigit, size)
+		}
+
+		digits = append(digits, digit_i)
+	}
+
+	// Each slot can only be closed once per update
+	// ex: 22 = 4
+	for i, digit_i := range digits {
+		if slices.Contains(digits[:i], digit_i) {
+			return nil, fmt.Errorf(ErrDuplicateDigit, digit_i)
 		}
+	}
 
-		// This will handle duplicated inputs and already closed slots
-		// ex: 22 = 4 or [_][2]... -> 12 = 3
-		digit_slot := GetValueSlot(digit_i)
-		if !IsBitSet(gstate, digit_slot) {
-			return -1, fmt.Errorf(
+	for _, digit_i := range digits {
+		// This will handle already closed slots
+		// ex: [_][2]... -> 12 = 3
+		if !IsBitSet(gstate, GetValueSlot(digit_i)) {
+			return nil, fmt.Errorf(
 				"slot %d is already closed. Please try again",
 				digit_i)
 		}
 
 		combinedDigits += digit_i
-		SetBitEmpty(&gstate, digit_slot)
 	}
 
 	// Verify whether the inputs actually add up to the target
 	if combinedDigits != target {
-		return -1, fmt.Errorf(ErrNotEqTarget, combinedDigits, target)
+		return nil, fmt.Errorf(ErrNotEqTarget, combinedDigits, target)
 	}
 
-	return gstate, nil
+	return digits, nil
+}
+
+// Split the proposed update into the individual slot values. Without
+// separators every character is its own slot
+//
+//	Ex: "137" -> {"1", "3", "7"}
+//	Ex: "1,10" or "1 10" -> {"1", "10"}
+//
+//	Params
+//		update string : proposed update
+//	Returns
+//		[]string : each slot value
+func splitSlots(update string) []string {
+	if !strings.ContainsAny(update, ", ") {
+		return strings.Split(update, "")
+	}
+
+	return strings.FieldsFunc(update, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
 }
 
 // Check whether the bit in the bitset is on
@@ -262,24 +1081,87 @@ func SetBitEmpty(bitset *int, bit int) {
 	*bitset = *bitset &^ (1 << bit)
 }
 
-// Retrieve the visualized slot for printing
+// Check whether the box size is supported
 //
-// Ex: Open slot   -> [0][1][2] ... [9]
-// Ex: Closed slot -> [_]
+//	Params
+//		size int : total number of slots
+//	Returns
+//		bool : true if size in [SizeBox, MaxSizeBox]
+func ValidBoxSize(size int) bool {
+	return size >= SizeBox && size <= MaxSizeBox
+}
+
+// Check whether the number of dice summed for the target is supported
+//
+//	Params
+//		numDice int : number of D6 in a full roll
+//	Returns
+//		bool : true if numDice is in range [NumDice, MaxNumDice]
+func ValidNumDice(numDice int) bool {
+	return numDice >= NumDice && numDice <= MaxNumDice
+}
+
+// Initial open box with the given total number of slots
+//
+//	Params
+//		size int : total number of slots
+//	Returns
+//		int : game state with every slot open
+func OpenBoxOf(size int) int {
+	return (1 << size) - 1
+}
+
+// Width of every slot value in the display so that single and double digit
+// slots line up
+//
+//	Ex: 9 -> 1, 12 -> 2
+//
+//	Params
+//		size int : total number of slots
+//	Returns
+//		int : number of characters of the largest slot value
+func slotWidth(size int) int {
+	return len(strconv.Itoa(size))
+}
+
+// Retrieve the visualized slot for printing in the default style, see
+// SlotStyle.GetSlotForPrint
+//
+// Ex: Open slot   -> [1][2] ... [9] or [ 1][ 2] ... [12]
+// Ex: Closed slot -> [_] or [ _]
 //
 //	Params
 //		gstate int : game state bitset
 //		slot int   : the slot we want to visualize
+//		size int   : total number of slots
 //	Returns
 //		string : the visualized slot
-func GetSlotForPrint(gstate int, slot int) string {
-	slot_v := EmptySlot
+func GetSlotForPrint(gstate int, slot int, size int) string {
+	return DefaultSlotStyle.GetSlotForPrint(gstate, slot, size)
+}
+
+// Retrieve the visualized slot for printing, right aligned to the width of
+// the largest slot value or the empty marker, whichever is wider
+//
+// Ex: {"(", ")", " "}, open slot -> (1)(2) ... (9)
+// Ex: {"(", ")", " "}, closed    -> ( ) or (  )
+//
+//	Params
+//		gstate int : game state bitset
+//		slot int   : the slot we want to visualize
+//		size int   : total number of slots
+//	Returns
+//		string : the visualized slot
+func (style SlotStyle) GetSlotForPrint(gstate int, slot int, size int) string {
+	slot_v := style.Empty
 
 	if IsBitSet(gstate, slot) {
 		slot_v = strconv.Itoa(GetSlotValue(slot))
 	}
 
-	return fmt.Sprintf(Slot, slot_v)
+	width := max(slotWidth(size), utf8.RuneCountInString(style.Empty))
+
+	return style.Open + fmt.Sprintf("%*s", width, slot_v) + style.Close
 }
 
 // Get the value for the given slot index in the game state
@@ -302,17 +1184,60 @@ func GetValueSlot(value int) int {
 	return value - 1
 }
 
+// Create formatted display for the provided game state in the default
+// style, see SlotStyle.AssembleSlotsToDisplay
+//
+//	 Ex: gstate(32), size 9 -> "[_][_][_][_][_][6][_][_][_]"
+//		Params
+//			gstate int : game state to display
+//			size int   : total number of slots
+//		Returns
+//			string : display string
+func AssembleSlotsToDisplay(gstate int, size int) string {
+	return DefaultSlotStyle.AssembleSlotsToDisplay(gstate, size)
+}
+
 // Create formatted display for the provided game state
 //
-//	 Ex: gstate(32) -> "[_][_][_][_][_][6][_][_][_]"
+//	 Ex: {"(", ")", " "}, gstate(32), size 9 -> "( )( )( )( )( )(6)( )( )( )"
 //		Params
 //			gstate int : game state to display
+//			size int   : total number of slots
 //		Returns
 //			string : display string
-func AssembleSlotsToDisplay(gstate int) string {
+func (style SlotStyle) AssembleSlotsToDisplay(gstate int, size int) string {
 	gstateslots := ""
-	for i := 0; i < SizeBox; i++ {
-		gstateslots += GetSlotForPrint(gstate, i)
+	for i := 0; i < size; i++ {
+		gstateslots += style.GetSlotForPrint(gstate, i, size)
+	}
+
+	return gstateslots
+}
+
+// Create formatted display for the provided game state, coloring the given
+// slots with ClosedColor. Same as AssembleSlotsToDisplay when color is
+// disabled, see probgen.UseColor
+//
+//	Ex: closed(3), gstate(508), size 9 -> "\033[33m[_]\033[0m\033[33m[_]\033[0m[3]..."
+//
+//	Params
+//		gstate int : game state to display
+//		size int   : total number of slots
+//		closed int : bits of the slots to color
+//	Returns
+//		string : display string
+func (style SlotStyle) HighlightSlots(gstate int, size int, closed int) string {
+	if !probgen.UseColor {
+		return style.AssembleSlotsToDisplay(gstate, size)
+	}
+
+	gstateslots := ""
+	for i := 0; i < size; i++ {
+		slot := style.GetSlotForPrint(gstate, i, size)
+		if IsBitSet(closed, i) {
+			slot = ClosedColor + slot + probgen.ColorReset
+		}
+		gstateslots += slot
 	}
 
 	return gstateslots
@@ -324,13 +1249,28 @@ func AssembleSlotsToDisplay(gstate int) string {
 //
 //	Params
 //		gslots string : formatted slot display for conversion
+//		size int      : total number of slots
 //	Returns
 //		int : game state representation
-func ConvertSlotsToGameState(gslots string) int {
+func ConvertSlotsToGameState(gslots string, size int) int {
+	return DefaultSlotStyle.ConvertSlotsToGameState(gslots, size)
+}
+
+// Helper function to convert displayed game state in this style to internal
+// game state
+//
+// Example: {"(", ")", " "}, "( )( )( )( )( )(6)( )( )( )" -> 32
+//
+//	Params
+//		gslots string : formatted slot display for conversion
+//		size int      : total number of slots
+//	Returns
+//		int : game state representation
+func (style SlotStyle) ConvertSlotsToGameState(gslots string, size int) int {
 	gstate := 0
-	for i := 0; i < SizeBox; i++ {
+	for i := 0; i < size; i++ {
 		// Turn each bit on for each open slot
-		gstate |= (ConvertSlotToBit(gslots, i) << i)
+		gstate |= (style.ConvertSlotToBit(gslots, i, size) << i)
 	}
 
 	return gstate
@@ -340,20 +1280,43 @@ func ConvertSlotsToGameState(gslots string) int {
 //
 // Ex: [_] -> 0
 // Ex: [4] -> 1
-// Ex: [7] -> 1
+// Ex: [ 7] -> 1
+//
+//	Params
+//		gslots string : game state as visual string (size slots)
+//		slot int      : the slot we want to convert to a bit (off or on)
+//		size int      : total number of slots
+//	Returns
+//		int : 0 if [_] and 1 if [1->size]
+func ConvertSlotToBit(gslots string, slot int, size int) int {
+	return DefaultSlotStyle.ConvertSlotToBit(gslots, slot, size)
+}
+
+// Helper function to convert a slot displayed in this style to a bit
+//
+// Ex: {"(", ")", " "}, ( ) -> 0
+// Ex: {"(", ")", " "}, (4) -> 1
 //
 //	Params
-//		gslots string : game state as visual string (9 slots)
+//		gslots string : game state as visual string (size slots)
 //		slot int      : the slot we want to convert to a bit (off or on)
+//		size int      : total number of slots
 //	Returns
-//		int : 0 if [_] and 1 if [1->9]
-func ConvertSlotToBit(gslots string, slot int) int {
-	// [X][.]...
-	// \_\
-	//    \
-	//     slot index 0 in the display format covers gslots[0:3]
-	// We read each slot 3 characters at a time
-	if gslots[slot*3:(slot*3)+3] == fmt.Sprintf(Slot, EmptySlot) {
+//		int : 0 if closed and 1 if open
+func (style SlotStyle) ConvertSlotToBit(gslots string, slot int, size int) int {
+	// Open and closed slots may differ in length, ex: a multi-byte empty
+	// marker, so skip over the slots before this one as they were drawn
+	rest := gslots
+	for i := 0; i < slot; i++ {
+		closed := style.GetSlotForPrint(ShutBox, i, size)
+		if strings.HasPrefix(rest, closed) {
+			rest = rest[len(closed):]
+		} else {
+			rest = rest[len(style.GetSlotForPrint(OpenBoxOf(size), i, size)):]
+		}
+	}
+
+	if strings.HasPrefix(rest, style.GetSlotForPrint(ShutBox, slot, size)) {
 		return 0
 	} else {
 		return 1
@@ -370,8 +1333,13 @@ func ConvertSlotToBit(gslots string, slot int) int {
 //
 // Game Rules : A slot can only be used once when trying to reach the target
 //
+// Backtracking : a failed split restores every slot its sub targets consumed
+// before the next split is tried
+//
 //	Params
-//		bitset *int : persistent game state used to reach target
+//		bitset *int : persistent game state used to reach target. On success
+//		              the slots of the solution are closed, otherwise it is
+//		              left unchanged
 //		target int  : the target sum of open slots in the game state
 //	Returns
 //		bool : true if a solution exists, false otherwise
@@ -399,8 +1367,9 @@ func TargetSumExists(bitset *int, target int) bool {
 		if TargetSumExists(bitset, low_v) && TargetSumExists(bitset, high_v) {
 			return true
 		} else {
-			// Reset for the next iteration
-			bitset = &orig_bitset
+			// Reset the caller's game state for the next iteration, the low
+			// sub target may have consumed slots even though high failed
+			*bitset = orig_bitset
 			// initialize the next permutation
 			low_v++
 			high_v--
@@ -411,6 +1380,516 @@ func TargetSumExists(bitset *int, target int) bool {
 	return false
 }
 
+// Find every distinct combination of open slots in the game state that sums
+// to the target. Each slot is used at most once per combination
+//
+// Combinations are sorted slot values, ordered by the number of slots and
+// then lexicographically
+//
+//	Ex: open box and target 5 -> {5}, {1, 4}, {2, 3}
+//
+//	Params
+//		gstate int : game state bitset
+//		target int : the target sum of open slots in the game state
+//	Returns
+//		[][]int : every combination of slot values summing to target
+func FindAllSolutions(gstate int, target int) [][]int {
+	solutions := [][]int{}
+	if target < 1 {
+		return solutions
+	}
+
+	findSolutions(gstate, target, 1, []int{}, &solutions)
+
+	// Depth first search is already lexicographic, so a stable sort keeps
+	// that order among combinations with the same number of slots
+	sort.SliceStable(solutions, func(i, j int) bool {
+		return len(solutions[i]) < len(solutions[j])
+	})
+
+	return solutions
+}
+
+// Depth first search over the open slot values in ascending order, adding a
+// combination to the solutions whenever the remaining target reaches zero
+//
+//	Params
+//		gstate int           : game state bitset
+//		remaining int        : target left to satisfy
+//		value int            : smallest slot value still available to use
+//		partial []int        : slot values used so far
+//		solutions *[][]int   : all combinations found
+func findSolutions(gstate int, remaining int, value int, partial []int, solutions *[][]int) {
+	if remaining == 0 {
+		*solutions = append(*solutions, slices.Clone(partial))
+		return
+	}
+
+	for v := value; v <= bits.Len(uint(gstate)) && v <= remaining; v++ {
+		if IsBitSet(gstate, GetValueSlot(v)) {
+			findSolutions(gstate, remaining-v, v+1, append(partial, v), solutions)
+		}
+	}
+}
+
+// Exact probability of each sum of two D6, over all 36 equally likely
+// outcomes of the two dice
+//
+//	Ex: 2 -> 1/36, 7 -> 6/36, 12 -> 1/36
+//
+//	Returns
+//		map[int]float64 : probability of each sum in [2, 12]
+func TwoDiceSumProbabilities() map[int]float64 {
+	return DiceSumProbabilities(NumDice)
+}
+
+// Exact probability of each sum of the given number of D6, adding one die
+// at a time to the sums of the dice before it
+//
+//	Ex: 1 -> 1/6 each, 3 -> 3 is 1/216 and 10 is 27/216
+//
+//	Params
+//		numDice int : number of D6 summed, at least 1
+//	Returns
+//		map[int]float64 : probability of each sum in [numDice, 6*numDice]
+func DiceSumProbabilities(numDice int) map[int]float64 {
+	probabilities := map[int]float64{0: 1}
+	for range numDice {
+		next := make(map[int]float64)
+		for sum, probability := range probabilities {
+			for face := 1; face <= probgen.D6; face++ {
+				next[sum+face] += probability / probgen.D6
+			}
+		}
+		probabilities = next
+	}
+
+	return probabilities
+}
+
+// Print the chance of rolling each target with the dice of the roll.
+// Example:
+//
+// Chance of each 2d6 target:
+//
+// 2  :   2.777778%
+//
+// ...
+//
+// 7  :  16.666667%
+//
+//	Params
+//		numDice int : number of D6 rolled. Ex: 1 in one-die mode
+func printSumProbabilities(numDice int) {
+	probabilities := DiceSumProbabilities(numDice)
+
+	fmt.Printf("\nChance of each %dd6 target:\n", numDice)
+	for sum := numDice; sum <= numDice*probgen.D6; sum++ {
+		fmt.Printf("%-2d : %10.*f%%\n", sum, probgen.Precision, probabilities[sum]*100)
+	}
+}
+
+// Probability that the next 2d6 roll has no legal move in the game state,
+// over all 36 equally likely outcomes of the two dice
+//
+//	Ex: only slot 9 open -> 32/36, every target but 9 is unsolvable
+//
+//	Params
+//		gstate int : game state bitset, left unchanged
+//	Returns
+//		float64 : fraction of outcomes without a solution, in [0, 1]
+func ProbNoSolution(gstate int) float64 {
+	unsolvable := 0.0
+	for target, probability := range TwoDiceSumProbabilities() {
+		// TargetSumExists consumes slots, so check against a copy
+		bitset := gstate
+		if !TargetSumExists(&bitset, target) {
+			unsolvable += probability
+		}
+	}
+
+	return unsolvable
+}
+
+// Lowest score reachable from the game state by playing optimally against a
+// known sequence of rolls. Play ends once the box is shut, the rolls run out
+// or no move is legal for a roll, scoring the sum of the open slots
+//
+//	Ex: "[1][2][3][_][_][_][_][_][_]" and rolls {{1, 2}, {3}} -> 0 by
+//	closing 3 then 1 and 2, where closing 1 and 2 first leaves 3 open
+//
+//	Params
+//		gstate int    : game state bitset
+//		rolls [][]int : dice values of each roll in order. Ex: {{3, 4}, {6}}
+//	Returns
+//		int : lowest reachable sum of open slots
+func BestScore(gstate int, rolls [][]int) int {
+	return bestScore(gstate, rolls, 0, make(map[[2]int]int))
+}
+
+// Memoized search of BestScore. The score only depends on the game state
+// and the rolls left, so each pair is solved once
+//
+//	Params
+//		gstate int           : game state bitset
+//		rolls [][]int        : dice values of each roll in order
+//		roll_i int           : index of the next roll
+//		memo map[[2]int]int  : best score by game state and roll index
+//	Returns
+//		int : lowest reachable sum of open slots
+func bestScore(gstate int, rolls [][]int, roll_i int, memo map[[2]int]int) int {
+	if IsBoxEmpty(gstate) || roll_i == len(rolls) {
+		return SumOpenSlots(gstate)
+	}
+
+	key := [2]int{gstate, roll_i}
+	if score, ok := memo[key]; ok {
+		return score
+	}
+
+	target := 0
+	for _, die := range rolls[roll_i] {
+		target += die
+	}
+
+	// Without a legal move the turn is over
+	best := SumOpenSlots(gstate)
+	for _, solution := range FindAllSolutions(gstate, target) {
+		best = min(best, bestScore(closeSlots(gstate, solution), rolls, roll_i+1, memo))
+	}
+
+	memo[key] = best
+
+	return best
+}
+
+// Shut the slots of the given values in the game state
+//
+//	Params
+//		gstate int   : game state bitset
+//		values []int : slot values to shut. Ex: {1, 4}
+//	Returns
+//		int : updated game state bitset
+func closeSlots(gstate int, values []int) int {
+	for _, v := range values {
+		SetBitEmpty(&gstate, GetValueSlot(v))
+	}
+
+	return gstate
+}
+
+// Pick a legal move for the AI which closes the highest value slots, keeping
+// the low slots open for flexibility on later rolls
+//
+//	Params
+//		gstate int : game state bitset
+//		target int : the target sum of open slots in the game state
+//	Returns
+//		string : the move as the input expected by updateGameState
+//		bool   : false if no legal move exists
+func autoMove(gstate int, target int) (string, bool) {
+	return autoMoveWith(gstate, target, StrategyHighest)
+}
+
+// Pick a legal move for the AI with the given strategy. Ties in the number
+// of slots closed are broken by the highest value slots
+//
+//	Ex: open box, target 10, StrategyHighest -> "19"
+//	Ex: open box, target 10, StrategyMost    -> "1234"
+//
+//	Params
+//		gstate int        : game state bitset
+//		target int        : the target sum of open slots in the game state
+//		strategy Strategy : how to pick among the legal moves
+//	Returns
+//		string : the move as the input expected by updateGameState
+//		bool   : false if no legal move exists
+func autoMoveWith(gstate int, target int, strategy Strategy) (string, bool) {
+	solutions := FindAllSolutions(gstate, target)
+	if len(solutions) == 0 {
+		return "", false
+	}
+
+	best := solutions[0]
+	for _, solution := range solutions[1:] {
+		if strategy == StrategyMost && len(solution) != len(best) {
+			if len(solution) > len(best) {
+				best = solution
+			}
+			continue
+		}
+
+		if closesHigherSlots(solution, best) {
+			best = solution
+		}
+	}
+
+	return SolutionToInput(best), true
+}
+
+// Compare two combinations of ascending slot values from their highest
+// value downwards
+//
+//	Ex: {1, 9} vs {2, 8} -> true
+//
+//	Params
+//		a []int : ascending slot values
+//		b []int : ascending slot values
+//	Returns
+//		bool : true if a closes a higher slot than b first
+func closesHigherSlots(a []int, b []int) bool {
+	for i, j := len(a)-1, len(b)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if a[i] != b[j] {
+			return a[i] > b[j]
+		}
+	}
+
+	return false
+}
+
+// Convert a combination of slot values to the input expected by
+// updateGameState. Values are only separated by commas when one of them
+// needs more than one digit
+//
+//	Ex: {1, 4} -> "14"
+//	Ex: {1, 10} -> "1,10"
+//
+//	Params
+//		solution []int : slot values
+//	Returns
+//		string : slot values joined together
+func SolutionToInput(solution []int) string {
+	values, sep := make([]string, len(solution)), ""
+	for i, v := range solution {
+		values[i] = strconv.Itoa(v)
+		if v > 9 {
+			sep = ","
+		}
+	}
+
+	return strings.Join(values, sep)
+}
+
+// Number of open slots in the game state
+//
+//	Ex: "[_][2][3][_][5][6][_][8][9]" -> 6
+//
+//	Params
+//		gstate int : game state bitset
+//	Returns
+//		int : number of open slots, 0 for a shut box
+func CountOpenSlots(gstate int) int {
+	return bits.OnesCount(uint(gstate))
+}
+
+// Sum of the values of all open slots in the game state, which is the score
+// of a turn that ends with it
+//
+//	Ex: "[_][2][3][_][5][6][_][8][9]" -> 33
+//
+//	Params
+//		gstate int : game state bitset
+//	Returns
+//		int : sum of open slot values, 0 for a shut box
+func SumOpenSlots(gstate int) int {
+	sum := 0
+	for i := 0; i < bits.Len(uint(gstate)); i++ {
+		if IsBitSet(gstate, i) {
+			sum += GetSlotValue(i)
+		}
+	}
+
+	return sum
+}
+
+// Rank the players by score, lowest first. Players with the same score share
+// the same place and the following place is skipped
+//
+//	Ex: scores {12, 0, 0} -> 1) p2 : 0, 1) p3 : 0, 3) p1 : 12
+//
+//	Params
+//		players []string : names of the players
+//		scores []int     : score of each player
+//	Returns
+//		[]Rank : placements in ranked order, ties kept in player order
+func RankScores(players []string, scores []int) []Rank {
+	ranks := make([]Rank, len(players))
+	for i, player := range players {
+		ranks[i] = Rank{Player: player, Score: scores[i]}
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].Score < ranks[j].Score
+	})
+
+	for i := range ranks {
+		if i > 0 && ranks[i].Score == ranks[i-1].Score {
+			ranks[i].Place = ranks[i-1].Place
+		} else {
+			ranks[i].Place = i + 1
+		}
+	}
+
+	return ranks
+}
+
+// Check whether every slot from HighSlot upwards is shut, allowing the
+// player to roll a single die
+//
+//	Params
+//		gstate int : game state bitset
+//	Returns
+//		bool : true if no slot of value HighSlot or more is open
+func HighSlotsShut(gstate int) bool {
+	return gstate>>GetValueSlot(HighSlot) == 0
+}
+
+// Number of dice for the current player's next roll according to the dice
+// mode. In the hybrid mode, once the high slots are shut a player may roll
+// a single die
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool : true if user indicates they are done
+//		int  : number of dice to roll, 1 or the game's number of dice
+func (shutTheBox ShutTheBox) numDiceForRoll(stdin io.Reader) (bool, int) {
+	switch {
+	case shutTheBox.diceMode == DiceOne:
+		return false, 1
+	case shutTheBox.diceMode == DiceAll || !HighSlotsShut(shutTheBox.gameState):
+		return false, shutTheBox.numDice
+	case shutTheBox.isAI():
+		// Only low slots are left, which a single die favors
+		return false, 1
+	default:
+		return chooseNumDice(stdin, shutTheBox.numDice)
+	}
+}
+
+// Convert the dice mode input of the game setup
+//
+//	Params
+//		input string : 'a' all dice, '1' one die, 'h' hybrid
+//	Returns
+//		DiceMode : the selected dice mode
+//		error    : ErrInvalidDiceMode for any other input
+func ParseDiceMode(input string) (DiceMode, error) {
+	switch input {
+	case "a":
+		return DiceAll, nil
+	case "1":
+		return DiceOne, nil
+	case "h":
+		return DiceHybrid, nil
+	default:
+		return DiceHybrid, errors.New(ErrInvalidDiceMode)
+	}
+}
+
+// Roll the given number of D6 with the game's dice roller and sum their
+// values, without displaying the dice, see probgen.RollSum
+//
+//	Params
+//		numDice int : number of dice to roll
+//	Returns
+//		int : target sum of the dice
+func (shutTheBox ShutTheBox) rollTarget(numDice int) int {
+	return probgen.RollSum(numDice, probgen.D6, shutTheBox.prng)
+}
+
+// Roll the given number of D6 with the game's dice roller
+//
+//	Params
+//		numDice int : number of dice to roll
+//	Returns
+//		[]int : value of each die in the order rolled
+func (shutTheBox ShutTheBox) rollDice(numDice int) []int {
+	dice := make([]int, numDice)
+	for i := range dice {
+		dice[i] = GetSlotValue(probgen.ExecuteAndDisplayOneRollActionWith(probgen.D6, shutTheBox.prng))
+	}
+
+	return dice
+}
+
+// Sum the values of the dice rolled
+//
+//	Params
+//		dice []int : value of each die
+//	Returns
+//		int : target sum of the dice
+func sumDice(dice []int) int {
+	target := 0
+	for _, die := range dice {
+		target += die
+	}
+
+	return target
+}
+
+// Slot values shut between two game states of the same turn
+//
+//	Ex: "[1][2][3][4][5]..." -> "[1][2][_][4][_]..." : {3, 5}
+//
+//	Params
+//		before int : game state before the move
+//		after int  : game state after the move
+//	Returns
+//		[]int : ascending values of the slots closed by the move
+func closedSlots(before int, after int) []int {
+	closed := []int{}
+	for slot := 0; slot < bits.Len(uint(before)); slot++ {
+		if IsBitSet(before, slot) && !IsBitSet(after, slot) {
+			closed = append(closed, GetSlotValue(slot))
+		}
+	}
+
+	return closed
+}
+
+// Join values for display as a list in words
+//
+//	Ex: {4} -> "4", {3, 5} -> "3 and 5", {1, 2, 6} -> "1, 2 and 6"
+//
+//	Params
+//		values []int : values to join
+//	Returns
+//		string : the joined values
+func joinValues(values []int) string {
+	values_s := make([]string, len(values))
+	for i, value := range values {
+		values_s[i] = strconv.Itoa(value)
+	}
+
+	if len(values_s) <= 1 {
+		return strings.Join(values_s, "")
+	}
+
+	last := len(values_s) - 1
+	return strings.Join(values_s[:last], ", ") + " and " + values_s[last]
+}
+
+// Sum the scores of every round of a match and rank the players by total
+//
+//	Ex: rounds {{5, 0}, {3, 6}} -> 1) p2 : 6, 2) p1 : 8
+//
+//	Params
+//		players []string    : names of the players
+//		roundScores [][]int : score of each player in every round
+//	Returns
+//		[]Rank : placements in ranked order, see RankScores
+func MatchStandings(players []string, roundScores [][]int) []Rank {
+	totals := make([]int, len(players))
+	for _, round := range roundScores {
+		for i, score := range round {
+			totals[i] += score
+		}
+	}
+
+	return RankScores(players, totals)
+}
+
 // Check whether the box is empty, ie all slots closed
 //
 //	Params
@@ -421,36 +1900,91 @@ func IsBoxEmpty(gstate int) bool {
 	return gstate == ShutBox
 }
 
-// Prompt whether user wants to keep playing or not. Will handle
-// errors and invalid inputs and prompt for input again and exit
-// when user indicates they are done
+// Prompt whether user wants to roll one die or all of the game's dice. Will
+// handle invalid inputs and prompt for input again, up to
+// utilities.MaxAttempts times
 //
+//	Params
+//		stdin io.Reader : holds user input
+//		numDice int     : number of dice in a full roll
 //	Returns
-//		bool : true if user wants to continue
-func continuePlaying() bool {
-	var done bool
-	for !done {
-		fmt.Print("Would you like to keep playing? [y/n]\n")
-		done, input := utilities.ProcessInputStr(os.Stdin)
+//		bool : true if user indicates they are done
+//		int  : number of dice to roll, 1 or numDice
+func chooseNumDice(stdin io.Reader, numDice int) (bool, int) {
+	all := strconv.Itoa(numDice)
+	for attempts := 1; ; attempts++ {
+		fmt.Printf("\nAll high slots are shut. Roll one die or all %s dice? [1/%s]\n", all, all)
+		done, input := utilities.ProcessInputStr(stdin)
 
 		// Inform caller we are done
 		if done {
-			return false
+			return true, -1
 		}
 
-		// Inform caller to continue
-		if input == "y" {
-			return true
+		if input == "1" {
+			return false, 1
 		}
 
-		// Inform caller we are done
-		if input == "n" {
-			return false
+		if input == all {
+			return false, numDice
 		}
 
-		fmt.Printf("input error: expected 'y' or 'n'\n")
+		fmt.Printf("input error: expected '1' or '%s'\n", all)
+		if err := utilities.CheckAttempts(attempts); err != nil {
+			// Too many invalid inputs, treat as done
+			fmt.Print(err.Error() + "\n")
+			return true, -1
+		}
 	}
+}
 
-	// We should not ever reach this line
-	return false
+// Prompt how the user wants to keep playing after a win: with the next
+// player, from player 1 again, with new players, or not at all. Will handle
+// invalid inputs and prompt for input again, up to utilities.MaxAttempts
+// times
+//
+//	Params
+//		stdin io.Reader       : holds user input
+//		canChangePlayers bool : whether ChangeCmd is offered
+//	Returns
+//		bool     : true if user indicates they are done
+//		afterWin : how to keep playing
+func chooseAfterWin(stdin io.Reader, canChangePlayers bool) (bool, afterWin) {
+	// 'y' and 'n' are still accepted from the former yes or no prompt
+	choices := map[string]afterWin{
+		NextCmd:    afterWinNext,
+		RestartCmd: afterWinRestart,
+		StopCmd:    afterWinStop,
+		"y":        afterWinNext,
+		"yes":      afterWinNext,
+		"n":        afterWinStop,
+		"no":       afterWinStop,
+	}
+	cmds := []string{NextCmd, RestartCmd}
+	if canChangePlayers {
+		choices[ChangeCmd] = afterWinChange
+		cmds = append(cmds, ChangeCmd)
+	}
+	cmds = append(cmds, StopCmd)
+
+	for attempts := 1; ; attempts++ {
+		fmt.Printf("\nWould you like to keep playing? [%s]\n", strings.Join(cmds, "/"))
+		done, input := utilities.ProcessInputStr(stdin)
+
+		// Inform caller we are done
+		if done {
+			return true, afterWinStop
+		}
+
+		if choice, ok := choices[strings.ToLower(input)]; ok {
+			return false, choice
+		}
+
+		fmt.Printf("input error: expected one of '%s'\n", strings.Join(cmds, "', '"))
+		if err := utilities.CheckAttempts(attempts); err != nil {
+			// Too many invalid inputs, treat as done
+			fmt.Print(err.Error() + "\n")
+			return true, afterWinStop
+		}
+	}
 }
@@ -1,10 +1,18 @@
 package games
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/romansod/roll-dice/internal/probgen"
 	"github.com/romansod/roll-dice/internal/testing_utils"
+	"github.com/romansod/roll-dice/internal/utilities"
 )
 
 // Helper function for this test file to batch test the existenec of a solution
@@ -63,7 +71,7 @@ func TestGetSlotForPrint(t *testing.T) {
 		testing_utils.AssertEQ(
 			t,
 			"["+fmt.Sprintf("%d", i+1)+"]", // [1] -> [9]
-			GetSlotForPrint(OpenBox, i))
+			GetSlotForPrint(OpenBox, i, SizeBox))
 	}
 
 	// Retrive all slots (shut)
@@ -71,10 +79,63 @@ func TestGetSlotForPrint(t *testing.T) {
 		testing_utils.AssertEQ(
 			t,
 			"["+EmptySlot+"]", // [_] x 9
-			GetSlotForPrint(ShutBox, i))
+			GetSlotForPrint(ShutBox, i, SizeBox))
 	}
 }
 
+func TestSlotStyle(t *testing.T) {
+	// Alternative slot styles display and convert back to the same state
+
+	parens := SlotStyle{Open: "(", Close: ")", Empty: " "}
+	crossed := SlotStyle{Open: "[", Close: "]", Empty: "X"}
+	dotted := SlotStyle{Open: "<", Close: ">", Empty: "·"}
+
+	gstate := ConvertSlotsToGameState("[1][_][3][_][5][6][_][8][_]", SizeBox)
+
+	display := parens.AssembleSlotsToDisplay(gstate, SizeBox)
+	testing_utils.AssertEQ(t, "(1)( )(3)( )(5)(6)( )(8)( )", display)
+	testing_utils.AssertEQi(t, gstate, parens.ConvertSlotsToGameState(display, SizeBox))
+
+	display = crossed.AssembleSlotsToDisplay(gstate, SizeBox)
+	testing_utils.AssertEQ(t, "[1][X][3][X][5][6][X][8][X]", display)
+	testing_utils.AssertEQi(t, gstate, crossed.ConvertSlotsToGameState(display, SizeBox))
+
+	// Multi-byte markers are wider in bytes than the open slots
+	display = dotted.AssembleSlotsToDisplay(gstate, SizeBox)
+	testing_utils.AssertEQ(t, "<1><·><3><·><5><6><·><8><·>", display)
+	testing_utils.AssertEQi(t, gstate, dotted.ConvertSlotsToGameState(display, SizeBox))
+
+	// Every state of a 12 slot box round trips, values are right aligned
+	for gstate := 0; gstate <= OpenBoxOf(MaxSizeBox); gstate += 97 {
+		display = parens.AssembleSlotsToDisplay(gstate, MaxSizeBox)
+		testing_utils.AssertEQi(t, gstate, parens.ConvertSlotsToGameState(display, MaxSizeBox))
+	}
+	testing_utils.AssertEQ(t, "(  )", parens.GetSlotForPrint(ShutBox, 0, MaxSizeBox))
+	testing_utils.AssertEQ(t, "( 1)", parens.GetSlotForPrint(OpenBoxOf(MaxSizeBox), 0, MaxSizeBox))
+
+	// Markers wider than the values widen every slot
+	wide := SlotStyle{Open: "[", Close: "]", Empty: "--"}
+	display = wide.AssembleSlotsToDisplay(gstate, SizeBox)
+	testing_utils.AssertEQ(t, "[ 1][--][ 3][--][ 5][ 6][--][ 8][--]", display)
+	testing_utils.AssertEQi(t, gstate, wide.ConvertSlotsToGameState(display, SizeBox))
+
+	// The game draws its state in its style
+	shutTheBox := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
+	shutTheBox.SetSlotStyle(parens)
+	shutTheBox.gameState = gstate
+	states := []string{}
+	shutTheBox.states = &states
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	shutTheBox.printGameState()
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	testing_utils.AssertEQSlice(t, []string{"(1)( )(3)( )(5)(6)( )(8)( )"}, states)
+
+	// The default style is unchanged
+	testing_utils.AssertEQ(t, "[1][_][3][_][5][6][_][8][_]", AssembleSlotsToDisplay(gstate, SizeBox))
+}
+
 func TestSetBitEmpty(t *testing.T) {
 	// Check the setting of bits to empty
 
@@ -95,6 +156,62 @@ func TestSetBitEmpty(t *testing.T) {
 	testing_utils.AssertEQb(t, true, IsBoxEmpty(setvar))
 }
 
+func TestIsLegalMove(t *testing.T) {
+	// Same checks as TestIsValidShutInput, without applying the move
+
+	illegal := []struct {
+		gstate int
+		size   int
+		update string
+		target int
+		err    string
+	}{
+		// (-) Invalid inputs
+		{OpenBox, SizeBox, "", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		{OpenBox, SizeBox, "1a345", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		{OpenBox, SizeBox, "asdf", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		{OpenBox, SizeBox, "-2", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		{OpenBox, SizeBox, "0", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		{OpenBox, SizeBox, "4209", 6, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		// (-) Duplicate digits
+		{OpenBox, SizeBox, "11", 2, fmt.Sprintf(ErrDuplicateDigit, 1)},
+		{OpenBox, SizeBox, "121", 4, fmt.Sprintf(ErrDuplicateDigit, 1)},
+		{OpenBoxOf(12), 12, "10 2 10", 22, fmt.Sprintf(ErrDuplicateDigit, 10)},
+		{OpenBox, SizeBox, "11a", 2, fmt.Sprintf(ErrInvDigit, SizeBox)},
+		// (-) Combined != Target
+		{OpenBox, SizeBox, "1", 6, fmt.Sprintf(ErrNotEqTarget, 1, 6)},
+		{OpenBox, SizeBox, "145", 6, fmt.Sprintf(ErrNotEqTarget, 10, 6)},
+		{OpenBox, SizeBox, "12345", 6, fmt.Sprintf(ErrNotEqTarget, 15, 6)},
+		// (-) Closed slots
+		{ConvertSlotsToGameState("[_][2][3][4][5][6][7][8][9]", SizeBox), SizeBox, "12", 3,
+			"slot 1 is already closed. Please try again"},
+	}
+
+	for _, move := range illegal {
+		legal, err := IsLegalMove(move.gstate, move.size, move.update, move.target)
+		testing_utils.AssertEQb(t, false, legal)
+		testing_utils.AssertEQ(t, move.err, err.Error())
+	}
+
+	// (+) Combined == Target
+	for _, move := range []struct {
+		update string
+		target int
+	}{{"1", 1}, {"45", 9}, {"1245", 12}, {"134", 8}, {"1, 3, 7", 11}} {
+		legal, err := IsLegalMove(OpenBox, SizeBox, move.update, move.target)
+		testing_utils.AssertEQb(t, true, legal)
+		testing_utils.AssertNIL(t, err)
+	}
+
+	// Legal exactly when the update would be applied
+	for _, update := range []string{"12", "21", "3", "1,2", "4"} {
+		legal, legalErr := IsLegalMove(OpenBox, SizeBox, update, 3)
+		_, err := processProposedUpdate(OpenBox, SizeBox, update, 3)
+		testing_utils.AssertEQb(t, err == nil, legal)
+		testing_utils.AssertEQb(t, true, fmt.Sprint(err) == fmt.Sprint(legalErr))
+	}
+}
+
 func TestIsValidShutInput(t *testing.T) {
 	// Test the verification and processing of input from a players turn
 
@@ -104,59 +221,76 @@ func TestIsValidShutInput(t *testing.T) {
 	gstate := OpenBox
 
 	// (-) Invalid inputs
-	_, err := processProposedUpdate(gstate, "", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err := processProposedUpdate(gstate, SizeBox, "", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
+
+	gstate = OpenBox
+	_, err = processProposedUpdate(gstate, SizeBox, "1a345", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
+
+	gstate = OpenBox
+	_, err = processProposedUpdate(gstate, SizeBox, "asdf", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
+
+	gstate = OpenBox
+	_, err = processProposedUpdate(gstate, SizeBox, "-2", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
 
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "1a345", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err = processProposedUpdate(gstate, SizeBox, "0", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
 
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "asdf", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err = processProposedUpdate(gstate, SizeBox, "4209", 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
 
+	// (-) Duplicate digits
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "-2", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err = processProposedUpdate(gstate, SizeBox, "11", 2)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrDuplicateDigit, 1), err.Error())
 
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "0", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err = processProposedUpdate(gstate, SizeBox, "121", 4)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrDuplicateDigit, 1), err.Error())
+
+	_, err = processProposedUpdate(OpenBoxOf(12), 12, "10 2 10", 22)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrDuplicateDigit, 10), err.Error())
 
+	// Invalid digits are reported before duplicates
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "4209", 6)
-	testing_utils.AssertEQ(t, ErrInvDigit, err.Error())
+	_, err = processProposedUpdate(gstate, SizeBox, "11a", 2)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
 
 	// (-) Combined != Target
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "1", 6)
+	_, err = processProposedUpdate(gstate, SizeBox, "1", 6)
 	testing_utils.AssertEQ(t, fmt.Sprintf(ErrNotEqTarget, 1, 6), err.Error())
 
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "145", 6)
+	_, err = processProposedUpdate(gstate, SizeBox, "145", 6)
 	testing_utils.AssertEQ(t, fmt.Sprintf(ErrNotEqTarget, 10, 6), err.Error())
 
 	gstate = OpenBox
-	_, err = processProposedUpdate(gstate, "12345", 6)
+	_, err = processProposedUpdate(gstate, SizeBox, "12345", 6)
 	testing_utils.AssertEQ(t, fmt.Sprintf(ErrNotEqTarget, 15, 6), err.Error())
 
 	// (+) Combined == Target
 	gstate = OpenBox
-	gstate_processed, err := processProposedUpdate(gstate, "1", 1)
+	gstate_processed, err := processProposedUpdate(gstate, SizeBox, "1", 1)
 	testing_utils.AssertNIL(t, err)
-	testing_utils.AssertEQ(t, "[_][2][3][4][5][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed))
+	testing_utils.AssertEQ(t, "[_][2][3][4][5][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed, SizeBox))
 
-	gstate_processed, err = processProposedUpdate(gstate, "45", 9)
+	gstate_processed, err = processProposedUpdate(gstate, SizeBox, "45", 9)
 	testing_utils.AssertNIL(t, err)
-	testing_utils.AssertEQ(t, "[1][2][3][_][_][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed))
+	testing_utils.AssertEQ(t, "[1][2][3][_][_][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed, SizeBox))
 
-	gstate_processed, err = processProposedUpdate(gstate, "1245", 12)
+	gstate_processed, err = processProposedUpdate(gstate, SizeBox, "1245", 12)
 	testing_utils.AssertNIL(t, err)
-	testing_utils.AssertEQ(t, "[_][_][3][_][_][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed))
+	testing_utils.AssertEQ(t, "[_][_][3][_][_][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed, SizeBox))
 
-	gstate_processed, err = processProposedUpdate(gstate, "134", 8)
+	gstate_processed, err = processProposedUpdate(gstate, SizeBox, "134", 8)
 	testing_utils.AssertNIL(t, err)
-	testing_utils.AssertEQ(t, "[_][2][_][_][5][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed))
+	testing_utils.AssertEQ(t, "[_][2][_][_][5][6][7][8][9]", AssembleSlotsToDisplay(gstate_processed, SizeBox))
 
 	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
 }
@@ -168,7 +302,7 @@ func TestUpdateGameState(t *testing.T) {
 
 	// Capture the print output for testing
 	origStdout, r, w := testing_utils.RedirectStdout()
-	stb := NewShutBox([]string{"p1"})
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
 	stb.printGameState()
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	testing_utils.AssertEQ(t, "\n\nPlayer: p1\n\n[1][2][3][4][5][6][7][8][9]\n", output)
@@ -249,7 +383,7 @@ func TestTargetSumExists(t *testing.T) {
 	// be composites
 
 	// Fully open box
-	bitset := ConvertSlotsToGameState("[1][2][3][4][5][6][7][8][9]")
+	bitset := ConvertSlotsToGameState("[1][2][3][4][5][6][7][8][9]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -271,7 +405,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Composite of 2 covers the missing slot 4
-	bitset = ConvertSlotsToGameState("[1][2][3][_][5][6][7][8][9]")
+	bitset = ConvertSlotsToGameState("[1][2][3][_][5][6][7][8][9]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -293,7 +427,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Four 2 slot composites
-	bitset = ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]")
+	bitset = ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -315,7 +449,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// 3 slots
-	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][9]")
+	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][9]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -337,7 +471,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Two slots
-	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][_]")
+	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][_]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -359,7 +493,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Single slot
-	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][_][_]")
+	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][_][_]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -381,7 +515,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Shut box
-	bitset = ConvertSlotsToGameState("[_][_][_][_][_][_][_][_][_]")
+	bitset = ConvertSlotsToGameState("[_][_][_][_][_][_][_][_][_]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -403,7 +537,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Composite of 2 slots for 9
-	bitset = ConvertSlotsToGameState("[1][_][_][_][_][_][_][8][_]")
+	bitset = ConvertSlotsToGameState("[1][_][_][_][_][_][_][8][_]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -425,7 +559,7 @@ func TestTargetSumExists(t *testing.T) {
 	)
 
 	// Composite of 3 slots for 10
-	bitset = ConvertSlotsToGameState("[_][2][3][_][5][_][_][_][_]")
+	bitset = ConvertSlotsToGameState("[_][2][3][_][5][_][_][_][_]", SizeBox)
 	testing_utils.AssertNIL(
 		t,
 		CheckPermutations(
@@ -447,6 +581,57 @@ func TestTargetSumExists(t *testing.T) {
 	)
 }
 
+func TestTargetSumExistsBacktracking(t *testing.T) {
+	// A failed split must give back the slots its sub targets consumed,
+	// so the caller's game state only loses the slots of the solution
+
+	cases := []struct {
+		slots  string
+		target int
+		exists bool
+		after  string
+	}{
+		// 1 is consumed by the first split, 1+8, which fails. The solution
+		// 2+3+4 is only found once 1 is given back
+		{"[1][2][3][4][_][_][_][_][_]", 9, true, "[1][_][_][_][_][_][_][_][_]"},
+		// The first split 1+6, with 6 = 2+4, closes exactly those slots
+		{"[1][2][3][4][_][_][_][_][_]", 7, true, "[_][_][3][_][_][_][_][_][_]"},
+		{"[_][2][3][_][_][_][_][_][_]", 5, true, "[_][_][_][_][_][_][_][_][_]"},
+		// 4 can not be used twice
+		{"[_][2][_][4][_][_][_][_][_]", 8, false, "[_][2][_][4][_][_][_][_][_]"},
+		// Failed splits leave the caller's game state unchanged
+		{"[1][_][_][_][_][_][_][_][_]", 3, false, "[1][_][_][_][_][_][_][_][_]"},
+		{"[1][2][3][_][_][_][_][_][9]", 17, false, "[1][2][3][_][_][_][_][_][9]"},
+	}
+
+	for _, c := range cases {
+		bitset := ConvertSlotsToGameState(c.slots, SizeBox)
+		testing_utils.AssertEQb(t, c.exists, TargetSumExists(&bitset, c.target))
+		testing_utils.AssertEQ(t, c.after, AssembleSlotsToDisplay(bitset, SizeBox))
+	}
+
+	// Every state and target agrees with FindAllSolutions, and a solution
+	// closes open slots summing to exactly the target
+	for gstate := range OpenBox + 1 {
+		for target := 2; target <= 18; target++ {
+			bitset := gstate
+			exists := TargetSumExists(&bitset, target)
+			testing_utils.AssertEQb(t, len(FindAllSolutions(gstate, target)) > 0, exists)
+			testing_utils.AssertEQi(t, 0, bitset&^gstate)
+
+			closed := 0
+			for _, slot := range closedSlots(gstate, bitset) {
+				closed += slot
+			}
+			if exists {
+				testing_utils.AssertEQi(t, target, closed)
+			} else {
+				testing_utils.AssertEQi(t, 0, closed)
+			}
+		}
+	}
+}
+
 func TestNextTurn(t *testing.T) {
 	// Test the behavior for setting up the next turn, which includes:
 	// - opening the box
@@ -454,7 +639,7 @@ func TestNextTurn(t *testing.T) {
 
 	// Capture the print output for testing
 	origStdout, r, w := testing_utils.RedirectStdout()
-	stb := NewShutBox([]string{"p1", "p2", "p3", "p4"})
+	stb := NewShutBox([]string{"p1", "p2", "p3", "p4"}, SizeBox, DiceHybrid, NumDice, nil)
 	stb.printGameState()
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	testing_utils.AssertEQ(t, "\n\nPlayer: p1\n\n[1][2][3][4][5][6][7][8][9]\n", output)
@@ -539,3 +724,1125 @@ func TestNextTurn(t *testing.T) {
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	testing_utils.AssertEQ(t, "\n\nPlayer: p1\n\n[1][2][3][4][5][6][7][8][9]\n", output)
 }
+
+func TestFindAllSolutions(t *testing.T) {
+	// Check that every distinct combination is found for a target
+
+	// Fully open box, target 5
+	solutions := FindAllSolutions(OpenBox, 5)
+	testing_utils.AssertEQ(t, "[[5] [1 4] [2 3]]", fmt.Sprint(solutions))
+
+	// Fully open box, target 10
+	solutions = FindAllSolutions(OpenBox, 10)
+	testing_utils.AssertEQ(
+		t,
+		"[[1 9] [2 8] [3 7] [4 6] [1 2 7] [1 3 6] [1 4 5] [2 3 5] [1 2 3 4]]",
+		fmt.Sprint(solutions))
+
+	// Closed slots are never used
+	bitset := ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]", SizeBox)
+	solutions = FindAllSolutions(bitset, 8)
+	testing_utils.AssertEQ(t, "[[8] [2 6] [3 5]]", fmt.Sprint(solutions))
+
+	// No solution
+	bitset = ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][9]", SizeBox)
+	solutions = FindAllSolutions(bitset, 7)
+	testing_utils.AssertEQi(t, 0, len(solutions))
+
+	// Shut box
+	solutions = FindAllSolutions(ShutBox, 7)
+	testing_utils.AssertEQi(t, 0, len(solutions))
+
+	// Game state is not modified
+	bitset = OpenBox
+	FindAllSolutions(bitset, 12)
+	testing_utils.AssertEQi(t, OpenBox, bitset)
+}
+
+func TestPrintHints(t *testing.T) {
+	// Hints are printed as the input the player would enter
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb.printHints(5)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "\nPossible solutions: 5, 14, 23\n", output)
+}
+
+func TestQuitFromGame(t *testing.T) {
+	// Quitting at the slot prompt cleanly terminates the program. Every
+	// target of two dice is solvable in an open box, so the game prompts
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	origStdin, r := testing_utils.RedirectStdin("quit\n")
+	origExit, origConfirm := utilities.Exit, utilities.ConfirmQuit
+	utilities.ConfirmQuit = false
+
+	exitCode := -1
+	utilities.Exit = func(code int) { exitCode = code }
+
+	// Run must return rather than prompt again
+	NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil).Run()
+	testing_utils.AssertEQi(t, 0, exitCode)
+
+	utilities.Exit, utilities.ConfirmQuit = origExit, origConfirm
+	testing_utils.RestoreStdin(origStdin, r)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestCountOpenSlots(t *testing.T) {
+	// Number of open slots left in the game state
+
+	testing_utils.AssertEQi(t, 9, CountOpenSlots(OpenBox))
+	testing_utils.AssertEQi(t, 0, CountOpenSlots(ShutBox))
+	testing_utils.AssertEQi(
+		t, 6, CountOpenSlots(ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]", SizeBox)))
+	testing_utils.AssertEQi(
+		t, 1, CountOpenSlots(ConvertSlotsToGameState("[_][_][_][_][_][6][_][_][_]", SizeBox)))
+	testing_utils.AssertEQi(t, 12, CountOpenSlots(OpenBoxOf(12)))
+}
+
+func TestSumOpenSlots(t *testing.T) {
+	// Score is the sum of the open slots left in the game state
+
+	testing_utils.AssertEQi(t, 45, SumOpenSlots(OpenBox))
+	testing_utils.AssertEQi(t, 0, SumOpenSlots(ShutBox))
+	testing_utils.AssertEQi(
+		t, 33, SumOpenSlots(ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]", SizeBox)))
+	testing_utils.AssertEQi(
+		t, 6, SumOpenSlots(ConvertSlotsToGameState("[_][_][_][_][_][6][_][_][_]", SizeBox)))
+}
+
+func TestScoring(t *testing.T) {
+	// Scores accumulate per player and the scoreboard ranks ties together
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	stb := NewShutBox([]string{"p1", "p2", "p3"}, SizeBox, DiceHybrid, NumDice, nil)
+
+	// p1 is stuck with [_][2][3][_][5][6][_][8][9]
+	stb.updateGameState("147", 12)
+	stb.finishTurn()
+	// p2 shuts the box
+	stb.gameState = ShutBox
+	stb.finishTurn()
+	// p3 is stuck with only 6 open
+	stb.gameState = ConvertSlotsToGameState("[_][_][_][_][_][6][_][_][_]", SizeBox)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// Round completes with p3, printing the scoreboard
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.finishTurn()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "\nScoreboard:\n\n1) p2 : 0\n2) p3 : 6\n3) p1 : 33\n", output)
+
+	// Next round starts with p1 and an open box
+	testing_utils.AssertEQi(t, 0, stb.player_i)
+	testing_utils.AssertEQi(t, OpenBox, stb.gameState)
+
+	// Shared placement skips the following place
+	ranks := RankScores([]string{"p1", "p2", "p3", "p4"}, []int{12, 0, 0, 7})
+	testing_utils.AssertEQ(t, "[{1 p2 0} {1 p3 0} {3 p4 7} {4 p1 12}]", fmt.Sprint(ranks))
+
+	ranks = RankScores([]string{"p1", "p2"}, []int{5, 5})
+	testing_utils.AssertEQ(t, "[{1 p1 5} {1 p2 5}]", fmt.Sprint(ranks))
+}
+
+func TestBoxSize12(t *testing.T) {
+	// The 1-12 variant aligns single and double digit slots
+
+	openBox12 := OpenBoxOf(12)
+	testing_utils.AssertEQ(
+		t,
+		"[ 1][ 2][ 3][ 4][ 5][ 6][ 7][ 8][ 9][10][11][12]",
+		AssembleSlotsToDisplay(openBox12, 12))
+	testing_utils.AssertEQ(t, "[ _]", GetSlotForPrint(ShutBox, 11, 12))
+	testing_utils.AssertEQi(t, 78, SumOpenSlots(openBox12))
+
+	// Display round trips through the game state
+	gslots := "[ _][ 2][ 3][ _][ 5][ 6][ _][ 8][ 9][ _][11][ _]"
+	gstate := ConvertSlotsToGameState(gslots, 12)
+	testing_utils.AssertEQ(t, gslots, AssembleSlotsToDisplay(gstate, 12))
+	testing_utils.AssertEQi(t, openBox12, ConvertSlotsToGameState(AssembleSlotsToDisplay(openBox12, 12), 12))
+	testing_utils.AssertEQi(t, 1, ConvertSlotToBit(gslots, 10, 12))
+	testing_utils.AssertEQi(t, 0, ConvertSlotToBit(gslots, 11, 12))
+
+	// Slots are entered together or separated by commas or spaces
+	for _, update := range []string{"137", "1,3,7", "1 3 7", "1, 3, 7"} {
+		gstate, err := processProposedUpdate(OpenBox, SizeBox, update, 11)
+		testing_utils.AssertNIL(t, err)
+		testing_utils.AssertEQ(t, "[_][2][_][4][5][6][_][8][9]", AssembleSlotsToDisplay(gstate, SizeBox))
+	}
+	testing_utils.AssertEQ(t, "[1 3 7]", fmt.Sprint(splitSlots("1 3 7")))
+	testing_utils.AssertEQ(t, "[1 3 7]", fmt.Sprint(splitSlots("1,3,7")))
+	testing_utils.AssertEQ(t, "[1 3 7]", fmt.Sprint(splitSlots("137")))
+
+	// Double digit slots are entered separated by commas or spaces
+	stb := NewShutBox([]string{"p1"}, 12, DiceHybrid, NumDice, nil)
+	testing_utils.AssertNIL(t, stb.updateGameState("1,10", 11))
+	testing_utils.AssertNIL(t, stb.updateGameState("2", 2))
+	testing_utils.AssertNIL(t, stb.updateGameState("4 8", 12))
+	testing_utils.AssertEQ(
+		t,
+		"[ _][ _][ 3][ _][ 5][ 6][ 7][ _][ 9][ _][11][12]",
+		AssembleSlotsToDisplay(stb.gameState, 12))
+
+	// Slots beyond the box are invalid
+	_, err := processProposedUpdate(openBox12, 12, "1 13", 14)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, 12), err.Error())
+	_, err = processProposedUpdate(OpenBox, SizeBox, "1,10", 11)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvDigit, SizeBox), err.Error())
+
+	// Targets are solved with the higher slots
+	testing_utils.AssertEQ(t, "[[12] [1 11] [2 10] [3 9]]", fmt.Sprint(FindAllSolutions(openBox12, 12)[:4]))
+	bitset := ConvertSlotsToGameState("[ _][ _][ _][ _][ _][ _][ _][ _][ _][ _][11][ _]", 12)
+	testing_utils.AssertEQb(t, true, TargetSumExists(&bitset, 11))
+	testing_utils.AssertEQ(t, "1,10", SolutionToInput([]int{1, 10}))
+
+	// Reset opens all 12 slots and a shut box still wins
+	stb.resetBox()
+	testing_utils.AssertEQi(t, openBox12, stb.gameState)
+	testing_utils.AssertEQb(t, true, IsBoxEmpty(ConvertSlotsToGameState(
+		"[ _][ _][ _][ _][ _][ _][ _][ _][ _][ _][ _][ _]", 12)))
+
+	// Supported box sizes
+	testing_utils.AssertEQb(t, false, ValidBoxSize(8))
+	testing_utils.AssertEQb(t, true, ValidBoxSize(SizeBox))
+	testing_utils.AssertEQb(t, true, ValidBoxSize(MaxSizeBox))
+	testing_utils.AssertEQb(t, false, ValidBoxSize(13))
+}
+
+func TestOneDie(t *testing.T) {
+	// A single die is offered once slots 7, 8 and 9 are shut
+
+	testing_utils.AssertEQb(t, false, HighSlotsShut(OpenBox))
+	testing_utils.AssertEQb(t, false, HighSlotsShut(ConvertSlotsToGameState("[1][2][3][4][5][6][_][_][9]", SizeBox)))
+	testing_utils.AssertEQb(t, true, HighSlotsShut(ConvertSlotsToGameState("[1][2][3][4][5][6][_][_][_]", SizeBox)))
+	testing_utils.AssertEQb(t, true, HighSlotsShut(ShutBox))
+	testing_utils.AssertEQb(t, false, HighSlotsShut(OpenBoxOf(12)&^OpenBox))
+
+	// The player is asked and may pick one die
+	var stdin bytes.Buffer
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stdin.Write([]byte("1"))
+	done, numDice := chooseNumDice(&stdin, NumDice)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQi(t, 1, numDice)
+	testing_utils.AssertEQ(t, "\nAll high slots are shut. Roll one die or all 2 dice? [1/2]\n\n", output)
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	stdin.Reset()
+	stdin.Write([]byte("2"))
+	_, numDice = chooseNumDice(&stdin, NumDice)
+	testing_utils.AssertEQi(t, 2, numDice)
+
+	stdin.Reset()
+	stdin.Write([]byte("\n"))
+	done, _ = chooseNumDice(&stdin, NumDice)
+	testing_utils.AssertEQb(t, true, done)
+
+	// A single die produces targets in [1,6]
+	for i := 0; i < 100; i++ {
+		target := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil).rollTarget(1)
+		testing_utils.AssertEQb(t, true, target >= 1 && target <= 6)
+	}
+
+	// Every single die target is checked against the open low slots
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb.gameState = ConvertSlotsToGameState("[_][2][3][4][5][6][_][_][_]", SizeBox)
+	testing_utils.AssertEQb(t, false, stb.checkSolutionExists(1))
+	for target := 2; target <= 6; target++ {
+		testing_utils.AssertEQb(t, true, stb.checkSolutionExists(target))
+	}
+	testing_utils.AssertNIL(t, stb.updateGameState("4", 4))
+	testing_utils.AssertEQ(t, "[_][2][3][_][5][6][_][_][_]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestChallengeSeed(t *testing.T) {
+	// Games with the same challenge seed face identical rolls
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	stb1 := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb2 := NewShutBox([]string{"p2", "p3"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb3 := NewShutBox([]string{"p4"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb1.SetChallengeSeed(42)
+	stb2.SetChallengeSeed(42)
+	stb3.SetChallengeSeed(7)
+
+	// Advance every game through the same number of one and two dice rolls
+	differs := false
+	for i := 0; i < 50; i++ {
+		numDice := 1 + i%2
+		target1, target2, target3 := stb1.rollTarget(numDice), stb2.rollTarget(numDice), stb3.rollTarget(numDice)
+		testing_utils.AssertEQi(t, target1, target2)
+		differs = differs || target1 != target3
+	}
+
+	// A different seed is different luck
+	testing_utils.AssertEQb(t, true, differs)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestSaveLoadGame(t *testing.T) {
+	// An in-progress game round trips through a saved file
+
+	path := filepath.Join(t.TempDir(), "game.json")
+
+	stb := NewShutBox([]string{"p1", "p2", "p3"}, SizeBox, DiceHybrid, NumDice, nil)
+	stb.nextPlayer()
+	stb.scores[0] = 12
+	testing_utils.AssertNIL(t, stb.updateGameState("147", 12))
+	testing_utils.AssertNIL(t, stb.SaveGame(path))
+
+	// Undo history and the last move are not saved, a resumed turn starts
+	// without them
+	stb.undoStack = []int{}
+	stb.lastClosed = 0
+
+	loaded, err := LoadGame(path)
+	testing_utils.AssertNIL(t, err)
+
+	// Match statistics are not saved either, they restart with the next run
+	testing_utils.AssertEQ(t, fmt.Sprintf("%+v", *stb.stats), fmt.Sprintf("%+v", *loaded.stats))
+	loaded.stats = stb.stats
+	// Compared field by field rather than through ShutTheBox.String
+	type fields ShutTheBox
+	testing_utils.AssertEQ(t, fmt.Sprintf("%+v", fields(*stb)), fmt.Sprintf("%+v", fields(*loaded)))
+	testing_utils.AssertEQ(t, "[_][2][3][_][5][6][_][8][9]", AssembleSlotsToDisplay(loaded.gameState, loaded.boxSize))
+
+	// Invalid saved games are rejected with a description
+	write := func(saved string) error {
+		testing_utils.AssertNIL(t, os.WriteFile(path, []byte(saved), 0644))
+		_, err := LoadGame(path)
+		return err
+	}
+
+	err = write(`{"game_state":512,"box_size":9,"players":["p1"],"player_i":0,"scores":[0]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrSavedGameState, 512, 9), err.Error())
+
+	err = write(`{"game_state":-1,"box_size":9,"players":["p1"],"player_i":0,"scores":[0]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrSavedGameState, -1, 9), err.Error())
+
+	err = write(`{"game_state":3,"box_size":9,"players":["p1","p2"],"player_i":2,"scores":[0,0]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrSavedPlayer, 2, 2), err.Error())
+
+	err = write(`{"game_state":3,"box_size":9,"players":[],"player_i":0,"scores":[]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrSavedPlayer, 0, 0), err.Error())
+
+	err = write(`{"game_state":3,"box_size":9,"players":["p1"],"player_i":0,"scores":[]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrSavedScores, 0, 1), err.Error())
+
+	err = write(`{"game_state":3,"box_size":20,"players":["p1"],"player_i":0,"scores":[0]}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvalidBoxSize, SizeBox, MaxSizeBox), err.Error())
+
+	err = write(`{"game_state":3,"box_size":9,"players":["p1"],"player_i":0,"scores":[0],"num_dice":4}`)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvalidNumDice, NumDice, MaxNumDice), err.Error())
+
+	// A 12 slot box fits more than 9 bits
+	err = write(`{"game_state":4095,"box_size":12,"players":["p1"],"player_i":0,"scores":[0]}`)
+	testing_utils.AssertNIL(t, err)
+
+	// Missing file
+	_, err = LoadGame(filepath.Join(t.TempDir(), "missing.json"))
+	testing_utils.AssertEQb(t, true, os.IsNotExist(err))
+}
+
+func TestAutoMove(t *testing.T) {
+	// The AI always picks a legal move when one exists
+
+	gstates := []int{
+		OpenBox,
+		ConvertSlotsToGameState("[_][2][3][_][5][6][_][8][9]", SizeBox),
+		ConvertSlotsToGameState("[1][_][3][_][5][_][7][_][9]", SizeBox),
+		ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox),
+	}
+
+	for _, gstate := range gstates {
+		for target := 1; target <= 12; target++ {
+			for _, strategy := range []Strategy{StrategyHighest, StrategyMost} {
+				move, ok := autoMoveWith(gstate, target, strategy)
+				testing_utils.AssertEQb(t, len(FindAllSolutions(gstate, target)) > 0, ok)
+				if ok {
+					_, err := processProposedUpdate(gstate, SizeBox, move, target)
+					testing_utils.AssertNIL(t, err)
+				}
+			}
+		}
+	}
+
+	// Highest value slots are preferred by default
+	move, ok := autoMove(OpenBox, 10)
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertEQ(t, "19", move)
+	move, _ = autoMove(OpenBox, 12)
+	testing_utils.AssertEQ(t, "39", move)
+	move, _ = autoMove(ConvertSlotsToGameState("[1][2][3][4][5][6][_][_][_]", SizeBox), 6)
+	testing_utils.AssertEQ(t, "6", move)
+
+	// Or the most slots
+	move, _ = autoMoveWith(OpenBox, 10, StrategyMost)
+	testing_utils.AssertEQ(t, "1234", move)
+	move, _ = autoMoveWith(OpenBox, 12, StrategyMost)
+	testing_utils.AssertEQ(t, "1236", move)
+
+	// No legal move
+	move, ok = autoMove(ConvertSlotsToGameState("[_][_][_][_][_][6][_][8][9]", SizeBox), 7)
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "", move)
+	_, ok = autoMove(ShutBox, 2)
+	testing_utils.AssertEQb(t, false, ok)
+}
+
+func TestSetAI(t *testing.T) {
+	// One flag per player, anything else leaves the players as they were
+
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3))
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrAIPlayers, 1, 2), stb.SetAI([]bool{true}).Error())
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrAIPlayers, 3, 2), stb.SetAI([]bool{true, true, true}).Error())
+	testing_utils.AssertEQ(t, "[false false]", fmt.Sprint(stb.ai))
+
+	testing_utils.AssertNIL(t, stb.SetAI([]bool{true, false}))
+	testing_utils.AssertEQ(t, "[true false]", fmt.Sprint(stb.ai))
+
+	// The AI player moves without input
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nTarget sum is '9' . p1 closes '9'\n"))
+
+	// (-) Replayed records are checked too
+	_, err := ReplayGame(GameRecord{Players: []string{"p1"}, AI: []bool{true, false}, BoxSize: SizeBox})
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrAIPlayers, 2, 1), err.Error())
+}
+
+func TestUndoInRun(t *testing.T) {
+	// p1 closes 9 on 6+3 and takes it back, then closes 4 and 5 instead and
+	// keeps it. The next roll of 1+1 closes 2, and the move is kept too
+
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3, 1, 1, 1, 1))
+	states := []string{}
+	stb.states = &states
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nundo\n45\nok\n2\nok\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, false, strings.Contains(output, ErrNothingToUndo))
+	testing_utils.AssertEQSlice(
+		t,
+		[]string{
+			AssembleSlotsToDisplay(OpenBoxOf(SizeBox), SizeBox),
+			AssembleSlotsToDisplay(OpenBoxOf(SizeBox), SizeBox),
+			"[1][2][3][_][_][6][7][8][9]",
+			"[1][_][3][_][_][6][7][8][9]",
+		},
+		states[:4])
+}
+
+func TestUndo(t *testing.T) {
+	// Updates of the current turn are taken back one at a time
+
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, nil)
+
+	// Nothing to undo at the start of a turn
+	testing_utils.AssertEQ(t, ErrNothingToUndo, stb.undo().Error())
+
+	testing_utils.AssertNIL(t, stb.updateGameState("147", 12))
+	testing_utils.AssertNIL(t, stb.updateGameState("8", 8))
+	testing_utils.AssertEQ(t, "[_][2][3][_][5][6][_][_][9]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+
+	testing_utils.AssertNIL(t, stb.undo())
+	testing_utils.AssertEQ(t, "[_][2][3][_][5][6][_][8][9]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+
+	testing_utils.AssertNIL(t, stb.undo())
+	testing_utils.AssertEQ(t, "[1][2][3][4][5][6][7][8][9]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+
+	testing_utils.AssertEQ(t, ErrNothingToUndo, stb.undo().Error())
+
+	// Failed updates are not recorded
+	stb.updateGameState("99", 18)
+	testing_utils.AssertEQ(t, ErrNothingToUndo, stb.undo().Error())
+
+	// Undo does not reach across turns
+	testing_utils.AssertNIL(t, stb.updateGameState("9", 9))
+	stb.nextTurn()
+	testing_utils.AssertEQ(t, ErrNothingToUndo, stb.undo().Error())
+	testing_utils.AssertEQ(t, "[1][2][3][4][5][6][7][8][9]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+}
+
+func TestMatch(t *testing.T) {
+	// Round scores are summed into the final match ranking
+
+	players := []string{"p1", "p2", "p3"}
+
+	// Odd number of rounds with a clear winner
+	ranks := MatchStandings(players, [][]int{{5, 0, 9}, {3, 6, 0}, {0, 1, 4}})
+	testing_utils.AssertEQ(t, "[{1 p2 7} {2 p1 8} {3 p3 13}]", fmt.Sprint(ranks))
+
+	// Tied final standings share first place
+	ranks = MatchStandings(players, [][]int{{5, 0, 9}, {0, 5, 0}})
+	testing_utils.AssertEQ(t, "[{1 p1 5} {1 p2 5} {3 p3 9}]", fmt.Sprint(ranks))
+
+	// No rounds played
+	ranks = MatchStandings(players, [][]int{})
+	testing_utils.AssertEQ(t, "[{1 p1 0} {1 p2 0} {1 p3 0}]", fmt.Sprint(ranks))
+
+	// A 3 round match of 2 players ends after the last turn of round 3
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	stb := NewShutBoxMatch([]string{"p1", "p2"}, 3)
+	turns := []string{
+		"[_][_][_][_][_][6][_][_][_]", // p1 : 6
+		"[_][_][_][_][_][_][_][_][_]", // p2 : 0
+		"[1][_][_][_][_][_][_][_][_]", // p1 : 1
+		"[_][_][_][_][_][_][_][_][9]", // p2 : 9
+		"[_][_][_][_][_][_][_][_][_]", // p1 : 0
+	}
+	for _, gslots := range turns {
+		stb.gameState = ConvertSlotsToGameState(gslots, SizeBox)
+		testing_utils.AssertEQb(t, false, stb.finishTurn())
+	}
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.gameState = ConvertSlotsToGameState("[_][2][_][_][_][_][_][_][_]", SizeBox)
+	testing_utils.AssertEQb(t, true, stb.finishTurn())
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQ(t, "[[6 0] [1 9] [0 2]]", fmt.Sprint(stb.roundScores))
+	testing_utils.AssertEQ(
+		t,
+		"\nScoreboard:\n\n1) p1 : 7\n2) p2 : 11\n"+
+			"\nMatch over after 3 rounds\n\n1) p1 : 7\n2) p2 : 11\n\nWinner: p1\n",
+		output)
+
+	// Tied match
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb = NewShutBoxMatch([]string{"p1", "p2"}, 1)
+	stb.gameState = ConvertSlotsToGameState("[_][_][3][_][_][_][_][_][_]", SizeBox)
+	stb.finishTurn()
+	stb.gameState = ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox)
+	testing_utils.AssertEQb(t, true, stb.finishTurn())
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nTied winners: p1, p2\n"))
+}
+
+func TestMaxAttempts(t *testing.T) {
+	// Prompts abort after too many consecutive invalid inputs
+
+	origMax := utilities.MaxAttempts
+	utilities.MaxAttempts = 3
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	var stdin bytes.Buffer
+
+	// Still accepted on the last allowed attempt
+	stdin.Write([]byte("x\nx\n2\n"))
+	done, numDice := chooseNumDice(&stdin, NumDice)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQi(t, 2, numDice)
+	stdin.Reset()
+
+	// N+1 invalid inputs, the last is never read
+	stdin.Write([]byte("x\nx\nx\n1\n"))
+	done, _ = chooseNumDice(&stdin, NumDice)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQ(t, "1\n", stdin.String())
+	stdin.Reset()
+
+	stdin.Write([]byte("a\nb\nc\ny\n"))
+	done, _ = chooseAfterWin(&stdin, false)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQ(t, "y\n", stdin.String())
+	stdin.Reset()
+
+	stdin.Write([]byte("a\nb\ny\n"))
+	done, choice := chooseAfterWin(&stdin, false)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, choice == afterWinNext)
+	stdin.Reset()
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	utilities.MaxAttempts = origMax
+}
+
+func TestBestScore(t *testing.T) {
+	// Optimal play against a known sequence of rolls
+
+	gstate := ConvertSlotsToGameState("[1][2][3][_][_][_][_][_][_]", SizeBox)
+
+	// Closing 3 first keeps 1 and 2 for the next roll
+	testing_utils.AssertEQi(t, 0, BestScore(gstate, [][]int{{1, 2}, {3}}))
+	testing_utils.AssertEQi(t, 0, BestScore(gstate, [][]int{{2, 1}, {1, 2}}))
+
+	// No legal move for the second roll, best is closing 3 and leaving 3
+	testing_utils.AssertEQi(t, 3, BestScore(gstate, [][]int{{1, 2}, {5}}))
+
+	// No legal move for the first roll ends play immediately
+	testing_utils.AssertEQi(t, 6, BestScore(gstate, [][]int{{6, 6}, {1}}))
+
+	// No rolls left, or a shut box
+	testing_utils.AssertEQi(t, 6, BestScore(gstate, [][]int{}))
+	testing_utils.AssertEQi(t, 0, BestScore(ShutBox, [][]int{{1, 1}}))
+
+	// Open box of 45 against 6 rolls
+	rolls := [][]int{{6, 6}, {5, 4}, {4, 3}, {3, 3}, {2, 3}, {1, 3}}
+	testing_utils.AssertEQi(t, 45-12-9-7-6-5-4, BestScore(OpenBox, rolls))
+
+	// Every slot value rolled once in the worst order still shuts the box
+	rolls = [][]int{{1}, {1, 1}, {1, 2}, {2, 2}, {2, 3}, {3, 3}, {3, 4}, {4, 4}, {4, 5}}
+	testing_utils.AssertEQi(t, 0, BestScore(OpenBox, rolls))
+
+	testing_utils.AssertEQ(
+		t,
+		"[1][_][3][_][5][6][7][8][_]",
+		AssembleSlotsToDisplay(closeSlots(OpenBox, []int{2, 4, 9}), SizeBox))
+}
+
+func TestTwoDiceSumProbabilities(t *testing.T) {
+	// Sums of two D6 against the ways of rolling each of the 36 outcomes
+
+	ways := map[int]int{2: 1, 3: 2, 4: 3, 5: 4, 6: 5, 7: 6, 8: 5, 9: 4, 10: 3, 11: 2, 12: 1}
+	probabilities := TwoDiceSumProbabilities()
+	testing_utils.AssertEQi(t, len(ways), len(probabilities))
+
+	total := 0.0
+	for sum, n := range ways {
+		testing_utils.AssertEQf(t, float64(n)/36, probabilities[sum], 1e-9)
+		total += probabilities[sum]
+	}
+	testing_utils.AssertEQf(t, 1, total, 1e-9)
+
+	// Printed on request during a turn
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3))
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("stats\n9\nok\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nChance of each 2d6 target:\n2  :   2.777778%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n7  :  16.666667%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n12 :   2.777778%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[1][2][3][4][5][6][7][8][_]"))
+
+	// Sums of one and three D6
+	probabilities = DiceSumProbabilities(1)
+	testing_utils.AssertEQi(t, 6, len(probabilities))
+	testing_utils.AssertEQf(t, 1.0/6, probabilities[4], 1e-9)
+
+	probabilities = DiceSumProbabilities(3)
+	testing_utils.AssertEQi(t, 16, len(probabilities))
+	testing_utils.AssertEQf(t, 1.0/216, probabilities[3], 1e-9)
+	testing_utils.AssertEQf(t, 27.0/216, probabilities[10], 1e-9)
+
+	// Printed for the dice of the roll: one die, then three dice
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceOne, NumDice, fixedRolls(4))
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("stats\n4\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nChance of each 1d6 target:\n1  :  16.666667%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n6  :  16.666667%\n\n"))
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "2d6"))
+
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceAll, MaxNumDice, fixedRolls(6, 3, 1))
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("stats\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nChance of each 3d6 target:\n3  :   0.462963%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n10 :  12.500000%\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n18 :   0.462963%\n"))
+}
+
+func TestProbNoSolution(t *testing.T) {
+	// Fraction of 2d6 outcomes without a legal move
+
+	// Every target from 2 to 12 is reachable in an open box
+	testing_utils.AssertEQf(t, 0, ProbNoSolution(OpenBox), 1e-9)
+
+	// Only 9 is reachable, rolled 4 ways: 3+6, 4+5, 5+4, 6+3
+	gstate := ConvertSlotsToGameState("[_][_][_][_][_][_][_][_][9]", SizeBox)
+	testing_utils.AssertEQf(t, 32.0/36, ProbNoSolution(gstate), 1e-9)
+
+	// Only 2 and 3 are reachable, rolled 1 and 2 ways
+	gstate = ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox)
+	testing_utils.AssertEQf(t, 33.0/36, ProbNoSolution(gstate), 1e-9)
+
+	// The game state is left unchanged
+	testing_utils.AssertEQ(t, "[1][2][_][_][_][_][_][_][_]", AssembleSlotsToDisplay(gstate, SizeBox))
+
+	// Nothing is reachable once the box is shut
+	testing_utils.AssertEQf(t, 1, ProbNoSolution(ShutBox), 1e-9)
+}
+
+func TestDiceMode(t *testing.T) {
+	// The dice mode chosen at game start decides every roll
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	var stdin bytes.Buffer
+
+	// One die mode rolls a single die, even with the high slots open
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceOne, NumDice, nil)
+	stb.SetChallengeSeed(7)
+	for i := 0; i < 100; i++ {
+		done, numDice := stb.numDiceForRoll(&stdin)
+		testing_utils.AssertEQb(t, false, done)
+		testing_utils.AssertEQi(t, 1, numDice)
+
+		target := stb.rollTarget(numDice)
+		testing_utils.AssertEQb(t, true, target >= 1 && target <= 6)
+
+		// The one die targets are checked against the open slots
+		testing_utils.AssertEQb(t, true, stb.checkSolutionExists(target))
+	}
+
+	// Only the high slots are open, which no single die can reach
+	stb.gameState = ConvertSlotsToGameState("[_][_][_][_][_][_][7][8][9]", SizeBox)
+	for target := 1; target <= 6; target++ {
+		testing_utils.AssertEQb(t, false, stb.checkSolutionExists(target))
+	}
+
+	// All dice mode never offers a single die
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceAll, NumDice, nil)
+	stb.gameState = ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox)
+	_, numDice := stb.numDiceForRoll(&stdin)
+	testing_utils.AssertEQi(t, 2, numDice)
+
+	// Hybrid mode asks once the high slots are shut
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, nil)
+	_, numDice = stb.numDiceForRoll(&stdin)
+	testing_utils.AssertEQi(t, 2, numDice)
+	stb.gameState = ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox)
+	stdin.Write([]byte("1\n"))
+	_, numDice = stb.numDiceForRoll(&stdin)
+	testing_utils.AssertEQi(t, 1, numDice)
+	stdin.Reset()
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	for input, mode := range map[string]DiceMode{"a": DiceAll, "1": DiceOne, "h": DiceHybrid} {
+		parsed, err := ParseDiceMode(input)
+		testing_utils.AssertNIL(t, err)
+		testing_utils.AssertEQi(t, int(mode), int(parsed))
+	}
+	_, err := ParseDiceMode("3")
+	testing_utils.AssertEQ(t, ErrInvalidDiceMode, err.Error())
+}
+
+func TestNumDice(t *testing.T) {
+	// The number of dice summed for the target is set at game start
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	var stdin bytes.Buffer
+
+	// Three dice produce targets in [3,18]
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, 3, nil)
+	stb.SetChallengeSeed(11)
+	for i := 0; i < 100; i++ {
+		_, numDice := stb.numDiceForRoll(&stdin)
+		testing_utils.AssertEQi(t, 3, numDice)
+
+		target := stb.rollTarget(numDice)
+		testing_utils.AssertEQb(t, true, target >= 3 && target <= 18)
+	}
+
+	// Hybrid mode offers one die or all three once the high slots are shut
+	stb.gameState = ConvertSlotsToGameState("[1][2][_][_][_][_][_][_][_]", SizeBox)
+	stdin.Write([]byte("2\n3\n"))
+	done, numDice := stb.numDiceForRoll(&stdin)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQi(t, 3, numDice)
+	stdin.Reset()
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// Targets above 12 are still solved against the open slots
+	bitset := OpenBox
+	testing_utils.AssertEQb(t, true, TargetSumExists(&bitset, 17))
+	bitset = ConvertSlotsToGameState("[1][2][3][_][_][_][_][_][9]", SizeBox)
+	testing_utils.AssertEQb(t, false, TargetSumExists(&bitset, 17))
+
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceAll, 3, nil)
+	testing_utils.AssertEQb(t, true, stb.checkSolutionExists(17))
+	testing_utils.AssertNIL(t, stb.updateGameState("89", 17))
+	testing_utils.AssertEQ(t, "[1][2][3][4][5][6][7][_][_]", AssembleSlotsToDisplay(stb.gameState, SizeBox))
+
+	testing_utils.AssertEQb(t, false, ValidNumDice(1))
+	testing_utils.AssertEQb(t, true, ValidNumDice(NumDice))
+	testing_utils.AssertEQb(t, true, ValidNumDice(MaxNumDice))
+	testing_utils.AssertEQb(t, false, ValidNumDice(MaxNumDice+1))
+}
+
+func TestVerbose(t *testing.T) {
+	// Verbose mode prints each die and the move made, default output does not
+
+	run := func(verbose bool) string {
+		stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(3, 5, 1, 1))
+		stb.SetVerbose(verbose)
+
+		origStdout, r, w := testing_utils.RedirectStdout()
+		stb.RunWith(bytes.NewBufferString("5 3\nok\n\n"))
+		return testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	}
+
+	output := run(true)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nRolled 3 and 5 (target 8)\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\np1 closes 3 and 5\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nRolled 1 and 1 (target 2)\n"))
+
+	output = run(false)
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "Rolled"))
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "closes"))
+
+	testing_utils.AssertEQ(t, "", joinValues([]int{}))
+	testing_utils.AssertEQ(t, "4", joinValues([]int{4}))
+	testing_utils.AssertEQ(t, "1, 2 and 6", joinValues([]int{1, 2, 6}))
+	testing_utils.AssertEQSlice(t, []int{1, 9}, closedSlots(OpenBox, ConvertSlotsToGameState("[_][2][3][4][5][6][7][8][_]", SizeBox)))
+}
+
+// Dice roller cycling through a fixed sequence of die values
+//
+//	Params
+//		values ...int : die values in [1, 6], in the order rolled
+//	Returns
+//		func(int) int : prng returning each value as a number in [0, 6)
+func fixedRolls(values ...int) func(int) int {
+	i := 0
+	return func(int) int {
+		value := values[i%len(values)]
+		i++
+		return GetValueSlot(value)
+	}
+}
+
+func TestGameLoop(t *testing.T) {
+	// A fixed roll sequence plays out the same turns every time
+	//
+	// p1 : 6+3 closes 9, 1+1 closes 2, 1+1 has no move and scores 34
+	// p2 : 1+1 closes 2, 1+1 has no move and scores 43
+	// p1 : 6+3 again starts round 2, then quits
+
+	rolls := fixedRolls(6, 3, 1, 1, 1, 1, 1, 1, 1, 1)
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, rolls)
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\n2\nok\n2\nok\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQSlice(t, []int{34, 43}, stb.scores)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nScoreboard:\n\n1) p1 : 34\n2) p2 : 43\n"))
+
+	// Players alternate, each starting from an open box
+	turns := []string{
+		"Player: p1\n\n[1][2][3][4][5][6][7][8][9]",
+		"Player: p1\n\n[1][2][3][4][5][6][7][8][_]",
+		"Player: p1\n\n[1][_][3][4][5][6][7][8][_]",
+		"Player: p2\n\n[1][2][3][4][5][6][7][8][9]",
+		"Player: p2\n\n[1][_][3][4][5][6][7][8][9]",
+		"Player: p1\n\n[1][2][3][4][5][6][7][8][9]",
+	}
+	for _, turn := range turns {
+		i := strings.Index(output, turn)
+		testing_utils.AssertEQb(t, true, i >= 0)
+		output = output[i+len(turn):]
+	}
+	testing_utils.AssertEQi(t, -1, strings.Index(output, "Player: "))
+}
+
+func TestMatchStats(t *testing.T) {
+	// Counters of a match, same turns as TestGameLoop:
+	//
+	// p1 : 6+3 closes 9, 1+1 closes 2, 1+1 has no move and scores 34
+	// p2 : 1+1 closes 2, 1+1 has no move and scores 43
+	// p1 : 6+3 again starts round 2, then quits
+
+	rolls := fixedRolls(6, 3, 1, 1, 1, 1, 1, 1, 1, 1)
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, rolls)
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\n2\nok\n2\nok\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQi(t, 6, stb.stats.rolls)
+	testing_utils.AssertEQi(t, 2, stb.stats.busts)
+	testing_utils.AssertEQSlice(t, []int{0, 0}, stb.stats.shuts)
+	testing_utils.AssertEQf(t, 38.5, stb.stats.averageRemaining(), 1e-9)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(
+		output,
+		"\nMatch statistics:\n\nRolls : 6\nBusts : 2\nAverage remaining : 38.50\n"+
+			"\nShut boxes:\n\np1 : 0\np2 : 0\n"))
+
+	// A shut box counts for its player and leaves nothing. In a 1 round
+	// match resumed with only 9 open:
+	//
+	// p1 : 6+3 closes 9 and shuts the box
+	// p2 : 1+1 closes 2, 1+1 has no move and scores 43
+	stb = NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3, 1, 1, 1, 1))
+	stb.SetRounds(1)
+	stb.gameState = ConvertSlotsToGameState("[_][_][_][_][_][_][_][_][9]", SizeBox)
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\n2\nok\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	testing_utils.AssertEQi(t, 3, stb.stats.rolls)
+	testing_utils.AssertEQi(t, 1, stb.stats.busts)
+	testing_utils.AssertEQSlice(t, []int{1, 0}, stb.stats.shuts)
+	testing_utils.AssertEQf(t, 21.5, stb.stats.averageRemaining(), 1e-9)
+
+	// Reset at the start of the next run, which rolls once then quits
+	origStdout, ignoreOut = testing_utils.IgnoreStdout()
+	stb.RunWith(bytes.NewBufferString("\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	testing_utils.AssertEQi(t, 1, stb.stats.rolls)
+	testing_utils.AssertEQi(t, 0, stb.stats.busts)
+	testing_utils.AssertEQSlice(t, []int{0, 0}, stb.stats.shuts)
+	testing_utils.AssertEQf(t, 0, stb.stats.averageRemaining(), 1e-9)
+}
+
+// Clock returning the given offsets from a fixed start, one per call
+//
+//	Params
+//		offsets ...time.Duration : time since the start at each call
+//	Returns
+//		func() time.Time : clock for SetClock
+func fakeClock(offsets ...time.Duration) func() time.Time {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	i := 0
+	return func() time.Time {
+		now := start.Add(offsets[i])
+		i++
+		return now
+	}
+}
+
+func TestMoveTimes(t *testing.T) {
+	// Each move is timed from the roll to the valid move, accumulated per
+	// player. Same turns as TestGameLoop:
+	//
+	// p1 : rolls at 0s closes 9 at 5s, rolls at 10s closes 2 at 13s
+	// p2 : rolls at 20s closes 2 at 27s
+	// p1 : rolls at 30s, then quits without moving
+
+	rolls := fixedRolls(6, 3, 1, 1, 1, 1, 1, 1, 1, 1)
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, rolls)
+	stb.SetClock(fakeClock(0, 5*time.Second, 10*time.Second, 13*time.Second, 20*time.Second, 27*time.Second, 30*time.Second))
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\n2\nok\n2\nok\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQSlice(t, []time.Duration{8 * time.Second, 7 * time.Second}, stb.moveTimes)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nTime per player:\n\np1 : 8s\np2 : 7s\n"))
+
+	// Invalid inputs and hints are part of the move
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3, 1, 1))
+	stb.SetClock(fakeClock(0, time.Minute+5*time.Second, 2*time.Minute))
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("1\nhint\n9\nok\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQSlice(t, []time.Duration{time.Minute + 5*time.Second}, stb.moveTimes)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\np1 : 1m5s\n"))
+
+	// Untimed games print no times
+	stb = NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3))
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "Time per player"))
+}
+
+func TestReplayGame(t *testing.T) {
+	// A recorded game replays to the same game states
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	defer testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// Same turns as TestGameLoop, with a hint and an invalid input thrown in
+	rolls := fixedRolls(6, 3, 1, 1, 1, 1, 1, 1, 1, 1)
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, rolls)
+	recorded := []string{}
+	stb.states = &recorded
+	rec := stb.RecordGame(bytes.NewBufferString("9\nok\nhint\n11\n2\nok\n2\nok\n\n"))
+
+	testing_utils.AssertEQSlice(t, []int{6, 3, 1, 1, 1, 1, 1, 1, 1, 1, 6, 3}, rec.Rolls)
+	testing_utils.AssertEQSlice(t, []string{"9", "ok", "hint", "11", "2", "ok", "2", "ok", ""}, rec.Inputs)
+	testing_utils.AssertEQSlice(t, []string{"p1", "p2"}, rec.Players)
+
+	replayed, err := ReplayGame(rec)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQSlice(t, recorded, replayed)
+	testing_utils.AssertEQ(t, "[1][2][3][4][5][6][7][8][_]", replayed[1])
+
+	// CRLF input records the same lines
+	rolls = fixedRolls(6, 3, 1, 1, 1, 1, 1, 1, 1, 1)
+	stb = NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, rolls)
+	crlf := stb.RecordGame(bytes.NewBufferString("9\r\nok\r\nhint\r\n11\r\n2\r\nok\r\n2\r\nok\r\n\r\n"))
+	testing_utils.AssertEQSlice(t, rec.Inputs, crlf.Inputs)
+
+	// (-) Fewer rolls than the inputs play through
+	rec.Rolls = rec.Rolls[:4]
+	_, err = ReplayGame(rec)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrReplayRolls, 4), err.Error())
+
+	// (-) Invalid records
+	_, err = ReplayGame(GameRecord{BoxSize: SizeBox})
+	testing_utils.AssertEQ(t, ErrReplayPlayers, err.Error())
+
+	_, err = ReplayGame(GameRecord{Players: []string{"p1"}, BoxSize: 20})
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrInvalidBoxSize, SizeBox, MaxSizeBox), err.Error())
+
+	// Nothing played
+	replayed, err = ReplayGame(GameRecord{Players: []string{"p1"}, BoxSize: SizeBox, Rolls: []int{6, 3}})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQSlice(t, []string{"[1][2][3][4][5][6][7][8][9]"}, replayed)
+}
+
+func TestAfterWin(t *testing.T) {
+	// p1 resumes with only 9 open and 6+3 shuts the box, then each way of
+	// keeping playing is chosen. The next turn rolls 1+1 then quits
+
+	won := func() *ShutTheBox {
+		stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3, 1, 1))
+		stb.gameState = ConvertSlotsToGameState("[_][_][_][_][_][_][_][_][9]", SizeBox)
+		return stb
+	}
+	openBox := AssembleSlotsToDisplay(OpenBoxOf(SizeBox), SizeBox)
+
+	// Next player in the rotation keeps the box as it is
+	stb := won()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nnext\n\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[next/restart/stop]"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Player: p2"))
+	testing_utils.AssertEQSlice(t, []int{1, 0}, stb.stats.shuts)
+
+	// Restart from player 1 with an open box and the counters reset
+	stb = won()
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nRESTART\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	_, after, _ := strings.Cut(output, "[next/restart/stop]")
+	testing_utils.AssertEQb(t, true, strings.Contains(after, "Player: p1\n\n"+openBox))
+	testing_utils.AssertEQb(t, false, strings.Contains(after, "Player: p2"))
+	testing_utils.AssertEQi(t, 1, stb.stats.rolls)
+	testing_utils.AssertEQSlice(t, []int{0, 0}, stb.stats.shuts)
+
+	// Change to the players given by the setup, only offered with one
+	stb = won()
+	stb.SetPlayerSetup(func(stdin io.Reader) (bool, []string, []bool) {
+		return false, []string{"p3"}, []bool{false}
+	})
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nchange\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[next/restart/change/stop]"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Player: p3\n\n"+openBox))
+	testing_utils.AssertEQSlice(t, []int{0}, stb.stats.shuts)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\nShut boxes:\n\np3 : 0\n"))
+
+	// A setup that is done ends the game
+	stb = won()
+	stb.SetPlayerSetup(func(stdin io.Reader) (bool, []string, []bool) {
+		return true, nil, nil
+	})
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nchange\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertEQi(t, 1, stb.stats.rolls)
+	testing_utils.AssertEQSlice(t, []int{1, 0}, stb.stats.shuts)
+
+	// Stop ends the game without another roll
+	stb = won()
+	origStdout, ignoreOut = testing_utils.IgnoreStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nstop\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertEQi(t, 1, stb.stats.rolls)
+
+	// Without a setup, change is invalid and prompts again
+	stb = won()
+	origStdout, r, w = testing_utils.RedirectStdout()
+	stb.RunWith(bytes.NewBufferString("9\nok\nchange\nnext\n\n"))
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(
+		output, "input error: expected one of 'next', 'restart', 'stop'\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Player: p2"))
+}
+
+func TestString(t *testing.T) {
+	// The current player and the board, in the game's slot style
+
+	stb := NewShutBox([]string{"p1", "p2"}, SizeBox, DiceAll, NumDice, nil)
+	stb.nextPlayer()
+	stb.gameState = ConvertSlotsToGameState("[1][2][_][4][5][6][7][8][9]", SizeBox)
+	testing_utils.AssertEQ(t, "p2: [1][2][_][4][5][6][7][8][9]", stb.String())
+	testing_utils.AssertEQ(t, "p2: [1][2][_][4][5][6][7][8][9]", fmt.Sprint(stb))
+	testing_utils.AssertEQ(t, "p2: [1][2][_][4][5][6][7][8][9]", fmt.Sprintf("%v", *stb))
+
+	stb.SetSlotStyle(SlotStyle{Open: "(", Close: ")", Empty: " "})
+	testing_utils.AssertEQ(t, "p2: (1)(2)( )(4)(5)(6)(7)(8)(9)", stb.String())
+
+	// Two digit slots are padded like the printed game state
+	stb = NewShutBox([]string{"p1"}, 12, DiceAll, NumDice, nil)
+	stb.gameState = ConvertSlotsToGameState("[ 1][ _][ 3][ 4][ 5][ 6][ 7][ 8][ 9][10][11][12]", 12)
+	testing_utils.AssertEQ(t, "p1: [ 1][ _][ 3][ 4][ 5][ 6][ 7][ 8][ 9][10][11][12]", stb.String())
+
+	// Without players only the board is described
+	testing_utils.AssertEQ(t, "", ShutTheBox{}.String())
+}
+
+func TestHighlightClosedSlots(t *testing.T) {
+	// The slots closed by the last move are colored, p1 closes 9 then
+	// 1+1 is rolled before quitting
+
+	origColor := probgen.UseColor
+	defer func() { probgen.UseColor = origColor }()
+
+	run := func(highlight bool) string {
+		stb := NewShutBox([]string{"p1"}, SizeBox, DiceHybrid, NumDice, fixedRolls(6, 3, 1, 1))
+		stb.SetHighlight(highlight)
+
+		origStdout, r, w := testing_utils.RedirectStdout()
+		stb.RunWith(bytes.NewBufferString("9\nok\n\n"))
+		return testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	}
+
+	probgen.UseColor = true
+	output := run(true)
+	testing_utils.AssertEQb(t, true, strings.Contains(
+		output, "Player: p1\n\n[1][2][3][4][5][6][7][8]"+ClosedColor+"[_]"+probgen.ColorReset+"\n"))
+	// Nothing was closed before the first move
+	testing_utils.AssertEQb(t, true, strings.Contains(
+		output, "Player: p1\n\n[1][2][3][4][5][6][7][8][9]\n"))
+
+	// Plain slots without highlighting, and plain output without color
+	testing_utils.AssertEQb(t, false, strings.Contains(run(false), ClosedColor+"["))
+	probgen.UseColor = false
+	testing_utils.AssertEQ(t, run(false), run(true))
+
+	// Each closed slot is wrapped on its own, others are left plain
+	probgen.UseColor = true
+	gstate := ConvertSlotsToGameState("[_][_][3][4][_][_][_][_][_]", SizeBox)
+	testing_utils.AssertEQ(
+		t,
+		ClosedColor+"[_]"+probgen.ColorReset+ClosedColor+"[_]"+probgen.ColorReset+"[3][4][_][_][_][_][_]",
+		DefaultSlotStyle.HighlightSlots(gstate, SizeBox, 0b11))
+
+	// Undo forgets the slots closed by the move it reverts
+	stb := NewShutBox([]string{"p1"}, SizeBox, DiceAll, NumDice, nil)
+	testing_utils.AssertNIL(t, stb.updateGameState("9", 9))
+	testing_utils.AssertEQi(t, 1<<GetValueSlot(9), stb.lastClosed)
+	testing_utils.AssertNIL(t, stb.undo())
+	testing_utils.AssertEQi(t, 0, stb.lastClosed)
+}
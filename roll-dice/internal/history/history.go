@@ -0,0 +1,164 @@
+/*
+history.go - run history persistence
+
+Stores the results of probability runs as one JSON record
+per line, oldest first, and keeps the file within the
+configured retention limit
+*/
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+/// Constants
+
+const ErrInvalidMaxRecords = "invalid history retention: max records must not be negative"
+
+/// Settings
+
+// Configurable history persistence
+type Settings struct {
+	Path       string // file the history is persisted to
+	MaxRecords int    // number of newest records kept when pruning
+}
+
+// Settings used unless configured otherwise
+var DefaultSettings = Settings{
+	Path:       "roll-dice-history.jsonl",
+	MaxRecords: 1000,
+}
+
+/// Records
+
+// One persisted probability run
+type Record struct {
+	Time      time.Time      `json:"time"`       // when the run completed
+	EventType string         `json:"event_type"` // Ex: "coin", "D6"
+	NumEvents int            `json:"num_events"` // number of events in the run
+	Results   map[string]int `json:"results"`    // count of each outcome
+}
+
+// Persist a completed run to the history file, logging any failure as
+// losing history should not interrupt the run. Compatible with
+// probgen.RecordRun
+//
+//	Params
+//		eventType string   : type of the run. Ex: "coin", "D6"
+//		numEvents int      : number of events in the run
+//		res map[string]int : aggregated results of the run
+func (settings Settings) Record(eventType string, numEvents int, res map[string]int) {
+	record := Record{
+		Time:      time.Now(),
+		EventType: eventType,
+		NumEvents: numEvents,
+		Results:   res,
+	}
+
+	if err := AppendRecord(settings.Path, record); err != nil {
+		log.Printf("failed to record run in history '%s': %v", settings.Path, err)
+	}
+}
+
+// Append a record to the end of the history file, creating it if missing
+//
+//	Params
+//		path string   : history file
+//		record Record : run to persist
+//	Returns
+//		error : any error encountered writing the file
+func AppendRecord(path string, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Read every record in the history file, oldest first. A missing file is
+// an empty history
+//
+//	Params
+//		path string : history file
+//	Returns
+//		[]Record : all persisted records
+//		error    : any error encountered reading or decoding the file
+func ReadHistory(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Record{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Trim the oldest records so that at most maxRecords of the newest remain,
+// in their original order. A missing file or one already within the limit
+// is left untouched
+//
+//	Params
+//		path string    : history file
+//		maxRecords int : number of newest records to keep
+//	Returns
+//		error : any error encountered reading or rewriting the file
+func PruneHistory(path string, maxRecords int) error {
+	if maxRecords < 0 {
+		return errors.New(ErrInvalidMaxRecords)
+	}
+
+	records, err := ReadHistory(path)
+	if err != nil || len(records) <= maxRecords {
+		return err
+	}
+
+	// Rewrite to a temporary file first so a failure never loses history
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, record := range records[len(records)-maxRecords:] {
+		if err := AppendRecord(tmp, record); err != nil {
+			return err
+		}
+	}
+
+	if maxRecords == 0 {
+		// Nothing kept, but the history file still exists
+		if err := os.WriteFile(tmp, nil, 0644); err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,131 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+	"github.com/romansod/roll-dice/internal/testing_utils"
+)
+
+// Write numbered records to a new history file in a temporary directory
+//
+//	Params
+//		t *testing.T : needed for the temporary directory
+//		n int        : number of records, numbered 0 -> n-1 by NumEvents
+//	Returns
+//		string : path to the history file
+func writeRecords(t *testing.T, n int) string {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := 0; i < n; i++ {
+		record := Record{
+			Time:      time.Unix(int64(i), 0).UTC(),
+			EventType: "D6",
+			NumEvents: i,
+			Results:   map[string]int{"1": i}}
+		testing_utils.AssertNIL(t, AppendRecord(path, record))
+	}
+
+	return path
+}
+
+func TestReadHistory(t *testing.T) {
+	// Records are read back oldest first
+
+	path := writeRecords(t, 3)
+	records, err := ReadHistory(path)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 3, len(records))
+	for i, record := range records {
+		testing_utils.AssertEQi(t, i, record.NumEvents)
+		testing_utils.AssertEQi(t, i, record.Results["1"])
+		testing_utils.AssertEQ(t, "D6", record.EventType)
+	}
+
+	// Missing file is an empty history
+	records, err = ReadHistory(filepath.Join(t.TempDir(), "missing.jsonl"))
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 0, len(records))
+}
+
+func TestPruneHistory(t *testing.T) {
+	// Pruning keeps the newest records in order
+
+	path := writeRecords(t, 10)
+	testing_utils.AssertNIL(t, PruneHistory(path, 4))
+
+	records, err := ReadHistory(path)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 4, len(records))
+	for i, record := range records {
+		testing_utils.AssertEQi(t, 6+i, record.NumEvents)
+	}
+
+	// Already under the limit is a no-op
+	before, _ := os.ReadFile(path)
+	testing_utils.AssertNIL(t, PruneHistory(path, 4))
+	testing_utils.AssertNIL(t, PruneHistory(path, 100))
+	after, _ := os.ReadFile(path)
+	testing_utils.AssertEQ(t, string(before), string(after))
+
+	// Missing file is a no-op and is not created
+	missing := filepath.Join(t.TempDir(), "missing.jsonl")
+	testing_utils.AssertNIL(t, PruneHistory(missing, 4))
+	_, err = os.Stat(missing)
+	testing_utils.AssertEQb(t, true, os.IsNotExist(err))
+
+	// Keeping nothing empties the file
+	testing_utils.AssertNIL(t, PruneHistory(path, 0))
+	records, err = ReadHistory(path)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 0, len(records))
+
+	// Negative limits are rejected
+	testing_utils.AssertEQ(t, ErrInvalidMaxRecords, PruneHistory(path, -1).Error())
+}
+
+func TestExportLifetimeStats(t *testing.T) {
+	// Persisted runs are aggregated into a single JSON document
+
+	origSettings := DefaultSettings
+	DefaultSettings.Path = filepath.Join(t.TempDir(), "history.jsonl")
+
+	// Empty history is still a valid document
+	var out bytes.Buffer
+	testing_utils.AssertNIL(t, ExportLifetimeStats(&out))
+	var stats LifetimeStats
+	testing_utils.AssertNIL(t, json.Unmarshal(out.Bytes(), &stats))
+	testing_utils.AssertEQi(t, 0, stats.TotalRuns)
+	testing_utils.AssertEQi(t, 0, stats.Coins.TotalFlips)
+	testing_utils.AssertEQi(t, 0, len(stats.Dice))
+
+	DefaultSettings.Record(probgen.CoinEventType, 10, map[string]int{probgen.Heads: 4, probgen.Tails: 6})
+	DefaultSettings.Record(probgen.CoinEventType, 5, map[string]int{probgen.Heads: 3, probgen.Tails: 2})
+	DefaultSettings.Record(probgen.DiceEventType(probgen.D4), 3, map[string]int{"1": 2, "4": 1})
+	DefaultSettings.Record(probgen.DiceEventType(probgen.D4), 2, map[string]int{"1": 1, "2": 1})
+	DefaultSettings.Record(probgen.DiceEventType(probgen.D6), 1, map[string]int{"6": 1})
+
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportLifetimeStats(&out))
+	stats = LifetimeStats{}
+	testing_utils.AssertNIL(t, json.Unmarshal(out.Bytes(), &stats))
+
+	testing_utils.AssertEQi(t, 5, stats.TotalRuns)
+	testing_utils.AssertEQi(t, 15, stats.Coins.TotalFlips)
+	testing_utils.AssertEQi(t, 7, stats.Coins.Heads)
+	testing_utils.AssertEQi(t, 8, stats.Coins.Tails)
+
+	testing_utils.AssertEQi(t, 2, len(stats.Dice))
+	testing_utils.AssertEQi(t, 5, stats.Dice["D4"].TotalRolls)
+	testing_utils.AssertEQi(t, 3, stats.Dice["D4"].Distribution["1"])
+	testing_utils.AssertEQi(t, 1, stats.Dice["D4"].Distribution["2"])
+	testing_utils.AssertEQi(t, 1, stats.Dice["D4"].Distribution["4"])
+	testing_utils.AssertEQi(t, 1, stats.Dice["D6"].TotalRolls)
+	testing_utils.AssertEQi(t, 1, stats.Dice["D6"].Distribution["6"])
+
+	DefaultSettings = origSettings
+}
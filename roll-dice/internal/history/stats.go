@@ -0,0 +1,91 @@
+/*
+stats.go - lifetime statistics
+
+Aggregates every persisted run of the history
+into lifetime totals and renders them as JSON
+*/
+package history
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+)
+
+// Lifetime totals of all coin flip runs
+type CoinStats struct {
+	TotalFlips int `json:"total_flips"`
+	Heads      int `json:"heads"`
+	Tails      int `json:"tails"`
+}
+
+// Lifetime totals of all runs of one dice type
+type DiceStats struct {
+	TotalRolls   int            `json:"total_rolls"`
+	Distribution map[string]int `json:"distribution"` // count of each face
+}
+
+// Lifetime totals of every persisted run
+type LifetimeStats struct {
+	TotalRuns int                  `json:"total_runs"`
+	Coins     CoinStats            `json:"coins"`
+	Dice      map[string]DiceStats `json:"dice"` // indexed by dice type. Ex: "D6"
+}
+
+// Aggregate the records into lifetime totals. Records of other event types
+// only count towards the total number of runs
+//
+//	Params
+//		records []Record : persisted runs
+//	Returns
+//		LifetimeStats : aggregated totals
+func AggregateStats(records []Record) LifetimeStats {
+	stats := LifetimeStats{Dice: make(map[string]DiceStats)}
+
+	for _, record := range records {
+		stats.TotalRuns++
+
+		switch {
+		case record.EventType == probgen.CoinEventType:
+			stats.Coins.TotalFlips += record.NumEvents
+			stats.Coins.Heads += record.Results[probgen.Heads]
+			stats.Coins.Tails += record.Results[probgen.Tails]
+		case strings.HasPrefix(record.EventType, "D"):
+			dice, exists := stats.Dice[record.EventType]
+			if !exists {
+				dice.Distribution = make(map[string]int)
+			}
+
+			dice.TotalRolls += record.NumEvents
+			for face, count := range record.Results {
+				dice.Distribution[face] += count
+			}
+
+			stats.Dice[record.EventType] = dice
+		}
+	}
+
+	return stats
+}
+
+// Write the lifetime totals of every run persisted in the history file of
+// the DefaultSettings as a JSON document. An empty history is written as a
+// valid document with zero totals
+//
+//	Params
+//		w io.Writer : destination of the JSON document
+//	Returns
+//		error : any error encountered reading the history or writing
+func ExportLifetimeStats(w io.Writer) error {
+	records, err := ReadHistory(DefaultSettings.Path)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(AggregateStats(records))
+}
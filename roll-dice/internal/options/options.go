@@ -11,10 +11,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/romansod/roll-dice/internal/games"
+	"github.com/romansod/roll-dice/internal/history"
 	"github.com/romansod/roll-dice/internal/probgen"
 	"github.com/romansod/roll-dice/internal/utilities"
 )
@@ -24,57 +30,249 @@ import (
 const ErrUnsupported = "unsupported option"
 const ErrNotImplemented = "not yet implemented"
 
-const SyntaxErrExpectedInt = "syntax error: expected integer"
+const SyntaxErrExpectedInt = utilities.ErrExpectedInt
+const SyntaxErrExpectedFloat = "syntax error: expected number"
+
+const ErrRecoveredPanic = "operation '%s' failed unexpectedly: %v"
+const ErrDuplicateOption = "option '%s' is already registered"
+const ErrUnregisteredOption = "option %d is not registered"
+const ErrUnknownOperation = "unknown operation '%s': expected '" + OpFlip + "' or '" + OpRoll + "'"
+
+const ErrNegativeAI = "invalid number of AI opponents: must not be negative"
+const ErrTooManyPlayers = "invalid number of players: must have at most %d players, see MaxPlayers"
+const ErrEmptyName = "invalid player name: must not be empty"
+const ErrDuplicateName = "invalid player name: '%s' is already taken"
+const ErrNegativeRounds = "invalid number of rounds: must not be negative"
+const ErrNoGameRecorded = "no Shut the Box game played yet this session"
+
+/// Operations run without the menu, see RunOperation
+
+const OpFlip = "flip"
+const OpRoll = "roll"
 
 /// Option Types
 
 const (
-	exit       = iota
-	flip_coins = iota
-	roll_dice  = iota
-	shutthebox = iota
+	exit        = iota
+	flip_coins  = iota
+	roll_dice   = iota
+	shutthebox  = iota
+	convergence = iota
+	custom_dice = iota
+	lifetime    = iota
+	sum_dice    = iota
+	roll_until  = iota
+	advantage   = iota
+	session_log = iota
+	help        = iota
+	sequence    = iota
+	dice_pool   = iota
+	min_max     = iota
+	dc_check    = iota
+	at_least    = iota
+	dice_jack   = iota
+	dice_types  = iota
+	mixed_pool  = iota
+	cumulative  = iota
+	exact_heads = iota
+	successes   = iota
+	weighted    = iota
+	practice    = iota
+	replay      = iota
 )
 
 /// Collection of Options
 
+// Whether the menu describes each option under its name
+var VerboseMenu = false
+
+// Whether Shut the Box prints each die and the move made every roll
+var VerboseShutTheBox = false
+
+// Whether Shut the Box times every move and prints each player's total
+var TimedShutTheBox = false
+
+// Whether Shut the Box colors the slots closed by the last move
+var HighlightShutTheBox = false
+
+// Default largest number of players, including AI opponents, in a game
+const DefaultMaxPlayers = 12
+
+// Largest number of players, including AI opponents, in a game
+var MaxPlayers = DefaultMaxPlayers
+
 type Options struct {
-	opts map[int]Opt // Map of menu options to Opt
+	opts     map[int]Opt       // Map of menu options to Opt
+	session  *SessionLog       // History of the runs performed this session
+	lastGame *games.GameRecord // Last Shut the Box game played this session
+	verbose  bool              // Print each option's description under it
 }
 
-// Print the menu options
+// Print the menu options, with their descriptions if verbose
 func (options Options) displayOptions() {
-	fmt.Print("\n\nPlease enter the option number\n\nRegistered Options:\n\n")
-	for i := 0; i < len(options.opts); i++ {
+	fmt.Print("\n\nPlease enter the option number or name\n\nRegistered Options:\n\n")
+	for _, i := range sortedOptNums(options.opts) {
 		v := options.opts[i]
-		fmt.Printf("\t%d) %s\n", v.getOptNum(), v.getName())
+		fmt.Printf("\t%d) %s\n", i, v.getName())
+		if options.verbose {
+			fmt.Printf("\t\t%s\n", v.getDescription())
+		}
 	}
 }
 
-// Register all Options
-//
-//	Example:
-//		opts[optNum] = Opt{name: _, optNum: _}
+// Register all Options, numbered in the order of the option types
 func (options *Options) registerOptions() {
 	options.opts = make(map[int]Opt)
-	options.opts[exit] = OptExit{name: "Exit", optNum: exit}
-	options.opts[flip_coins] = OptFlipCoins{name: "Flip Coins", optNum: flip_coins}
-	options.opts[roll_dice] = OptRollDice{name: "Roll Dice", optNum: roll_dice}
-	options.opts[shutthebox] = OptShutTheBox{name: "Shut the Box", optNum: shutthebox}
+	options.session = &SessionLog{}
+	session := options.session
+	options.lastGame = &games.GameRecord{}
+	builtins := []Opt{
+		OptExit{name: "Exit", optNum: exit},
+		OptFlipCoins{name: "Flip Coins", optNum: flip_coins, session: session},
+		OptRollDice{name: "Roll Dice", optNum: roll_dice, session: session},
+		OptShutTheBox{name: "Shut the Box", optNum: shutthebox, session: session, lastGame: options.lastGame},
+		OptConvergence{name: "Coin Convergence", optNum: convergence, session: session},
+		OptCustomDice{name: "Roll Custom Dice", optNum: custom_dice, session: session},
+		OptLifetimeStats{name: "Lifetime Stats", optNum: lifetime},
+		OptSumDice{name: "Roll Dice Sum", optNum: sum_dice, session: session},
+		OptRollUntil{name: "Roll Until", optNum: roll_until, session: session},
+		OptAdvantage{name: "D20 Advantage", optNum: advantage, session: session},
+		OptHistory{name: "History", optNum: session_log, session: session},
+		OptHelp{name: "Help", optNum: help, opts: options.opts},
+		OptRollSequence{name: "Roll Sequence", optNum: sequence, session: session},
+		OptDicePool{name: "Dice Pool", optNum: dice_pool, session: session},
+		OptMinMax{name: "Roll Min Max", optNum: min_max, session: session},
+		OptCheck{name: "DC Check", optNum: dc_check, session: session},
+		OptAtLeastOne{name: "At Least One", optNum: at_least, session: session},
+		OptDiceJack{name: "Dice Jack", optNum: dice_jack, session: session},
+		OptDiceTypes{name: "Dice Types", optNum: dice_types},
+		OptMixedPool{name: "Mixed Dice", optNum: mixed_pool, session: session},
+		OptCumulative{name: "Cumulative Results", optNum: cumulative, session: session},
+		OptExactHeads{name: "Exact Heads", optNum: exact_heads, session: session},
+		OptSuccesses{name: "Success Pool", optNum: successes, session: session},
+		OptWeightedConvergence{name: "Weighted Convergence", optNum: weighted, session: session},
+		OptPractice{name: "Practice Target", optNum: practice},
+		OptReplay{name: "Replay Game", optNum: replay, lastGame: options.lastGame},
+	}
+
+	for _, opt_t := range builtins {
+		// Built-in names are unique, registration cannot fail
+		options.Register(opt_t)
+	}
+}
+
+// Add an Opt to the menu under the next available opt number. The menu,
+// help and runOption all use the number assigned here
+//
+//	Example:
+//		options.Register(OptCustom{name: "Custom Game"})
+//
+//	Params
+//		opt Opt : the option to add
+//	Returns
+//		error : ErrDuplicateOption if an Opt with the same name is registered
+func (options *Options) Register(opt Opt) error {
+	if _, exists := options.lookupOption(opt.getName()); exists {
+		return fmt.Errorf(ErrDuplicateOption, opt.getName())
+	}
+
+	// The next number is past the highest, so gaps are never reused
+	optNum := 0
+	if optNums := sortedOptNums(options.opts); len(optNums) > 0 {
+		optNum = optNums[len(optNums)-1] + 1
+	}
+	options.opts[optNum] = opt
+
+	return nil
+}
+
+// Remove an Opt from the menu. It is no longer displayed, described by help
+// or runnable, and its number is not reused
+//
+//	Params
+//		optNum int : the opt number of the option to remove. Ex: 2
+//	Returns
+//		error : ErrUnregisteredOption if no Opt has the number
+func (options *Options) Unregister(optNum int) error {
+	if _, exists := options.opts[optNum]; !exists {
+		return fmt.Errorf(ErrUnregisteredOption, optNum)
+	}
+
+	delete(options.opts, optNum)
+
+	return nil
+}
+
+// List the opt numbers of the registered Opts in menu order. Numbers need not
+// be contiguous, ex: after an Opt is removed
+//
+//	Params
+//		opts map[int]Opt : registered options
+//	Returns
+//		[]int : the opt numbers in ascending order
+func sortedOptNums(opts map[int]Opt) []int {
+	optNums := make([]int, 0, len(opts))
+	for optNum := range opts {
+		optNums = append(optNums, optNum)
+	}
+	slices.Sort(optNums)
+
+	return optNums
+}
+
+// Find the opt number of the Opt with the given name, ignoring case and
+// surrounding whitespace
+//
+//	Params
+//		name string : option name. Ex: "flip coins"
+//	Returns
+//		int  : the opt number, -1 if not found
+//		bool : true if an Opt has the name
+func (options Options) lookupOption(name string) (int, bool) {
+	name = strings.TrimSpace(name)
+	for optNum, opt_t := range options.opts {
+		if strings.EqualFold(opt_t.getName(), name) {
+			return optNum, true
+		}
+	}
+
+	return -1, false
+}
+
+// Convert menu input to an opt number. Numbers are taken as is, anything
+// else is looked up by option name
+//
+//	Params
+//		input string : menu input. Ex: "1" or "Flip Coins"
+//	Returns
+//		int   : the opt number
+//		error : ErrUnsupported if input is neither a number nor an option name
+func (options Options) parseOption(input string) (int, error) {
+	if opt, err := strconv.Atoi(input); err == nil {
+		return opt, nil
+	}
+
+	if opt, exists := options.lookupOption(input); exists {
+		return opt, nil
+	}
+
+	return -1, errors.New(ErrUnsupported)
 }
 
 // Run the given Opt based on the opt number provided
 //
 //	Params
-//		opt int : the menu option dictating which Opt is run
+//		stdin io.Reader : holds user input
+//		opt int         : the menu option dictating which Opt is run
 //	Returns
 //		bool  : true if user indicates they are done
 //		error : any error encountered
-func (options Options) runOption(opt int) (bool, error) {
+func (options Options) runOption(stdin io.Reader, opt int) (bool, error) {
 	done, err := false, errors.New(ErrUnsupported)
 	opt_t, exists := options.opts[opt]
 	if exists {
 		for !done {
-			done, err = opt_t.process()
+			done, err = processRecover(opt_t, stdin)
 
 			if err != nil {
 				// Give feedback on any errors before next prompt
@@ -82,6 +280,11 @@ func (options Options) runOption(opt int) (bool, error) {
 			}
 
 			fmt.Print("\n\n")
+
+			// Exit is confirmed once, a cancelled exit returns to the menu
+			if opt == exit {
+				break
+			}
 		}
 
 		fmt.Print("Returning to main menu ...\n")
@@ -92,12 +295,35 @@ func (options Options) runOption(opt int) (bool, error) {
 	return done, err
 }
 
+// Run the process of the given Opt, recovering from any panic raised by it
+// so that a bug in one operation returns control to the main menu instead of
+// terminating the whole program
+//
+//	Params
+//		opt_t Opt       : the Opt to process
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool  : true if user indicates they are done, or a panic was recovered
+//		error : any error encountered, including the recovered panic
+func processRecover(opt_t Opt, stdin io.Reader) (done bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			// Log the stack for diagnosing the bug and fall back to the menu
+			log.Printf("recovered panic in '%s':\n%s", opt_t.getName(), debug.Stack())
+			done, err = true, fmt.Errorf(ErrRecoveredPanic, opt_t.getName(), r)
+		}
+	}()
+
+	return opt_t.process(stdin)
+}
+
 /// - Base Opt type
 
 type Opt interface {
-	process() (bool, error) // Setup and execute operation
-	getName() string        // Retrieve the name of the operation
-	getOptNum() int         // Get the opt number
+	process(stdin io.Reader) (bool, error) // Setup and execute operation
+	getName() string                       // Retrieve the name of the operation
+	getOptNum() int                        // Get the opt number
+	getDescription() string                // Explain the operation, its inputs and outputs
 }
 
 /// - 0) Exit
@@ -107,9 +333,13 @@ type OptExit struct {
 	optNum int
 }
 
-func (optExit OptExit) process() (bool, error) {
-	// Simply exit gracefully
+func (optExit OptExit) process(stdin io.Reader) (bool, error) {
+	// A mistyped option should not end the program
+	if done, yes := utilities.PromptYesNo(stdin, "Are you sure you want to exit?"); done || !yes {
+		return false, nil
+	}
 
+	// Exit gracefully
 	fmt.Print("Exiting now ")
 	for i := 0; i < 3; i++ {
 		time.Sleep(500 * time.Millisecond)
@@ -128,18 +358,23 @@ func (optExit OptExit) getOptNum() int {
 	return optExit.optNum
 }
 
+func (optExit OptExit) getDescription() string {
+	return "Exit the program."
+}
+
 /// - 1) Flip Coins
 
 type OptFlipCoins struct {
-	name   string
-	optNum int
+	name    string
+	optNum  int
+	session *SessionLog
 }
 
-func (optFlipCoins OptFlipCoins) process() (bool, error) {
+func (optFlipCoins OptFlipCoins) process(stdin io.Reader) (bool, error) {
 	// Prompt user for the number of coin flips they want to do
 
 	fmt.Print("Please enter the number of coin flips:\n")
-	done, input, err := utilities.ProcessInputInt(os.Stdin)
+	done, input, err := utilities.ProcessInputInt(stdin)
 
 	if done {
 		return true, err
@@ -149,9 +384,18 @@ func (optFlipCoins OptFlipCoins) process() (bool, error) {
 		return false, errors.New(SyntaxErrExpectedInt)
 	}
 
-	coinFlip := probgen.NewCoinFlip(input)
+	res, err := flipCoins(input)
+	if err != nil {
+		return false, err
+	}
+
+	optFlipCoins.session.add(
+		optFlipCoins.name,
+		fmt.Sprintf("flips=%d", input),
+		summarizeResults(res))
+	optFlipCoins.session.accumulate(optFlipCoins.name, "", res)
 
-	return false, probgen.ValidateAndExecute(coinFlip)
+	return promptExportCSV(stdin, res)
 }
 
 func (optFlipCoins OptFlipCoins) getName() string {
@@ -162,17 +406,22 @@ func (optFlipCoins OptFlipCoins) getOptNum() int {
 	return optFlipCoins.optNum
 }
 
+func (optFlipCoins OptFlipCoins) getDescription() string {
+	return "Flip a fair coin a given number of times and show how many flips came up Heads and Tails, with an optional CSV export of the results."
+}
+
 /// - 2) Roll Dice
 
 type OptRollDice struct {
-	name   string
-	optNum int
+	name    string
+	optNum  int
+	session *SessionLog
 }
 
-func (optRollDice OptRollDice) process() (bool, error) {
+func (optRollDice OptRollDice) process(stdin io.Reader) (bool, error) {
 	// Prompt the user for the number of sides on the dice
 	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
-	done, sides, err := utilities.ProcessInputInt(os.Stdin)
+	done, sides, err := utilities.ProcessInputInt(stdin)
 	if done {
 		return true, err
 	}
@@ -183,7 +432,7 @@ func (optRollDice OptRollDice) process() (bool, error) {
 
 	// Prompt the user for the number of rolls for the dice
 	fmt.Print("Please enter the number of dice rolls:\n")
-	done, rolls, err := utilities.ProcessInputInt(os.Stdin)
+	done, rolls, err := utilities.ProcessInputInt(stdin)
 	if done {
 		return true, err
 	}
@@ -192,9 +441,18 @@ func (optRollDice OptRollDice) process() (bool, error) {
 		return false, errors.New(SyntaxErrExpectedInt)
 	}
 
-	diceRoll := probgen.NewDiceRoll(rolls, sides)
+	res, err := rollDice(sides, rolls)
+	if err != nil {
+		return false, err
+	}
+
+	optRollDice.session.add(
+		optRollDice.name,
+		fmt.Sprintf("sides=%d, rolls=%d", sides, rolls),
+		summarizeResults(res))
+	optRollDice.session.accumulate(optRollDice.name, fmt.Sprintf("sides=%d", sides), res)
 
-	return false, probgen.ValidateAndExecute(diceRoll)
+	return promptExportCSV(stdin, res)
 }
 
 func (optRollDice OptRollDice) getName() string {
@@ -205,25 +463,117 @@ func (optRollDice OptRollDice) getOptNum() int {
 	return optRollDice.optNum
 }
 
-/// - 3) Roll Dice
+func (optRollDice OptRollDice) getDescription() string {
+	return "Roll a dice with a given number of sides a given number of times and show how often each face came up, with an optional CSV export of the results."
+}
+
+/// - 3) Shut the Box
 
 type OptShutTheBox struct {
-	name   string
-	optNum int
+	name     string
+	optNum   int
+	session  *SessionLog
+	lastGame *games.GameRecord
 }
 
-func (optShutTheBox OptShutTheBox) process() (bool, error) {
-	done, players, err := getPlayers(os.Stdin)
+func (optShutTheBox OptShutTheBox) process(stdin io.Reader) (bool, error) {
+	done, players, err := getPlayers(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	done, players, ai, err := getAIPlayers(stdin, players)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	done, boxSize, err := getBoxSize(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	done, numDice, err := getNumDice(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	// House rules may always roll all the dice or one die
+	fmt.Print("Please select the dice mode: all dice, one die, or one die once the high slots are shut [a/1/h]:\n")
+	done, mode := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
+	}
+
+	diceMode, err := games.ParseDiceMode(mode)
+	if err != nil {
+		return false, err
+	}
+
+	// Players competing on identical luck share a challenge seed
+	fmt.Print("Please enter a challenge seed, or 0 for random rolls:\n")
+	done, seed, err := utilities.ProcessInputInt(stdin)
 	if done {
 		return true, err
 	}
 
 	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// A match plays a fixed number of rounds
+	fmt.Print("Please enter the number of match rounds, or 0 to play freely:\n")
+	done, rounds, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if rounds < 0 {
+		return false, errors.New(ErrNegativeRounds)
+	}
+
+	shutTheBox := games.NewShutBox(players, boxSize, diceMode, numDice, nil)
+	if err := shutTheBox.SetAI(ai); err != nil {
 		return false, err
 	}
+	shutTheBox.SetPlayerSetup(setupPlayers)
+	shutTheBox.SetRounds(rounds)
+	shutTheBox.SetVerbose(VerboseShutTheBox)
+	shutTheBox.SetHighlight(HighlightShutTheBox)
+	if TimedShutTheBox {
+		shutTheBox.SetClock(time.Now)
+	}
+	if seed != 0 {
+		shutTheBox.SetChallengeSeed(int64(seed))
+	}
+
+	// Kept for Replay Game
+	record := shutTheBox.RecordGame(stdin)
+	*optShutTheBox.lastGame = record
 
-	shutTheBox := games.NewShutBox(players)
-	shutTheBox.Run()
+	optShutTheBox.session.add(
+		optShutTheBox.name,
+		fmt.Sprintf("players=%s, box=%d, dice=%d", strings.Join(record.Players, " "), boxSize, numDice),
+		fmt.Sprintf("dice rolled=%d", len(record.Rolls)))
 
 	return true, nil
 }
@@ -236,60 +586,1495 @@ func (optShutTheBox OptShutTheBox) getOptNum() int {
 	return optShutTheBox.optNum
 }
 
-func getPlayers(stdin io.Reader) (bool, []string, error) {
-	// Prompt the user for the number of sides on the dice
-	fmt.Print("Please indicate the number of players:\n")
-	done, players_n, err := utilities.ProcessInputInt(stdin)
+func (optShutTheBox OptShutTheBox) getDescription() string {
+	return "Play Shut the Box. Enter the players, any AI opponents, the box size, the dice mode, a challenge seed and the number of match rounds, then close slots adding up to each roll of the dice. The lowest total of open slots wins."
+}
+
+/// - 4) Coin Convergence
+
+type OptConvergence struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optConvergence OptConvergence) process(stdin io.Reader) (bool, error) {
+	// Prompt user for the number of coin flips they want to do
+
+	fmt.Print("Please enter the number of coin flips:\n")
+	done, input, err := utilities.ProcessInputInt(stdin)
+
 	if done {
-		return true, nil, err
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
 	}
 
+	heads, err := probgen.ExecuteConvergence(input)
 	if err != nil {
-		return false, nil, errors.New(SyntaxErrExpectedInt)
+		return false, err
 	}
 
-	players := make([]string, players_n)
+	optConvergence.session.add(
+		optConvergence.name,
+		fmt.Sprintf("flips=%d", input),
+		fmt.Sprintf("heads=%s", probgen.FormatPercent(heads)))
 
-	for i := 0; i < players_n; i++ {
-		// Prompt the user for the number of rolls for the dice
-		fmt.Printf("Please enter player %d's name:\n", i+1)
-		done, player := utilities.ProcessInputStr(stdin)
-		if done {
-			return true, nil, nil
-		}
+	return false, nil
+}
+
+func (optConvergence OptConvergence) getName() string {
+	return optConvergence.name
+}
+
+func (optConvergence OptConvergence) getOptNum() int {
+	return optConvergence.optNum
+}
+
+func (optConvergence OptConvergence) getDescription() string {
+	return "Flip a coin a given number of times and show the percentage of Heads at checkpoints along the way, converging towards 50%."
+}
+
+/// - 5) Roll Custom Dice
+
+type OptCustomDice struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
 
-		players[i] = player
+func (optCustomDice OptCustomDice) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the faces of the custom dice
+	fmt.Print("Please enter the dice faces separated by commas (Ex: +,-,0):\n")
+	done, faces := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
 	}
 
-	return false, players, nil
+	// Prompt the user for the number of rolls for the dice
+	fmt.Print("Please enter the number of dice rolls:\n")
+	done, rolls, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	customDiceRoll := probgen.NewCustomDiceRoll(rolls, splitFaces(faces))
+	res, err := probgen.ValidateAndExecuteResults(customDiceRoll)
+	if err != nil {
+		return false, err
+	}
+
+	optCustomDice.session.add(
+		optCustomDice.name,
+		fmt.Sprintf("faces=%s, rolls=%d", faces, rolls),
+		summarizeResults(res))
+
+	return false, nil
 }
 
-// Main driving function. Will continue to prompt user for input
-// until failure or user asks to exit
-func Menu() {
-	done, input, err := false, -1, error(nil)
+func (optCustomDice OptCustomDice) getName() string {
+	return optCustomDice.name
+}
 
-	menu_options := Options{}
-	menu_options.registerOptions()
+func (optCustomDice OptCustomDice) getOptNum() int {
+	return optCustomDice.optNum
+}
 
-	// Consumer user input until user is done and indicates exit
-	// through the Exit option
-	for !done {
-		// User input for menu option parsed
-		menu_options.displayOptions()
-		// Errors from processing options fall back to the
-		// main menu to here where user is prompted again
-		_, input, err = utilities.ProcessInputInt(os.Stdin)
+func (optCustomDice OptCustomDice) getDescription() string {
+	return "Roll a dice with custom faces, entered separated by commas, a given number of times and show how often each face came up."
+}
 
-		if err != nil {
-			fmt.Print(SyntaxErrExpectedInt)
-			continue
-		}
+/// - 6) Lifetime Stats
 
-		// For this iteration, we will run the selected option
-		done, err = menu_options.runOption(input)
-		if err != nil {
-			fmt.Print(err)
+type OptLifetimeStats struct {
+	name   string
+	optNum int
+}
+
+func (optLifetimeStats OptLifetimeStats) process(stdin io.Reader) (bool, error) {
+	// Print the aggregated history as JSON, nothing to prompt for
+	return true, history.ExportLifetimeStats(probgen.Output)
+}
+
+func (optLifetimeStats OptLifetimeStats) getName() string {
+	return optLifetimeStats.name
+}
+
+func (optLifetimeStats OptLifetimeStats) getOptNum() int {
+	return optLifetimeStats.optNum
+}
+
+func (optLifetimeStats OptLifetimeStats) getDescription() string {
+	return "Print the statistics aggregated over every recorded run as JSON."
+}
+
+/// - 7) Roll Dice Sum
+
+type OptSumDice struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optSumDice OptSumDice) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of dice summed per roll
+	fmt.Print("Please enter the number of dice to sum:\n")
+	done, dice, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of rolls for the dice
+	fmt.Print("Please enter the number of dice rolls:\n")
+	done, rolls, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	sumDiceRoll := probgen.NewSumDiceRoll(rolls, dice, sides)
+	res, err := probgen.ValidateAndExecuteResults(sumDiceRoll)
+	if err != nil {
+		return false, err
+	}
+
+	optSumDice.session.add(
+		optSumDice.name,
+		fmt.Sprintf("sides=%d, dice=%d, rolls=%d", sides, dice, rolls),
+		summarizeResults(res))
+
+	return false, nil
+}
+
+func (optSumDice OptSumDice) getName() string {
+	return optSumDice.name
+}
+
+func (optSumDice OptSumDice) getOptNum() int {
+	return optSumDice.optNum
+}
+
+func (optSumDice OptSumDice) getDescription() string {
+	return "Roll several dice with a given number of sides a given number of times and show how often each sum came up."
+}
+
+/// - 8) Roll Until
+
+type OptRollUntil struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optRollUntil OptRollUntil) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the face to roll until
+	fmt.Print("Please enter the target face:\n")
+	done, face, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the most rolls to attempt
+	fmt.Print("Please enter the maximum number of dice rolls:\n")
+	done, maxRolls, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	rolls, hit, err := probgen.ExecuteRollUntil(sides, face, maxRolls)
+	if err != nil {
+		return false, err
+	}
+
+	optRollUntil.session.add(
+		optRollUntil.name,
+		fmt.Sprintf("sides=%d, face=%d, max=%d", sides, face, maxRolls),
+		fmt.Sprintf("rolls=%d, hit=%t", rolls, hit))
+
+	return false, nil
+}
+
+func (optRollUntil OptRollUntil) getName() string {
+	return optRollUntil.name
+}
+
+func (optRollUntil OptRollUntil) getOptNum() int {
+	return optRollUntil.optNum
+}
+
+func (optRollUntil OptRollUntil) getDescription() string {
+	return "Roll a dice with a given number of sides until a target face comes up, giving up after a maximum number of rolls, and show how many rolls it took."
+}
+
+/// - 9) D20 Advantage
+
+type OptAdvantage struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optAdvantage OptAdvantage) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for which of the two rolls to keep
+	fmt.Print("Please select advantage or disadvantage [a/d]:\n")
+	done, input := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
+	}
+
+	if input != "a" && input != "d" {
+		return false, probgen.ErrInvalidAdvantage
+	}
+
+	roll1, roll2, kept := probgen.ExecuteAdvantageRoll(input == "a")
+
+	optAdvantage.session.add(
+		optAdvantage.name,
+		fmt.Sprintf("mode=%s", input),
+		fmt.Sprintf("rolls=%d %d, kept=%d", roll1, roll2, kept))
+
+	return false, nil
+}
+
+func (optAdvantage OptAdvantage) getName() string {
+	return optAdvantage.name
+}
+
+func (optAdvantage OptAdvantage) getOptNum() int {
+	return optAdvantage.optNum
+}
+
+func (optAdvantage OptAdvantage) getDescription() string {
+	return "Roll two D20 and keep the higher roll with advantage, or the lower roll with disadvantage."
+}
+
+/// - 10) History
+
+type OptHistory struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optHistory OptHistory) process(stdin io.Reader) (bool, error) {
+	// Print every run so far, nothing to prompt for
+	optHistory.session.display()
+
+	return true, nil
+}
+
+func (optHistory OptHistory) getName() string {
+	return optHistory.name
+}
+
+func (optHistory OptHistory) getOptNum() int {
+	return optHistory.optNum
+}
+
+func (optHistory OptHistory) getDescription() string {
+	return "Show every run performed this session with its parameters and a summary of its results."
+}
+
+/// - 11) Help
+
+type OptHelp struct {
+	name   string
+	optNum int
+	opts   map[int]Opt // Registered options to describe
+}
+
+func (optHelp OptHelp) process(stdin io.Reader) (bool, error) {
+	// Describe every option in menu order, nothing to prompt for
+	fmt.Print("\n")
+	for _, i := range sortedOptNums(optHelp.opts) {
+		opt_t := optHelp.opts[i]
+		fmt.Printf("%d) %s\n\t%s\n\n", i, opt_t.getName(), opt_t.getDescription())
+	}
+
+	return true, nil
+}
+
+func (optHelp OptHelp) getName() string {
+	return optHelp.name
+}
+
+func (optHelp OptHelp) getOptNum() int {
+	return optHelp.optNum
+}
+
+func (optHelp OptHelp) getDescription() string {
+	return "Show this description of every option."
+}
+
+/// - 12) Roll Sequence
+
+type OptRollSequence struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optRollSequence OptRollSequence) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of rolls for the dice
+	fmt.Print("Please enter the number of dice rolls:\n")
+	done, rolls, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	sequence, err := probgen.ExecuteRollSequence(sides, rolls, probgen.RandNumGen)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Printf("%s\n\n", probgen.FormatSequence(sequence))
+
+	optRollSequence.session.add(
+		optRollSequence.name,
+		fmt.Sprintf("sides=%d, rolls=%d", sides, rolls),
+		probgen.FormatSequence(sequence))
+
+	return false, nil
+}
+
+func (optRollSequence OptRollSequence) getName() string {
+	return optRollSequence.name
+}
+
+func (optRollSequence OptRollSequence) getOptNum() int {
+	return optRollSequence.optNum
+}
+
+func (optRollSequence OptRollSequence) getDescription() string {
+	return "Roll a dice with a given number of sides a given number of times and show every roll in the order rolled."
+}
+
+/// - 13) Dice Pool
+
+type OptDicePool struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optDicePool OptDicePool) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of dice in the pool
+	fmt.Print("Please enter the number of dice in the pool:\n")
+	done, dice, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for how many of the highest dice to keep
+	fmt.Print("Please enter the number of highest dice to keep:\n")
+	done, keep, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if err := probgen.ValidatePool(dice, sides, keep); err != nil {
+		return false, err
+	}
+
+	kept, dropped, total := probgen.RollPool(dice, sides, keep, probgen.RandNumGen)
+	summary := fmt.Sprintf(
+		"kept=%s, dropped=%s, total=%d",
+		probgen.FormatSequence(kept), probgen.FormatSequence(dropped), total)
+	fmt.Printf(
+		"Kept    : %s\nDropped : %s\nTotal   : %d\n\n",
+		probgen.FormatSequence(kept), probgen.FormatSequence(dropped), total)
+
+	optDicePool.session.add(
+		optDicePool.name,
+		fmt.Sprintf("sides=%d, dice=%d, keep=%d", sides, dice, keep),
+		summary)
+
+	return false, nil
+}
+
+func (optDicePool OptDicePool) getName() string {
+	return optDicePool.name
+}
+
+func (optDicePool OptDicePool) getOptNum() int {
+	return optDicePool.optNum
+}
+
+func (optDicePool OptDicePool) getDescription() string {
+	return "Roll a pool of dice with a given number of sides, keep the given number of highest dice and show the kept dice, the dropped dice and the total kept. Ex: 4 dice keeping 3 is 4d6 drop the lowest."
+}
+
+/// - 14) Roll Min Max
+
+type OptMinMax struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optMinMax OptMinMax) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of rolls for the dice
+	fmt.Print("Please enter the number of dice rolls:\n")
+	done, rolls, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	sequence, err := probgen.ExecuteRollSequence(sides, rolls, probgen.RandNumGen)
+	if err != nil {
+		return false, err
+	}
+
+	summary := formatMinMax(sequence)
+	fmt.Printf("%s\n\n", summary)
+
+	optMinMax.session.add(
+		optMinMax.name,
+		fmt.Sprintf("sides=%d, rolls=%d", sides, rolls),
+		summary)
+
+	return false, nil
+}
+
+func (optMinMax OptMinMax) getName() string {
+	return optMinMax.name
+}
+
+func (optMinMax OptMinMax) getOptNum() int {
+	return optMinMax.optNum
+}
+
+func (optMinMax OptMinMax) getDescription() string {
+	return "Roll a dice with a given number of sides a given number of times and show only the lowest and highest face rolled, with how many times each came up."
+}
+
+/// - 15) DC Check
+
+type OptCheck struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optCheck OptCheck) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if err := probgen.ValidateCheck(sides); err != nil {
+		return false, err
+	}
+
+	// Prompt the user for the modifier added to the roll
+	fmt.Print("Please enter the modifier:\n")
+	done, modifier, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the difficulty class to meet or beat
+	fmt.Print("Please enter the DC:\n")
+	done, dc, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	result := probgen.RollCheck(sides, modifier, dc, probgen.RandNumGen)
+	summary := formatCheck(result, modifier, dc)
+	fmt.Printf("%s\n\n", summary)
+
+	optCheck.session.add(
+		optCheck.name,
+		fmt.Sprintf("sides=%d, modifier=%d, dc=%d", sides, modifier, dc),
+		summary)
+
+	return false, nil
+}
+
+func (optCheck OptCheck) getName() string {
+	return optCheck.name
+}
+
+func (optCheck OptCheck) getOptNum() int {
+	return optCheck.optNum
+}
+
+func (optCheck OptCheck) getDescription() string {
+	return "Roll a dice with a given number of sides, add a modifier and show whether the total meets or beats a difficulty class."
+}
+
+/// - 16) At Least One
+
+type OptAtLeastOne struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optAtLeastOne OptAtLeastOne) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the face hoped for
+	fmt.Print("Please enter the target face:\n")
+	done, face, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of rolls
+	fmt.Print("Please enter the number of dice rolls:\n")
+	done, trials, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if err := probgen.ValidateAtLeastOne(sides, face, trials); err != nil {
+		return false, err
+	}
+
+	percent := probgen.FormatPercent(probgen.ProbAtLeastOne(sides, face, trials) * 100)
+	fmt.Printf("Chance of at least one %d in %d rolls : %s\n\n", face, trials, percent)
+
+	optAtLeastOne.session.add(
+		optAtLeastOne.name,
+		fmt.Sprintf("sides=%d, face=%d, rolls=%d", sides, face, trials),
+		percent)
+
+	return false, nil
+}
+
+func (optAtLeastOne OptAtLeastOne) getName() string {
+	return optAtLeastOne.name
+}
+
+func (optAtLeastOne OptAtLeastOne) getOptNum() int {
+	return optAtLeastOne.optNum
+}
+
+func (optAtLeastOne OptAtLeastOne) getDescription() string {
+	return "Compute the exact chance of a target face coming up at least once in a given number of rolls of a dice with a given number of sides."
+}
+
+/// - 17) Dice Jack
+
+type OptDiceJack struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optDiceJack OptDiceJack) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the total to get close to
+	fmt.Printf("Please enter the target total, ex: %d:\n", games.JackTarget)
+	done, target, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if !games.ValidJackTarget(target, probgen.D6) {
+		return false, fmt.Errorf(games.ErrInvalidJackTarget, probgen.D6+1)
+	}
+
+	diceJack := games.NewDiceJack(target, probgen.D6, nil)
+	diceJack.RunWith(stdin)
+
+	summary := "bust"
+	if distance, ok := diceJack.Distance(); ok {
+		summary = fmt.Sprintf("%d away", distance)
+	}
+	optDiceJack.session.add(optDiceJack.name, fmt.Sprintf("target=%d", target), summary)
+
+	return true, nil
+}
+
+func (optDiceJack OptDiceJack) getName() string {
+	return optDiceJack.name
+}
+
+func (optDiceJack OptDiceJack) getOptNum() int {
+	return optDiceJack.optNum
+}
+
+func (optDiceJack OptDiceJack) getDescription() string {
+	return "Play dice blackjack: keep rolling a D6 and adding it to the total, and stand as close to the target total as possible without going over."
+}
+
+/// - 18) Dice Types
+
+type OptDiceTypes struct {
+	name   string
+	optNum int
+}
+
+func (optDiceTypes OptDiceTypes) process(stdin io.Reader) (bool, error) {
+	// List every supported dice type, nothing to prompt for
+	fmt.Print("\n")
+	for _, diceType := range probgen.ListDiceTypes() {
+		fmt.Printf("%s\n", diceType)
+	}
+
+	return true, nil
+}
+
+func (optDiceTypes OptDiceTypes) getName() string {
+	return optDiceTypes.name
+}
+
+func (optDiceTypes OptDiceTypes) getOptNum() int {
+	return optDiceTypes.optNum
+}
+
+func (optDiceTypes OptDiceTypes) getDescription() string {
+	return "List every supported dice type with the range of values it rolls."
+}
+
+/// - 19) Mixed Dice
+
+type OptMixedPool struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optMixedPool OptMixedPool) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the dice to roll together
+	fmt.Print("Please enter the dice to roll, ex: 1d20+2d6:\n")
+	done, notation := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
+	}
+
+	dice, err := probgen.ParseNotation(notation)
+	if err != nil {
+		return false, err
+	}
+
+	if err := probgen.ValidateMixedPool(dice); err != nil {
+		return false, err
+	}
+
+	results, total := probgen.RollMixedPool(dice, probgen.RandNumGen)
+	rolls := probgen.FormatMixedPool(dice, results)
+	fmt.Printf("Rolls : %s\nTotal : %d\n\n", rolls, total)
+
+	optMixedPool.session.add(
+		optMixedPool.name,
+		fmt.Sprintf("notation=%s", notation),
+		fmt.Sprintf("%s, total=%d", rolls, total))
+
+	return false, nil
+}
+
+func (optMixedPool OptMixedPool) getName() string {
+	return optMixedPool.name
+}
+
+func (optMixedPool OptMixedPool) getOptNum() int {
+	return optMixedPool.optNum
+}
+
+func (optMixedPool OptMixedPool) getDescription() string {
+	return "Roll dice of different types together written in dice notation, such as 1d20+2d6, and show the rolls of each type and their total."
+}
+
+/// - 20) Cumulative Results
+
+type OptCumulative struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optCumulative OptCumulative) process(stdin io.Reader) (bool, error) {
+	// Print the combined results so far, nothing to prompt for
+	optCumulative.session.displayTotals()
+
+	return true, nil
+}
+
+func (optCumulative OptCumulative) getName() string {
+	return optCumulative.name
+}
+
+func (optCumulative OptCumulative) getOptNum() int {
+	return optCumulative.optNum
+}
+
+func (optCumulative OptCumulative) getDescription() string {
+	return "Show the results of every coin flip and dice roll run this session added together, grouped by dice type, to check fairness over time."
+}
+
+/// - 21) Exact Heads
+
+type OptExactHeads struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optExactHeads OptExactHeads) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of coin flips
+	fmt.Print("Please enter the number of coin flips:\n")
+	done, flips, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of heads hoped for
+	fmt.Print("Please enter the number of heads:\n")
+	done, heads, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if err := probgen.ValidateExactHeads(flips, heads); err != nil {
+		return false, err
+	}
+
+	percent := probgen.FormatPercent(probgen.ProbExactHeads(flips, heads) * 100)
+	fmt.Printf("Chance of exactly %d heads in %d flips : %s\n\n", heads, flips, percent)
+
+	optExactHeads.session.add(
+		optExactHeads.name,
+		fmt.Sprintf("flips=%d, heads=%d", flips, heads),
+		percent)
+
+	return false, nil
+}
+
+func (optExactHeads OptExactHeads) getName() string {
+	return optExactHeads.name
+}
+
+func (optExactHeads OptExactHeads) getOptNum() int {
+	return optExactHeads.optNum
+}
+
+func (optExactHeads OptExactHeads) getDescription() string {
+	return "Compute the exact chance of a fair coin landing on Heads a given number of times in a given number of flips."
+}
+
+/// - 22) Success Pool
+
+type OptSuccesses struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optSuccesses OptSuccesses) process(stdin io.Reader) (bool, error) {
+	// Prompt the user for the number of dice in the pool
+	fmt.Print("Please enter the number of dice in the pool:\n")
+	done, dice, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the number of sides on the dice
+	fmt.Printf("Please select the number of dice sides %s:\n", probgen.ValidDiceTypes)
+	done, sides, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt the user for the lowest face counted as a success
+	fmt.Print("Please enter the success threshold:\n")
+	done, threshold, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if err := probgen.ValidateSuccesses(dice, sides, threshold); err != nil {
+		return false, err
+	}
+
+	hits, rolls := probgen.CountSuccesses(dice, sides, threshold, probgen.RandNumGen)
+	fmt.Printf("Rolls     : %s\nSuccesses : %d of %d\n\n", probgen.FormatSequence(rolls), hits, dice)
+
+	optSuccesses.session.add(
+		optSuccesses.name,
+		fmt.Sprintf("dice=%d, sides=%d, threshold=%d", dice, sides, threshold),
+		fmt.Sprintf("rolls=%s, successes=%d", probgen.FormatSequence(rolls), hits))
+
+	return false, nil
+}
+
+func (optSuccesses OptSuccesses) getName() string {
+	return optSuccesses.name
+}
+
+func (optSuccesses OptSuccesses) getOptNum() int {
+	return optSuccesses.optNum
+}
+
+func (optSuccesses OptSuccesses) getDescription() string {
+	return "Roll a pool of dice with a given number of sides and count the dice at or above a success threshold, showing every roll."
+}
+
+/// - 23) Weighted Convergence
+
+type OptWeightedConvergence struct {
+	name    string
+	optNum  int
+	session *SessionLog
+}
+
+func (optWeightedConvergence OptWeightedConvergence) process(stdin io.Reader) (bool, error) {
+	// Prompt user for the number of coin flips they want to do
+	fmt.Print("Please enter the number of coin flips:\n")
+	done, flips, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, err
+	}
+
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedInt)
+	}
+
+	// Prompt user for the bias of the coin
+	fmt.Print("Please enter the probability of Heads in [0,1]:\n")
+	done, input := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
+	}
+
+	headsProbability, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil {
+		return false, errors.New(SyntaxErrExpectedFloat)
+	}
+
+	heads, err := probgen.ExecuteBiasedConvergence(flips, headsProbability, probgen.RandNumGen)
+	if err != nil {
+		return false, err
+	}
+
+	optWeightedConvergence.session.add(
+		optWeightedConvergence.name,
+		fmt.Sprintf("flips=%d, heads probability=%g", flips, headsProbability),
+		fmt.Sprintf("heads=%s", probgen.FormatPercent(heads)))
+
+	return false, nil
+}
+
+func (optWeightedConvergence OptWeightedConvergence) getName() string {
+	return optWeightedConvergence.name
+}
+
+func (optWeightedConvergence OptWeightedConvergence) getOptNum() int {
+	return optWeightedConvergence.optNum
+}
+
+func (optWeightedConvergence OptWeightedConvergence) getDescription() string {
+	return "Flip a biased coin a given number of times and show the percentage of Heads at checkpoints along the way, converging towards its probability of Heads rather than 50%."
+}
+
+/// - 24) Practice Target
+
+type OptPractice struct {
+	name   string
+	optNum int
+}
+
+func (optPractice OptPractice) process(stdin io.Reader) (bool, error) {
+	games.DealPractice(games.SizeBox, nil).RunWith(stdin)
+
+	return true, nil
+}
+
+func (optPractice OptPractice) getName() string {
+	return optPractice.name
+}
+
+func (optPractice OptPractice) getOptNum() int {
+	return optPractice.optNum
+}
+
+func (optPractice OptPractice) getDescription() string {
+	return "Practice Shut the Box mental math: enter every combination of open slots adding up to a dealt target, then see how many were found."
+}
+
+/// - 25) Replay Game
+
+type OptReplay struct {
+	name     string
+	optNum   int
+	lastGame *games.GameRecord
+}
+
+func (optReplay OptReplay) process(stdin io.Reader) (bool, error) {
+	if len(optReplay.lastGame.Players) == 0 {
+		return false, errors.New(ErrNoGameRecorded)
+	}
+
+	fmt.Print("Replaying the last Shut the Box game:\n")
+	_, err := games.ReplayGame(*optReplay.lastGame)
+
+	return false, err
+}
+
+func (optReplay OptReplay) getName() string {
+	return optReplay.name
+}
+
+func (optReplay OptReplay) getOptNum() int {
+	return optReplay.optNum
+}
+
+func (optReplay OptReplay) getDescription() string {
+	return "Replay the last Shut the Box game played this session with the same rolls and moves."
+}
+
+// Describe the outcome of a check against a difficulty class, calling out
+// a natural 1 or 20
+//
+//	Ex: roll 20, modifier -5, DC 25 -> "rolled 20 -5 = 15 vs DC 25: failure (natural 20)"
+//
+//	Params
+//		result probgen.CheckResult : outcome of the check
+//		modifier int               : added to the roll
+//		dc int                     : difficulty class of the check
+//	Returns
+//		string : the roll, the total and the outcome
+func formatCheck(result probgen.CheckResult, modifier int, dc int) string {
+	outcome := "failure"
+	if result.Success {
+		outcome = "success"
+	}
+
+	switch {
+	case result.Natural1:
+		outcome += " (natural 1)"
+	case result.Natural20:
+		outcome += " (natural 20)"
+	}
+
+	return fmt.Sprintf("rolled %d %+d = %d vs DC %d: %s", result.Roll, modifier, result.Total, dc, outcome)
+}
+
+// Describe the lowest and highest rolls of a sequence and how many times
+// each was rolled
+//
+//	Ex: {4, 1, 6, 6, 2} -> "min=1 (x1), max=6 (x2)"
+//
+//	Params
+//		rolls []int : rolls in order
+//	Returns
+//		string : the extremes and their counts
+func formatMinMax(rolls []int) string {
+	min, max := probgen.MinMax(rolls)
+	minCount, maxCount := 0, 0
+	for _, roll := range rolls {
+		if roll == min {
+			minCount++
+		}
+		if roll == max {
+			maxCount++
+		}
+	}
+
+	return fmt.Sprintf("min=%d (x%d), max=%d (x%d)", min, minCount, max, maxCount)
+}
+
+// Prompt whether the user wants to export the results of a run as CSV,
+// and if so to which file
+//
+//	Params
+//		stdin io.Reader    : holds user input
+//		res map[string]int : aggregated results of the run
+//	Returns
+//		bool  : true if user indicates they are done
+//		error : any error encountered writing the file
+func promptExportCSV(stdin io.Reader, res map[string]int) (bool, error) {
+	done, yes := utilities.PromptYesNo(stdin, "Would you like to export the results as CSV?")
+	if done || !yes {
+		return done, nil
+	}
+
+	fmt.Print("Please enter the CSV file path:\n")
+	done, path := utilities.ProcessInputStr(stdin)
+	if done {
+		return true, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if err := probgen.ExportCSV(res, file); err != nil {
+		return false, err
+	}
+
+	fmt.Printf("Results exported to '%s'\n", path)
+	return false, nil
+}
+
+// Split the comma separated faces of a custom dice, trimming surrounding
+// whitespace from each face
+//
+//	Params
+//		faces string : comma separated faces. Ex: "+, -, 0"
+//	Returns
+//		[]string : each face. Ex: {"+", "-", "0"}
+func splitFaces(faces string) []string {
+	split := strings.Split(faces, ",")
+	for i := range split {
+		split[i] = strings.TrimSpace(split[i])
+	}
+
+	return split
+}
+
+// Prompt the user for the number of players and each of their names. Names
+// are trimmed, and blank or duplicate (case-insensitive) names are rejected
+// with a reprompt for the same player. At most MaxPlayers can play
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool     : true if user indicates they are done
+//		[]string : names of the players
+//		error    : any error encountered
+func getPlayers(stdin io.Reader) (bool, []string, error) {
+	fmt.Print("Please indicate the number of players:\n")
+	done, players_n, err := utilities.ProcessInputIntRange(stdin, 1, MaxPlayers)
+	if done {
+		return true, nil, err
+	}
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	players := make([]string, 0, players_n)
+
+	for len(players) < players_n {
+		fmt.Printf("Please enter player %d's name:\n", len(players)+1)
+		done, player := utilities.ProcessInputStr(stdin)
+		if done {
+			return true, nil, nil
+		}
+
+		if err := validatePlayerName(players, player); err != nil {
+			fmt.Print(err.Error() + "\n")
+			continue
+		}
+
+		players = append(players, strings.TrimSpace(player))
+	}
+
+	return false, players, nil
+}
+
+// Check a proposed player name against the players entered so far
+//
+//	Params
+//		players []string : names already taken
+//		name string      : proposed name, before trimming
+//	Returns
+//		error : ErrEmptyName or ErrDuplicateName, otherwise nil
+func validatePlayerName(players []string, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New(ErrEmptyName)
+	}
+
+	for _, player := range players {
+		if strings.EqualFold(player, name) {
+			return fmt.Errorf(ErrDuplicateName, player)
+		}
+	}
+
+	return nil
+}
+
+// Prompt the user for the number of AI opponents and add them after the
+// human players, up to MaxPlayers in total. AI players are named "AI 1",
+// "AI 2", ... which must not already be taken by a human player
+//
+//	Params
+//		stdin io.Reader  : holds user input
+//		players []string : names of the human players
+//	Returns
+//		bool     : true if user indicates they are done
+//		[]string : names of all players
+//		[]bool   : parallel to the players, true for an AI player
+//		error    : any error encountered
+func getAIPlayers(stdin io.Reader, players []string) (bool, []string, []bool, error) {
+	fmt.Print("Please indicate the number of AI opponents:\n")
+	done, ai_n, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, nil, nil, err
+	}
+
+	if err != nil {
+		return false, nil, nil, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if ai_n < 0 {
+		return false, nil, nil, errors.New(ErrNegativeAI)
+	}
+
+	// Checked before allocating, the count may be absurdly large
+	if ai_n > MaxPlayers-len(players) {
+		return false, nil, nil, fmt.Errorf(ErrTooManyPlayers, MaxPlayers)
+	}
+
+	ai := make([]bool, len(players), len(players)+ai_n)
+	for i := 1; i <= ai_n; i++ {
+		name := fmt.Sprintf("AI %d", i)
+		if err := validatePlayerName(players, name); err != nil {
+			return false, nil, nil, err
+		}
+
+		players = append(players, name)
+		ai = append(ai, true)
+	}
+
+	return false, players, ai, nil
+}
+
+// Prompt for a new list of players after a Shut the Box win, the same way a
+// game is set up. An invalid input ends the game, see games.PlayerSetup
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool     : true if user indicates they are done, or on invalid input
+//		[]string : names of all players
+//		[]bool   : parallel to the players, true for an AI player
+func setupPlayers(stdin io.Reader) (bool, []string, []bool) {
+	done, players, err := getPlayers(stdin)
+	if done || err != nil {
+		printSetupError(err)
+		return true, nil, nil
+	}
+
+	done, players, ai, err := getAIPlayers(stdin, players)
+	if done || err != nil {
+		printSetupError(err)
+		return true, nil, nil
+	}
+
+	return false, players, ai
+}
+
+// Print an error ending the player setup, if any
+//
+//	Params
+//		err error : the error, or nil
+func printSetupError(err error) {
+	if err != nil {
+		fmt.Print(err.Error() + "\n")
+	}
+}
+
+// Prompt the user for the total number of slots in the Shut the Box
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool  : true if user indicates they are done
+//		int   : the box size
+//		error : any error encountered
+func getBoxSize(stdin io.Reader) (bool, int, error) {
+	fmt.Printf("Please enter the box size [%d,%d]:\n", games.SizeBox, games.MaxSizeBox)
+	done, boxSize, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, -1, err
+	}
+
+	if err != nil {
+		return false, -1, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if !games.ValidBoxSize(boxSize) {
+		return false, -1, fmt.Errorf(games.ErrInvalidBoxSize, games.SizeBox, games.MaxSizeBox)
+	}
+
+	return false, boxSize, nil
+}
+
+// Prompt the user for the number of dice summed for the Shut the Box target
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool  : true if user indicates they are done
+//		int   : the number of dice
+//		error : any error encountered
+func getNumDice(stdin io.Reader) (bool, int, error) {
+	fmt.Printf("Please enter the number of dice [%d,%d]:\n", games.NumDice, games.MaxNumDice)
+	done, numDice, err := utilities.ProcessInputInt(stdin)
+	if done {
+		return true, -1, err
+	}
+
+	if err != nil {
+		return false, -1, errors.New(SyntaxErrExpectedInt)
+	}
+
+	if !games.ValidNumDice(numDice) {
+		return false, -1, fmt.Errorf(games.ErrInvalidNumDice, games.NumDice, games.MaxNumDice)
+	}
+
+	return false, numDice, nil
+}
+
+// Flip a coin and display the results, shared by the menu and RunOperation
+//
+//	Params
+//		flips int : number of coin flips
+//	Returns
+//		map[string]int : number of Heads and Tails
+//		error          : any errors encountered during validation
+func flipCoins(flips int) (map[string]int, error) {
+	return probgen.ValidateAndExecuteResults(probgen.NewCoinFlip(flips))
+}
+
+// Roll a dice and display the results, shared by the menu and RunOperation
+//
+//	Params
+//		sides int : number of dice sides. Ex: 20
+//		rolls int : number of dice rolls
+//	Returns
+//		map[string]int : number of rolls of each face
+//		error          : any errors encountered during validation
+func rollDice(sides int, rolls int) (map[string]int, error) {
+	return probgen.ValidateAndExecuteResults(probgen.NewDiceRoll(rolls, sides))
+}
+
+// Run a single operation and return, without the menu. Allows scripts to
+// drive the tool through command line flags
+//
+//	Example:
+//		RunOperation(OpRoll, 20, 100)
+//
+//	Params
+//		op string  : operation to run, OpFlip or OpRoll. Ex: "flip"
+//		sides int  : number of dice sides, ignored by OpFlip. Ex: 6
+//		events int : number of flips or rolls. Ex: 1000
+//	Returns
+//		map[string]int : results of the operation
+//		error          : ErrUnknownOperation or any errors encountered during
+//		                 validation
+func RunOperation(op string, sides int, events int) (map[string]int, error) {
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case OpFlip:
+		return flipCoins(events)
+	case OpRoll:
+		return rollDice(sides, events)
+	}
+
+	return nil, fmt.Errorf(ErrUnknownOperation, op)
+}
+
+// Main driving function. Will continue to prompt user for input
+// until failure or user asks to exit
+func Menu() {
+	MenuWith(os.Stdin)
+}
+
+// Main driving function reading all input, for the menu and every
+// operation, from the given reader. Allows a session to be scripted
+//
+//	Params
+//		stdin io.Reader : holds user input. Ex: os.Stdin or a script file
+func MenuWith(stdin io.Reader) {
+	done, input, err := false, -1, error(nil)
+	attempts := 0
+
+	menu_options := Options{verbose: VerboseMenu}
+	menu_options.registerOptions()
+
+	// Consumer user input until user is done and indicates exit
+	// through the Exit option
+	for !done {
+		// User input for menu option parsed
+		menu_options.displayOptions()
+		// Errors from processing options fall back to the
+		// main menu to here where user is prompted again
+		switch cmd, input_s := utilities.ProcessInputCmd(stdin); cmd {
+		case utilities.CmdQuit:
+			// Program termination was requested from the menu
+			return
+		case utilities.CmdEOF:
+			// Input is closed, prompting again would loop forever
+			return
+		case utilities.CmdDone:
+			input, err = -1, nil
+		default:
+			input, err = menu_options.parseOption(input_s)
+		}
+
+		if err != nil {
+			fmt.Print(err)
+		} else {
+			// For this iteration, we will run the selected option
+			done, err = menu_options.runOption(stdin, input)
+			if err != nil {
+				fmt.Print(err)
+			}
+		}
+
+		// Guard against endless invalid input, such as an exhausted pipe
+		if err != nil {
+			attempts++
+			if err = utilities.CheckAttempts(attempts); err != nil {
+				fmt.Printf("\n%s\n", err.Error())
+				return
+			}
+		} else {
+			attempts = 0
 		}
 	}
 }
@@ -2,8 +2,17 @@ package options
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/romansod/roll-dice/internal/history"
+	"github.com/romansod/roll-dice/internal/probgen"
 	"github.com/romansod/roll-dice/internal/testing_utils"
 	"github.com/romansod/roll-dice/internal/utilities"
 )
@@ -31,15 +40,56 @@ func TestDisplay(t *testing.T) {
 
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected =
-		"\n\nPlease enter the option number" +
+		"\n\nPlease enter the option number or name" +
 			"\n\nRegistered Options:\n" +
 			"\n\t0) Exit" +
 			"\n\t1) Flip Coins" +
 			"\n\t2) Roll Dice" +
-			"\n\t3) Shut the Box\n"
+			"\n\t3) Shut the Box" +
+			"\n\t4) Coin Convergence" +
+			"\n\t5) Roll Custom Dice" +
+			"\n\t6) Lifetime Stats" +
+			"\n\t7) Roll Dice Sum" +
+			"\n\t8) Roll Until" +
+			"\n\t9) D20 Advantage" +
+			"\n\t10) History" +
+			"\n\t11) Help" +
+			"\n\t12) Roll Sequence" +
+			"\n\t13) Dice Pool" +
+			"\n\t14) Roll Min Max" +
+			"\n\t15) DC Check" +
+			"\n\t16) At Least One" +
+			"\n\t17) Dice Jack" +
+			"\n\t18) Dice Types" +
+			"\n\t19) Mixed Dice" +
+			"\n\t20) Cumulative Results" +
+			"\n\t21) Exact Heads" +
+			"\n\t22) Success Pool" +
+			"\n\t23) Weighted Convergence" +
+			"\n\t24) Practice Target" +
+			"\n\t25) Replay Game\n"
 	testing_utils.AssertEQ(t, expected, output)
 }
 
+func TestDisplayVerbose(t *testing.T) {
+	// Verbose display adds each description indented under its option
+
+	options := setUp()
+	options.verbose = true
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.displayOptions()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(
+		t, true,
+		strings.Contains(output, "\t0) Exit\n\t\tExit the program.\n\t1) Flip Coins\n\t\tFlip a fair coin"))
+	for _, opt_t := range options.opts {
+		testing_utils.AssertEQb(
+			t, true,
+			strings.Contains(output, fmt.Sprintf("%s\n\t\t%s\n", opt_t.getName(), opt_t.getDescription())))
+	}
+}
+
 func TestProcessInput(t *testing.T) {
 	// Tests input processing for error and passing values
 	// Ignoring stdout helps with extra lines added to processInput
@@ -82,9 +132,126 @@ func TestProcessInput(t *testing.T) {
 	testing_utils.AssertEQ(t, "playername", input_s)
 	stdin.Reset()
 
+	// Quit commands are classified and terminate through Exit
+	origExit, origConfirm := utilities.Exit, utilities.ConfirmQuit
+	exitCode := -1
+	utilities.Exit = func(code int) { exitCode = code }
+
+	utilities.ConfirmQuit = false
+	for _, quit := range utilities.QuitCmds {
+		exitCode = -1
+		stdin.Write([]byte(quit))
+		cmd, input_s := utilities.ProcessInputCmd(&stdin)
+		testing_utils.AssertEQb(t, true, cmd == utilities.CmdQuit)
+		testing_utils.AssertEQ(t, "", input_s)
+		testing_utils.AssertEQi(t, 0, exitCode)
+		stdin.Reset()
+	}
+
+	// Quit is seen as done by callers of ProcessInputStr
+	stdin.Write([]byte(":q"))
+	done, _ = utilities.ProcessInputStr(&stdin)
+	testing_utils.AssertEQb(t, true, done)
+	stdin.Reset()
+
+	// Declined confirmation keeps prompting instead of terminating
+	utilities.ConfirmQuit = true
+	exitCode = -1
+	stdin.Write([]byte("quit\nn\n\n"))
+	cmd, _ := utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdDone)
+	testing_utils.AssertEQi(t, -1, exitCode)
+	stdin.Reset()
+
+	// Confirmation is case-insensitive
+	stdin.Write([]byte("quit\nYes\n"))
+	cmd, _ = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdQuit)
+	testing_utils.AssertEQi(t, 0, exitCode)
+	stdin.Reset()
+
+	// Input ending before an answer neither quits nor prompts again
+	exitCode = -1
+	stdin.Write([]byte("quit\n"))
+	cmd, _ = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdEOF)
+	testing_utils.AssertEQi(t, -1, exitCode)
+	stdin.Reset()
+
+	utilities.Exit, utilities.ConfirmQuit = origExit, origConfirm
+
+	// Closed input is distinct from an explicit empty line
+	stdin.Write([]byte("\n"))
+	cmd, _ = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdDone)
+	cmd, _ = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdEOF)
+	stdin.Reset()
+
+	// A final line without a line ending is still a value
+	stdin.Write([]byte("last"))
+	cmd, input_s = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdValue)
+	testing_utils.AssertEQ(t, "last", input_s)
+	cmd, _ = utilities.ProcessInputCmd(&stdin)
+	testing_utils.AssertEQb(t, true, cmd == utilities.CmdEOF)
+	stdin.Reset()
+
+	// Callers of ProcessInputStr and ProcessInputInt see closed input as done
+	done, _ = utilities.ProcessInputStr(&stdin)
+	testing_utils.AssertEQb(t, true, done)
+	done, _, err = utilities.ProcessInputInt(&stdin)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+
 	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
 }
 
+func TestScriptedMenu(t *testing.T) {
+	// A whole session is driven from a reader: flip coins 10 times,
+	// decline the export, return to the menu, then exit
+
+	origExit := utilities.Exit
+	exitCode := -1
+	utilities.Exit = func(code int) { exitCode = code }
+	origStdout, r, w := testing_utils.RedirectStdout()
+
+	script := bytes.NewBufferString("1\n10\nn\n\nexit\ny\n")
+	MenuWith(script)
+
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	utilities.Exit = origExit
+
+	// Every line of the script was consumed and the menu exited normally
+	testing_utils.AssertEQi(t, 0, script.Len())
+	testing_utils.AssertEQi(t, -1, exitCode)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Please enter the number of coin flips:\n"))
+	testing_utils.AssertEQi(t, 2, strings.Count(output, "Returning to main menu ...\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Exiting now ...\n"))
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "End of input reached"))
+}
+
+func TestMenuEOF(t *testing.T) {
+	// The menu returns once input is closed rather than looping forever
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	origStdin, in := testing_utils.RedirectStdin("")
+
+	Menu()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "End of input reached\n"))
+
+	testing_utils.RestoreStdin(origStdin, in)
+
+	// Input closing part way through an operation returns from the menu too
+	for _, script := range []string{"1\n", "1\n10\n", "2\n6\n", "19\n1d6\n", "3\n1\n0\n"} {
+		origStdout, r, w = testing_utils.RedirectStdout()
+		MenuWith(bytes.NewBufferString(script))
+		output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+		testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "End of input reached\n"))
+	}
+}
+
 func TestMenuOptions(t *testing.T) {
 	// Tests the selection of menu options
 	// This will likely need updates for every new feature
@@ -95,26 +262,849 @@ func TestMenuOptions(t *testing.T) {
 	expected := ""
 
 	/// - -1) Unsupported Option
-	done, err := options.runOption(-1)
+	done, err := options.runOption(os.Stdin, -1)
 	expected = ErrUnsupported
 	testing_utils.AssertEQ(t, expected, err.Error())
 	testing_utils.AssertEQb(t, false, done)
 
 	/// - 0) Exit
-	done, err = options.runOption(exit)
+	done, err = options.runOption(bytes.NewBufferString("y\n"), exit)
 	expected = "nil"
 	testing_utils.AssertNIL(t, err)
 	testing_utils.AssertEQb(t, true, done)
 
 	/// - 1) Flip Coins
-	done, err = options.runOption(flip_coins)
+	done, err = options.runOption(os.Stdin, flip_coins)
 	testing_utils.AssertNIL(t, err)
 	testing_utils.AssertEQb(t, false, done)
 
 	/// - 2) Roll Dice
-	done, err = options.runOption(roll_dice)
+	done, err = options.runOption(os.Stdin, roll_dice)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+/// - Opt that always panics for testing recovery
+
+type OptPanic struct {
+	name   string
+	optNum int
+}
+
+func (optPanic OptPanic) process(stdin io.Reader) (bool, error) {
+	// Deliberately index out of range
+	var empty []int
+	return empty[optPanic.optNum] == 0, nil
+}
+
+func (optPanic OptPanic) getName() string {
+	return optPanic.name
+}
+
+func (optPanic OptPanic) getOptNum() int {
+	return optPanic.optNum
+}
+
+func (optPanic OptPanic) getDescription() string {
+	return "Always panics."
+}
+
+func TestRecoverPanic(t *testing.T) {
+	// Tests that a panicking option is recovered and control is
+	// returned to the menu with the recovered error reported
+
+	options := setUp()
+	options.opts[99] = OptPanic{name: "Panic", optNum: 99}
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	done, err := options.runOption(os.Stdin, 99)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQ(
+		t,
+		"operation 'Panic' failed unexpectedly: "+
+			"runtime error: index out of range [99] with length 0",
+		err.Error())
+
+	// The menu survives and other options keep working
+	done, err = options.runOption(bytes.NewBufferString("y\n"), exit)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+type OptFake struct {
+	name string
+	runs *int // Number of times processed
+}
+
+func (optFake OptFake) process(stdin io.Reader) (bool, error) {
+	*optFake.runs++
+	return true, nil
+}
+
+func (optFake OptFake) getName() string {
+	return optFake.name
+}
+
+func (optFake OptFake) getOptNum() int {
+	return -1
+}
+
+func (optFake OptFake) getDescription() string {
+	return "Counts its runs."
+}
+
+func TestRegister(t *testing.T) {
+	// Tests that a registered Opt takes the next opt number and can be
+	// selected by number or name like the built-in options
+
+	options := setUp()
+	numBuiltins := len(options.opts)
+	runs := 0
+	testing_utils.AssertNIL(t, options.Register(OptFake{name: "Fake Game", runs: &runs}))
+	testing_utils.AssertEQi(t, numBuiltins+1, len(options.opts))
+
+	opt, err := options.parseOption("fake game")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, numBuiltins, opt)
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	done, err := options.runOption(os.Stdin, opt)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQi(t, 1, runs)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// The menu lists it under its assigned number
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.displayOptions()
+	out := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(out, fmt.Sprintf("\t%d) Fake Game\n", numBuiltins)))
+
+	// (-) Names are unique, ignoring case
+	err = options.Register(OptFake{name: "flip coins", runs: &runs})
+	testing_utils.AssertEQ(t, "option 'flip coins' is already registered", err.Error())
+	err = options.Register(OptFake{name: "FAKE GAME", runs: &runs})
+	testing_utils.AssertEQ(t, "option 'FAKE GAME' is already registered", err.Error())
+	testing_utils.AssertEQi(t, numBuiltins+1, len(options.opts))
+}
+
+func TestNonContiguousOptions(t *testing.T) {
+	// Tests that options after a gap in the opt numbers are still displayed,
+	// runnable and followed by newly registered options
+
+	options := setUp()
+	delete(options.opts, roll_dice)
+	delete(options.opts, custom_dice)
+	runs := 0
+	options.opts[40] = OptFake{name: "Fake Game", runs: &runs}
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.displayOptions()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "Roll Dice\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n\t1) Flip Coins\n\t3) Shut the Box\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n\t4) Coin Convergence\n\t6) Lifetime Stats\n"))
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "\n\t25) Replay Game\n\t40) Fake Game\n"))
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	_, err := options.runOption(os.Stdin, 40)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 1, runs)
+
+	// (-) Removed options are unsupported
+	_, err = options.runOption(os.Stdin, roll_dice)
+	testing_utils.AssertEQ(t, ErrUnsupported, err.Error())
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// Registration continues past the highest number
+	testing_utils.AssertNIL(t, options.Register(OptFake{name: "Other Game", runs: &runs}))
+	opt, err := options.parseOption("other game")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 41, opt)
+}
+
+func TestUnregister(t *testing.T) {
+	// Tests that an unregistered option is gone from the menu and can no
+	// longer be run or selected by name
+
+	options := setUp()
+	testing_utils.AssertNIL(t, options.Unregister(roll_dice))
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.displayOptions()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQb(t, false, strings.Contains(output, "Roll Dice\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\n\t1) Flip Coins\n\t3) Shut the Box\n"))
+
+	done, err := options.runOption(bytes.NewBufferString("1\n"), roll_dice)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQ(t, ErrUnsupported, err.Error())
+
+	_, err = options.parseOption("roll dice")
+	testing_utils.AssertEQ(t, ErrUnsupported, err.Error())
+
+	// (-) Only registered options can be removed
+	err = options.Unregister(roll_dice)
+	testing_utils.AssertEQ(t, "option 2 is not registered", err.Error())
+	err = options.Unregister(99)
+	testing_utils.AssertEQ(t, "option 99 is not registered", err.Error())
+}
+
+func TestRunOperation(t *testing.T) {
+	// Tests that each operation from the command line flags runs the same
+	// flip or roll as the menu
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	defer testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	res, err := RunOperation(OpFlip, probgen.D6, 1000)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 1000, res[probgen.Heads]+res[probgen.Tails])
+	testing_utils.AssertEQi(t, 2, len(res))
+
+	res, err = RunOperation("ROLL", probgen.D20, 100)
+	testing_utils.AssertNIL(t, err)
+	total := 0
+	for face, count := range res {
+		face_i, err := strconv.Atoi(face)
+		testing_utils.AssertNIL(t, err)
+		testing_utils.AssertEQb(t, true, face_i >= 1 && face_i <= probgen.D20)
+		total += count
+	}
+	testing_utils.AssertEQi(t, 100, total)
+
+	// (-) Invalid parameters are reported as in the menu
+	_, err = RunOperation(OpRoll, 7, 100)
+	testing_utils.AssertEQ(t, probgen.ErrInvalidDiceType.Error(), err.Error())
+
+	_, err = RunOperation(OpFlip, probgen.D6, 0)
+	testing_utils.AssertEQ(t, probgen.ErrInvalidEvents.Error(), err.Error())
+
+	// (-) Unknown operation
+	_, err = RunOperation("spin", probgen.D6, 100)
+	testing_utils.AssertEQ(t, "unknown operation 'spin': expected 'flip' or 'roll'", err.Error())
+}
+
+func TestParseOption(t *testing.T) {
+	// Options are selected by number or case-insensitive name
+
+	options := setUp()
+
+	for _, input := range []string{"1", "flip coins", "FLIP COINS", " Flip Coins "} {
+		opt, err := options.parseOption(input)
+		testing_utils.AssertNIL(t, err)
+		testing_utils.AssertEQi(t, flip_coins, opt)
+	}
+
+	opt, err := options.parseOption("exit")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, exit, opt)
+
+	opt, err = options.parseOption("d20 advantage")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, advantage, opt)
+
+	// (-) Unknown and partial names are unsupported
+	for _, input := range []string{"roll", "flip", "coins flip"} {
+		_, err = options.parseOption(input)
+		testing_utils.AssertEQ(t, ErrUnsupported, err.Error())
+	}
+}
+
+func TestHelp(t *testing.T) {
+	// Help describes every registered option
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[help].process(os.Stdin)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+	for optNum, opt_t := range options.opts {
+		testing_utils.AssertEQb(
+			t, true,
+			strings.Contains(output, fmt.Sprintf("%d) %s\n\t", optNum, opt_t.getName())))
+		testing_utils.AssertEQb(t, true, strings.Contains(output, opt_t.getDescription()))
+	}
+}
+
+func TestDiceTypes(t *testing.T) {
+	// Dice Types lists every supported dice type
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[dice_types].process(os.Stdin)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQ(t, "\nD4: 1–4\nD6: 1–6\nD10: 1–10\nD12: 1–12\nD20: 1–20\n", output)
+}
+
+func TestMixedPool(t *testing.T) {
+	// Mixed Dice rolls every group of the notation and logs the run
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[mixed_pool].process(bytes.NewBufferString("1d20+2d6\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
 	testing_utils.AssertNIL(t, err)
 	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Rolls : 1d20=["))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "], 2d6=["))
+	testing_utils.AssertEQi(t, 1, len(options.session.entries))
 
+	// (-) Notation and dice types are validated
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	_, err = options.opts[mixed_pool].process(bytes.NewBufferString("1d20+2x6\n"))
+	testing_utils.AssertEQ(t, "invalid dice notation '2x6': expected groups like 1d20+2d6", err.Error())
+	_, err = options.opts[mixed_pool].process(bytes.NewBufferString("1d7\n"))
+	testing_utils.AssertEQ(t, probgen.ErrInvalidDiceType.Error(), err.Error())
+
+	// (-) More dice than events in a run
+	probgen.SetMaxEvents(2)
+	_, err = options.opts[mixed_pool].process(bytes.NewBufferString("1d20+2d6\n"))
+	probgen.SetMaxEvents(probgen.DefaultMaxEvents)
+	testing_utils.AssertEQb(t, true, errors.Is(err, probgen.ErrTooManyEvents))
 	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
 }
+
+func TestExitConfirmation(t *testing.T) {
+	// Exit only proceeds once confirmed
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+
+	// Declined, back to the menu
+	done, err := options.runOption(bytes.NewBufferString("n\n"), exit)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertNIL(t, err)
+
+	// Cancelled by the option itself, not an error
+	done, err = options.opts[exit].process(bytes.NewBufferString("n\n"))
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertNIL(t, err)
+
+	// Done at the prompt is not a confirmation either
+	done, _ = options.runOption(bytes.NewBufferString("\n"), exit)
+	testing_utils.AssertEQb(t, false, done)
+
+	// Invalid answers prompt again
+	done, err = options.runOption(bytes.NewBufferString("maybe\ny\n"), exit)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertNIL(t, err)
+
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQi(t, 5, strings.Count(output, "Are you sure you want to exit? [y/n]\n"))
+	testing_utils.AssertEQi(t, 1, strings.Count(output, "Exiting now ...\n"))
+}
+
+func TestFormatMinMax(t *testing.T) {
+	// Extremes are shown with how many times each was rolled
+
+	testing_utils.AssertEQ(t, "min=1 (x1), max=6 (x2)", formatMinMax([]int{4, 1, 6, 6, 2}))
+
+	// A single roll is both the min and the max
+	testing_utils.AssertEQ(t, "min=3 (x1), max=3 (x1)", formatMinMax([]int{3}))
+}
+
+func TestFormatCheck(t *testing.T) {
+	// The outcome shows the roll, the modifier and the DC
+
+	testing_utils.AssertEQ(
+		t, "rolled 12 +3 = 15 vs DC 15: success",
+		formatCheck(probgen.CheckResult{Roll: 12, Total: 15, Success: true}, 3, 15))
+	testing_utils.AssertEQ(
+		t, "rolled 15 -1 = 14 vs DC 15: failure",
+		formatCheck(probgen.CheckResult{Roll: 15, Total: 14}, -1, 15))
+
+	// Naturals are called out whatever the outcome
+	testing_utils.AssertEQ(
+		t, "rolled 1 +15 = 16 vs DC 10: success (natural 1)",
+		formatCheck(probgen.CheckResult{Roll: 1, Total: 16, Success: true, Natural1: true}, 15, 10))
+	testing_utils.AssertEQ(
+		t, "rolled 20 -5 = 15 vs DC 25: failure (natural 20)",
+		formatCheck(probgen.CheckResult{Roll: 20, Total: 15, Natural20: true}, -5, 25))
+}
+
+func TestAtLeastOne(t *testing.T) {
+	// The exact chance is printed and logged
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[at_least].process(bytes.NewBufferString("6\n6\n4\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "Chance of at least one 6 in 4 rolls : 51.774691%\n\n"))
+
+	// (-) Face not on the dice
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	_, err = options.opts[at_least].process(bytes.NewBufferString("6\n7\n4\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertEQ(t, "invalid target face: must be in range [1,6]", err.Error())
+}
+
+func TestExactHeads(t *testing.T) {
+	// The exact chance is printed and logged
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[exact_heads].process(bytes.NewBufferString("5\n3\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "Chance of exactly 3 heads in 5 flips : 31.250000%\n\n"))
+	testing_utils.AssertEQ(t, "flips=5, heads=3", options.session.entries[0].Parameters)
+
+	// (-) More heads than flips
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	_, err = options.opts[exact_heads].process(bytes.NewBufferString("5\n6\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertEQ(t, "invalid number of heads: must be in range [0,5]", err.Error())
+}
+
+func TestSuccessPool(t *testing.T) {
+	// Every die is at or above a threshold of 1, and the run is logged
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[successes].process(bytes.NewBufferString("4\n6\n1\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(output, "Successes : 4 of 4\n\n"))
+	testing_utils.AssertEQ(t, "dice=4, sides=6, threshold=1", options.session.entries[0].Parameters)
+
+	// (-) Threshold not on the dice
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	_, err = options.opts[successes].process(bytes.NewBufferString("4\n6\n7\n"))
+	testing_utils.AssertEQ(t, "invalid success threshold: must be in range [1,6]", err.Error())
+
+	// (-) More dice than events in a run
+	probgen.SetMaxEvents(3)
+	_, err = options.opts[successes].process(bytes.NewBufferString("4\n6\n1\n"))
+	probgen.SetMaxEvents(probgen.DefaultMaxEvents)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertEQb(t, true, errors.Is(err, probgen.ErrTooManyEvents))
+}
+
+func TestWeightedConvergence(t *testing.T) {
+	// A coin always landing on Heads converges at 100% from the first flip
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[weighted].process(bytes.NewBufferString("10\n1\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(
+		output,
+		"Flips      :   Observed   :  Theoretical\n"+
+			"1          : 100.000000%  : 100.000000%\n"+
+			"5          : 100.000000%  : 100.000000%\n"+
+			"10         : 100.000000%  : 100.000000%\n\n"))
+	testing_utils.AssertEQi(t, 1, len(options.session.entries))
+	testing_utils.AssertEQ(t, "flips=10, heads probability=1", options.session.entries[0].Parameters)
+	testing_utils.AssertEQ(t, "heads=100.000000%", options.session.entries[0].Summary)
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	// (-) Probability is not a number
+	_, err = options.opts[weighted].process(bytes.NewBufferString("10\nhalf\n"))
+	testing_utils.AssertEQ(t, SyntaxErrExpectedFloat, err.Error())
+
+	// (-) Probability outside [0,1]
+	_, err = options.opts[weighted].process(bytes.NewBufferString("10\n1.5\n"))
+	testing_utils.AssertEQ(t, probgen.ErrInvalidHeadsProbability.Error(), err.Error())
+
+	// Done at the probability prompt
+	done, err = options.opts[weighted].process(bytes.NewBufferString("10\n\n"))
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestLifetimeStats(t *testing.T) {
+	// The lifetime totals are written to the probgen output
+
+	origSettings := history.DefaultSettings
+	history.DefaultSettings.Path = filepath.Join(t.TempDir(), "history.jsonl")
+	defer func() { history.DefaultSettings = origSettings }()
+	history.DefaultSettings.Record(probgen.CoinEventType, 10, map[string]int{probgen.Heads: 4, probgen.Tails: 6})
+
+	var buf bytes.Buffer
+	origOutput := probgen.Output
+	probgen.Output = &buf
+	defer func() { probgen.Output = origOutput }()
+
+	options := setUp()
+	done, err := options.opts[lifetime].process(bytes.NewBufferString(""))
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQb(t, true, strings.Contains(buf.String(), "\"total_runs\": 1,\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(buf.String(), "\"total_flips\": 10,\n"))
+}
+
+func TestPractice(t *testing.T) {
+	// The drill returns to the menu once the user is done
+
+	options := setUp()
+	origStdout, r, w := testing_utils.RedirectStdout()
+	done, err := options.opts[practice].process(bytes.NewBufferString("\n"))
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "\nFound 0 of "))
+}
+
+func TestReplay(t *testing.T) {
+	// The last Shut the Box game is replayed with its rolls and moves
+
+	options := setUp()
+	done, err := options.opts[replay].process(bytes.NewBufferString(""))
+	testing_utils.AssertEQ(t, ErrNoGameRecorded, err.Error())
+	testing_utils.AssertEQb(t, false, done)
+
+	// One player, no AI, 9 slots, 2 dice, all dice, seed 7, free play, then
+	// quit at the first move
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.opts[shutthebox].process(bytes.NewBufferString("1\np1\n0\n9\n2\na\n7\n0\n\n"))
+	played := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "[p1]", fmt.Sprint(options.lastGame.Players))
+	testing_utils.AssertEQi(t, 2, len(options.lastGame.Rolls))
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	done, err = options.opts[replay].process(bytes.NewBufferString(""))
+	replayed := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(replayed, "Replaying the last Shut the Box game:\n\n"))
+
+	// Displayed as played, after the setup prompts
+	game := strings.TrimPrefix(replayed, "Replaying the last Shut the Box game:\n")
+	testing_utils.AssertEQb(t, true, strings.Contains(game, "Player: p1"))
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(played, game))
+}
+
+func TestSplitFaces(t *testing.T) {
+	// Tests splitting of custom dice faces
+
+	faces := splitFaces("+, -,0")
+	testing_utils.AssertEQi(t, 3, len(faces))
+	testing_utils.AssertEQ(t, "+", faces[0])
+	testing_utils.AssertEQ(t, "-", faces[1])
+	testing_utils.AssertEQ(t, "0", faces[2])
+
+	faces = splitFaces("crit")
+	testing_utils.AssertEQi(t, 1, len(faces))
+	testing_utils.AssertEQ(t, "crit", faces[0])
+}
+
+func TestQuitFromMenu(t *testing.T) {
+	// Tests that 'quit' at the menu prompt cleanly terminates the program
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	origStdin, r := testing_utils.RedirectStdin("quit\n")
+	origExit, origConfirm := utilities.Exit, utilities.ConfirmQuit
+	utilities.ConfirmQuit = false
+
+	exitCode := -1
+	utilities.Exit = func(code int) { exitCode = code }
+
+	// Menu must return rather than prompt again
+	Menu()
+	testing_utils.AssertEQi(t, 0, exitCode)
+
+	utilities.Exit, utilities.ConfirmQuit = origExit, origConfirm
+	testing_utils.RestoreStdin(origStdin, r)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestGetAIPlayers(t *testing.T) {
+	// AI opponents are added after the human players
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	var stdin bytes.Buffer
+
+	stdin.Write([]byte("2"))
+	done, players, ai, err := getAIPlayers(&stdin, []string{"p1"})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQ(t, "[p1 AI 1 AI 2]", fmt.Sprint(players))
+	testing_utils.AssertEQ(t, "[false true true]", fmt.Sprint(ai))
+	stdin.Reset()
+
+	// (-) Negative count
+	stdin.Write([]byte("-1"))
+	_, _, _, err = getAIPlayers(&stdin, []string{"p1"})
+	testing_utils.AssertEQ(t, ErrNegativeAI, err.Error())
+	stdin.Reset()
+
+	// (-) AI name taken by a human player
+	stdin.Write([]byte("2"))
+	_, players, _, err = getAIPlayers(&stdin, []string{"p1", "ai 2"})
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrDuplicateName, "ai 2"), err.Error())
+	testing_utils.AssertEQi(t, 0, len(players))
+	stdin.Reset()
+
+	// Up to MaxPlayers in total, counting the human players
+	stdin.Write([]byte("11\n"))
+	_, players, _, err = getAIPlayers(&stdin, []string{"p1"})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, DefaultMaxPlayers, len(players))
+	stdin.Reset()
+
+	// (-) One over, or an absurd number, is rejected before allocating
+	for _, input := range []string{"12\n", "1000000000000\n"} {
+		stdin.Write([]byte(input))
+		_, players, _, err = getAIPlayers(&stdin, []string{"p1"})
+		testing_utils.AssertEQ(t, "invalid number of players: must have at most 12 players, see MaxPlayers", err.Error())
+		testing_utils.AssertEQi(t, 0, len(players))
+		stdin.Reset()
+	}
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+}
+
+func TestSetupPlayers(t *testing.T) {
+	// New players after a Shut the Box win, prompted like a new game
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+
+	done, players, ai := setupPlayers(bytes.NewBufferString("1\np3\n1\n"))
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQ(t, "[p3 AI 1]", fmt.Sprint(players))
+	testing_utils.AssertEQ(t, "[false true]", fmt.Sprint(ai))
+
+	// (-) Invalid input ends the game with its error
+	done, players, _ = setupPlayers(bytes.NewBufferString("1\np3\n-1\n"))
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQi(t, 0, len(players))
+
+	// Done while prompting
+	done, _, _ = setupPlayers(bytes.NewBufferString("\n"))
+	testing_utils.AssertEQb(t, true, done)
+
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, ErrNegativeAI+"\n"))
+}
+
+func TestSessionHistory(t *testing.T) {
+	// Runs are logged in the session history across menu operations
+
+	options := setUp()
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	origStdin, r := testing_utils.RedirectStdin("10\nn\n\n")
+	options.runOption(os.Stdin, flip_coins)
+	testing_utils.RestoreStdin(origStdin, r)
+
+	origStdin, r = testing_utils.RedirectStdin("6\n5\nn\n\n")
+	options.runOption(os.Stdin, roll_dice)
+	testing_utils.RestoreStdin(origStdin, r)
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	entries := options.session.entries
+	testing_utils.AssertEQi(t, 2, len(entries))
+
+	testing_utils.AssertEQ(t, "Flip Coins", entries[0].Operation)
+	testing_utils.AssertEQ(t, "flips=10", entries[0].Parameters)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(entries[0].Summary, "Heads="))
+
+	testing_utils.AssertEQ(t, "Roll Dice", entries[1].Operation)
+	testing_utils.AssertEQ(t, "sides=6, rolls=5", entries[1].Parameters)
+
+	// Games and convergence are logged too
+	origStdout, ignoreOut = testing_utils.IgnoreStdout()
+	options.runOption(bytes.NewBufferString("10\n"), convergence)
+	options.runOption(bytes.NewBufferString("1\np1\n0\n9\n2\na\n7\n0\n\n"), shutthebox)
+	options.runOption(bytes.NewBufferString("21\ns\n"), dice_jack)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	entries = options.session.entries
+	testing_utils.AssertEQi(t, 5, len(entries))
+
+	testing_utils.AssertEQ(t, "Coin Convergence", entries[2].Operation)
+	testing_utils.AssertEQ(t, "flips=10", entries[2].Parameters)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(entries[2].Summary, "heads="))
+
+	testing_utils.AssertEQ(t, "Shut the Box", entries[3].Operation)
+	testing_utils.AssertEQ(t, "players=p1, box=9, dice=2", entries[3].Parameters)
+	testing_utils.AssertEQ(t, "dice rolled=2", entries[3].Summary)
+
+	// One roll of at most 6 stands at least 15 away from 21
+	testing_utils.AssertEQ(t, "Dice Jack", entries[4].Operation)
+	testing_utils.AssertEQ(t, "target=21", entries[4].Parameters)
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(entries[4].Summary, " away"))
+
+	// History prints every entry
+	origStdout, r, w := testing_utils.RedirectStdout()
+	options.runOption(os.Stdin, session_log)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Flip Coins (flips=10) : Heads="))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "Roll Dice (sides=6, rolls=5) : "))
+
+	// Summaries are in outcome order
+	testing_utils.AssertEQ(t, "2=1, 10=3", summarizeResults(map[string]int{"10": 3, "2": 1}))
+}
+
+func TestCumulativeResults(t *testing.T) {
+	// Runs of the same kind are added together across the session
+
+	sessionLog := SessionLog{}
+	sessionLog.accumulate("Flip Coins", "", map[string]int{probgen.Heads: 3, probgen.Tails: 7})
+	sessionLog.accumulate("Flip Coins", "", map[string]int{probgen.Heads: 4, probgen.Tails: 1})
+	sessionLog.accumulate("Roll Dice", "sides=4", map[string]int{"1": 2})
+
+	testing_utils.AssertEQi(t, 2, len(sessionLog.totals))
+	testing_utils.AssertEQi(t, 2, sessionLog.totals[0].Runs)
+	testing_utils.AssertEQi(t, 7, sessionLog.totals[0].Results[probgen.Heads])
+	testing_utils.AssertEQi(t, 8, sessionLog.totals[0].Results[probgen.Tails])
+	testing_utils.AssertEQi(t, 1, sessionLog.totals[1].Runs)
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	sessionLog.displayTotals()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(
+		t,
+		"\nFlip Coins : 2 runs, 15 events\n\n"+
+			"\tHeads : 46.666668% : 7\n"+
+			"\tTails : 53.333332% : 8\n"+
+			"\nRoll Dice (sides=4) : 1 runs, 2 events\n\n"+
+			"\t1 : 100.000000% : 2\n",
+		output)
+
+	// Flips and rolls from the menu are added up
+	options := setUp()
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	options.opts[flip_coins].process(bytes.NewBufferString("10\nn\n"))
+	options.opts[flip_coins].process(bytes.NewBufferString("5\nn\n"))
+	options.opts[roll_dice].process(bytes.NewBufferString("6\n5\nn\n"))
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	totals := options.session.totals
+	testing_utils.AssertEQi(t, 2, len(totals))
+	testing_utils.AssertEQi(t, 2, totals[0].Runs)
+	testing_utils.AssertEQi(t, 15, totals[0].Results[probgen.Heads]+totals[0].Results[probgen.Tails])
+	testing_utils.AssertEQ(t, "sides=6", totals[1].Parameters)
+
+	// (-) Nothing run yet
+	origStdout, r, w = testing_utils.RedirectStdout()
+	SessionLog{}.displayTotals()
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "No coin flips or dice rolls yet this session\n", output)
+}
+
+func TestGetPlayers(t *testing.T) {
+	// Player names are trimmed and must be unique and non-blank
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	var stdin bytes.Buffer
+
+	// Duplicate and blank names are reprompted for the same slot
+	stdin.Write([]byte("3\n p1 \nP1\n   \np2\np3\n"))
+	done, players, err := getPlayers(&stdin)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQ(t, "[p1 p2 p3]", fmt.Sprint(players))
+	stdin.Reset()
+
+	// (-) Zero players
+	stdin.Write([]byte("0\n"))
+	_, players, err = getPlayers(&stdin)
+	testing_utils.AssertEQ(t, fmt.Sprintf(utilities.ErrOutOfRange, 0, 1, DefaultMaxPlayers), err.Error())
+	testing_utils.AssertEQi(t, 0, len(players))
+	stdin.Reset()
+
+	// (-) Negative players
+	stdin.Write([]byte("-2\n"))
+	_, _, err = getPlayers(&stdin)
+	testing_utils.AssertEQ(t, fmt.Sprintf(utilities.ErrOutOfRange, -2, 1, DefaultMaxPlayers), err.Error())
+	stdin.Reset()
+
+	// The cap itself
+	stdin.Write([]byte("12\n"))
+	for i := 1; i <= DefaultMaxPlayers; i++ {
+		stdin.Write([]byte(fmt.Sprintf("p%d\n", i)))
+	}
+	_, players, err = getPlayers(&stdin)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, DefaultMaxPlayers, len(players))
+	stdin.Reset()
+
+	// (-) One over the cap, or an absurd number, is rejected before any
+	// name is asked for
+	for _, input := range []string{"13\np1\n", "1000000\np1\n"} {
+		stdin.Write([]byte(input))
+		_, players, err = getPlayers(&stdin)
+		testing_utils.AssertEQb(t, true, strings.HasSuffix(err.Error(), "must be in range [1,12]"))
+		testing_utils.AssertEQi(t, 0, len(players))
+		testing_utils.AssertEQ(t, "p1\n", stdin.String())
+		stdin.Reset()
+	}
+
+	// The cap is configurable
+	MaxPlayers = 2
+	stdin.Write([]byte("3\n"))
+	_, _, err = getPlayers(&stdin)
+	testing_utils.AssertEQ(t, fmt.Sprintf(utilities.ErrOutOfRange, 3, 1, 2), err.Error())
+	MaxPlayers = DefaultMaxPlayers
+	stdin.Reset()
+
+	// (-) Not a number
+	stdin.Write([]byte("two\n"))
+	_, _, err = getPlayers(&stdin)
+	testing_utils.AssertEQ(t, SyntaxErrExpectedInt, err.Error())
+	stdin.Reset()
+
+	// Done while entering names
+	stdin.Write([]byte("2\np1\n\n"))
+	done, players, err = getPlayers(&stdin)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQi(t, 0, len(players))
+	stdin.Reset()
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	err = validatePlayerName([]string{"Alice"}, "alice")
+	testing_utils.AssertEQ(t, "invalid player name: 'Alice' is already taken", err.Error())
+}
+
+func TestMenuMaxAttempts(t *testing.T) {
+	// The menu aborts after too many consecutive invalid inputs rather
+	// than looping forever once the input is exhausted
+
+	origMax := utilities.MaxAttempts
+	utilities.MaxAttempts = 3
+	origStdout, r, w := testing_utils.RedirectStdout()
+	origStdin, in := testing_utils.RedirectStdin("x\n-1\nx\n1000\n")
+
+	// Non numeric and unsupported options are both invalid
+	Menu()
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(
+		t, true,
+		strings.HasSuffix(output, fmt.Sprintf(utilities.ErrTooManyAttempts, 3)+"\n"))
+
+	testing_utils.RestoreStdin(origStdin, in)
+	utilities.MaxAttempts = origMax
+
+	// Below the limit nothing aborts
+	testing_utils.AssertNIL(t, utilities.CheckAttempts(2))
+}
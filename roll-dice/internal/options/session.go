@@ -0,0 +1,158 @@
+/*
+session.go - session history
+
+Append-only log of every run performed during a
+single execution of the program
+*/
+package options
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+)
+
+// One run performed during the session
+type SessionEntry struct {
+	Time       time.Time // when the run completed
+	Operation  string    // name of the Opt which performed the run
+	Parameters string    // inputs of the run. Ex: "flips=10"
+	Summary    string    // outcome of the run. Ex: "Heads=4, Tails=6"
+}
+
+// Results of every run of the same operation and parameters added together
+type SessionTotal struct {
+	Operation  string         // name of the Opt which performed the runs
+	Parameters string         // inputs shaping the distribution. Ex: "sides=6"
+	Runs       int            // number of runs added together
+	Results    map[string]int // combined results of every run
+}
+
+// Session history shared by every Opt for the life of the Menu
+type SessionLog struct {
+	entries []SessionEntry
+	totals  []SessionTotal // in the order first run
+}
+
+// Append an entry for a completed run
+//
+//	Params
+//		operation string  : name of the Opt which performed the run
+//		parameters string : inputs of the run
+//		summary string    : outcome of the run
+func (sessionLog *SessionLog) add(operation string, parameters string, summary string) {
+	sessionLog.entries = append(sessionLog.entries, SessionEntry{
+		Time:       time.Now(),
+		Operation:  operation,
+		Parameters: parameters,
+		Summary:    summary,
+	})
+}
+
+// Add the results of a completed run to the total of every run with the
+// same operation and parameters. The parameters should only hold the inputs
+// shaping the distribution, not the number of events, so that runs of
+// different sizes add up
+//
+//	Params
+//		operation string   : name of the Opt which performed the run
+//		parameters string  : inputs shaping the distribution. Ex: "sides=6"
+//		res map[string]int : aggregated results of the run
+func (sessionLog *SessionLog) accumulate(operation string, parameters string, res map[string]int) {
+	i := slices.IndexFunc(sessionLog.totals, func(total SessionTotal) bool {
+		return total.Operation == operation && total.Parameters == parameters
+	})
+
+	if i < 0 {
+		sessionLog.totals = append(sessionLog.totals, SessionTotal{
+			Operation:  operation,
+			Parameters: parameters,
+			Results:    make(map[string]int),
+		})
+		i = len(sessionLog.totals) - 1
+	}
+
+	total := &sessionLog.totals[i]
+	total.Runs++
+	for outcome, count := range res {
+		total.Results[outcome] += count
+	}
+}
+
+// Print the combined distribution of every total in the order first run
+//
+// Ex:
+//
+// Roll Dice (sides=4) : 2 runs, 10 events
+//
+//	1 : 20.000000% : 2
+//	...
+func (sessionLog SessionLog) displayTotals() {
+	if len(sessionLog.totals) == 0 {
+		fmt.Print("No coin flips or dice rolls yet this session\n")
+		return
+	}
+
+	for _, total := range sessionLog.totals {
+		events := 0
+		for _, count := range total.Results {
+			events += count
+		}
+
+		name := total.Operation
+		if total.Parameters != "" {
+			name += " (" + total.Parameters + ")"
+		}
+		fmt.Printf("\n%s : %d runs, %d events\n\n", name, total.Runs, events)
+
+		for _, outcome := range probgen.SortedOutcomes(total.Results) {
+			count := total.Results[outcome]
+			fmt.Printf(
+				"\t%s : %s : %d\n",
+				outcome,
+				probgen.FormatPercent(float64(probgen.Percent(count, events))),
+				count)
+		}
+	}
+}
+
+// Print every entry in the order the runs were performed
+//
+// Ex:
+//
+// [15:04:05] Flip Coins (flips=10) : Heads=4, Tails=6
+func (sessionLog SessionLog) display() {
+	if len(sessionLog.entries) == 0 {
+		fmt.Print("No runs yet this session\n")
+		return
+	}
+
+	for _, entry := range sessionLog.entries {
+		fmt.Printf(
+			"[%s] %s (%s) : %s\n",
+			entry.Time.Format(time.TimeOnly),
+			entry.Operation,
+			entry.Parameters,
+			entry.Summary)
+	}
+}
+
+// Summarize the results of a run in outcome order
+//
+//	Ex: {"Heads": 4, "Tails": 6} -> "Heads=4, Tails=6"
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//	Returns
+//		string : summary of the results
+func summarizeResults(res map[string]int) string {
+	outcomes := probgen.SortedOutcomes(res)
+	for i, outcome := range outcomes {
+		outcomes[i] = fmt.Sprintf("%s=%d", outcome, res[outcome])
+	}
+
+	return strings.Join(outcomes, ", ")
+}
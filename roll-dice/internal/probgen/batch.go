@@ -0,0 +1,89 @@
+/*
+batch.go
+
+Several probability experiments run one
+after another from a list of specs
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+const ErrBatchExperiment = "experiment %d: %w"
+
+// One experiment of a batch, named by its event type as in the run history
+//
+//	Ex: {CoinEventType, 1000} flips a coin 1000 times
+//	Ex: {"D20", 1000} rolls a D20 1000 times
+type ExperimentSpec struct {
+	EventType string // CoinEventType or a dice type, see DiceEventType
+	NumEvents int    // number of flips or rolls
+}
+
+// Build the probability event described by the spec
+//
+//	Params
+//		spec ExperimentSpec : the experiment to build
+//	Returns
+//		ProbEventType : the probability event, not yet validated
+//		error         : ErrUnknownEventType for an unsupported event type
+func (spec ExperimentSpec) probEventType() (ProbEventType, error) {
+	if spec.EventType == CoinEventType {
+		return NewCoinFlip(spec.NumEvents), nil
+	}
+
+	sides_s, ok := strings.CutPrefix(spec.EventType, "D")
+	if !ok {
+		return nil, unknownEventType(spec.EventType)
+	}
+
+	nSides, err := strconv.Atoi(sides_s)
+	if err != nil {
+		return nil, unknownEventType(spec.EventType)
+	}
+
+	return NewDiceRoll(spec.NumEvents, nSides), nil
+}
+
+// Wrap ErrUnknownEventType with the event type and the supported ones
+//
+//	Params
+//		eventType string : the unsupported event type. Ex: "spinner"
+//	Returns
+//		error : ErrUnknownEventType with context
+func unknownEventType(eventType string) error {
+	return fmt.Errorf("%w '%s': expected '%s' or a dice type. Ex: D6", ErrUnknownEventType, eventType, CoinEventType)
+}
+
+// Validate and execute every experiment in order, displaying each. Stops at
+// the first experiment that fails, ex: an invalid dice type
+//
+//	Params
+//		specs []ExperimentSpec : experiments to run in order
+//	Returns
+//		[]map[string]int : results of the experiments run, in the order of specs
+//		error            : the first error, wrapped with the index of its experiment
+func RunBatch(specs []ExperimentSpec) ([]map[string]int, error) {
+	results := make([]map[string]int, 0, len(specs))
+	for i, spec := range specs {
+		probEventType, err := spec.probEventType()
+		if err != nil {
+			return results, fmt.Errorf(ErrBatchExperiment, i, err)
+		}
+
+		res, err := ValidateAndExecuteResults(probEventType)
+		if err != nil {
+			return results, fmt.Errorf(ErrBatchExperiment, i, err)
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,180 @@
+/*
+biasedcoinflip.go
+
+BiasedCoinFlip is a ProbEventType which
+describes flips of a coin landing on
+Heads with a given probability
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrInvalidHeadsProbability = errors.New("invalid heads probability: must be in range [0,1]")
+
+// Number of equally likely positions the heads probability is resolved to
+const BiasResolution = 1000000
+
+type BiasedCoinFlip struct {
+	numEvents        int           // number of coin flips
+	headsProbability float64       // probability of each flip landing on Heads
+	prng             func(int) int // The Pseudo Random Number Generator to use
+}
+
+// Initialize private fields
+//
+//	Params
+//		nEvents int              : number of BiasedCoinFlip events
+//		headsProbability float64 : probability of Heads in [0,1]. Ex: 0.7
+//	Returns
+//		*BiasedCoinFlip : new BiasedCoinFlip object
+func NewBiasedCoinFlip(nEvents int, headsProbability float64) *BiasedCoinFlip {
+	return &BiasedCoinFlip{
+		numEvents:        nEvents,
+		headsProbability: headsProbability,
+		prng:             RandNumGen,
+	}
+}
+
+func (biasedCoinFlip BiasedCoinFlip) validate() (bool, error) {
+	// Written to also reject NaN
+	if !(biasedCoinFlip.headsProbability >= 0 && biasedCoinFlip.headsProbability <= 1) {
+		return false, ErrInvalidHeadsProbability
+	}
+
+	return true, nil
+}
+
+func (biasedCoinFlip BiasedCoinFlip) execute() (map[string]int, error) {
+	res := biasedCoinFlip.flip()
+	biasedCoinFlip.display(res)
+
+	return res, nil
+}
+
+// Flip the biased coin numEvents times
+//
+//	Returns
+//		map[string]int : number of Heads and Tails
+func (biasedCoinFlip BiasedCoinFlip) flip() map[string]int {
+	pe := ProbEvent{
+		numEvents: biasedCoinFlip.numEvents,
+		outcomes:  []string{Heads, Tails},
+		prng:      biasedCoinFlip.biasedPrng()}
+
+	return pe.computeProbability()
+}
+
+// Wrap the prng so that it lands on Heads for positions below the heads
+// threshold instead of uniformly over the outcomes
+//
+//	Ex: probability 0.7 -> positions [0, 700000) are Heads
+//
+//	Returns
+//		func(int) int : prng compatible biased selection of H or T
+func (biasedCoinFlip BiasedCoinFlip) biasedPrng() func(int) int {
+	threshold := biasedCoinFlip.headsThreshold()
+
+	return func(int) int {
+		if biasedCoinFlip.prng(BiasResolution) < threshold {
+			return H
+		}
+
+		return T
+	}
+}
+
+// Number of the BiasResolution positions which land on Heads
+//
+//	Returns
+//		int : heads probability scaled to BiasResolution
+func (biasedCoinFlip BiasedCoinFlip) headsThreshold() int {
+	return int(math.Round(biasedCoinFlip.headsProbability * BiasResolution))
+}
+
+// Flip the biased coin in order and record the observed percent of heads
+// once each number of flips is reached, see CoinFlip.headsPercentAtFlips
+//
+//	Params
+//		flips []int : ascending numbers of flips, at most numEvents
+//	Returns
+//		[]float64 : observed heads percent at each number of flips
+func (biasedCoinFlip BiasedCoinFlip) headsPercentAtFlips(flips []int) []float64 {
+	coinFlip := CoinFlip{
+		numEvents: biasedCoinFlip.numEvents,
+		prng:      biasedCoinFlip.biasedPrng()}
+
+	return coinFlip.headsPercentAtFlips(flips)
+}
+
+// Exposed endpoint to flip the biased coin and print the convergence table
+// of the observed heads percent towards the heads probability, rather than
+// towards 50%. Checkpoints are the same as ExecuteConvergence
+//
+//	Params
+//		nEvents int              : number of BiasedCoinFlip events
+//		headsProbability float64 : probability of Heads in [0,1]. Ex: 0.7
+//		prng func(int) int       : the Pseudo Random Number Generator to use
+//	Returns
+//		float64 : observed heads percent after every flip
+//		error   : any errors encountered during validation
+func ExecuteBiasedConvergence(nEvents int, headsProbability float64, prng func(int) int) (float64, error) {
+	biasedCoinFlip := NewBiasedCoinFlip(nEvents, headsProbability)
+	biasedCoinFlip.prng = prng
+	ok, err := validateAll(biasedCoinFlip)
+	if !ok {
+		return 0, err
+	}
+
+	checkpoints := convergenceCheckpoints(nEvents)
+	percents := biasedCoinFlip.headsPercentAtFlips(checkpoints)
+	printConvergence(checkpoints, percents, headsProbability*100)
+
+	return percents[len(percents)-1], nil
+}
+
+// Print the biased coin results with the theoretical percent of each face
+// side by side. Example:
+//
+// numEvents: 4
+//
+// headsProbability: 0.7
+//
+// Face :   Observed   :  Theoretical : Count
+//
+// (H)  :  75.000000%  :  70.000000%  : 3
+//
+// (T)  :  25.000000%  :  30.000000%  : 1
+//
+//	Params
+//		res map[string]int : results of biased coin flips
+func (biasedCoinFlip BiasedCoinFlip) display(res map[string]int) {
+	theoretical := map[string]float64{
+		Heads: biasedCoinFlip.headsProbability * 100,
+		Tails: (1 - biasedCoinFlip.headsProbability) * 100,
+	}
+
+	fmt.Fprint(Output, "Face :   Observed   :  Theoretical : Count\n")
+	for _, face := range []string{Heads, Tails} {
+		fmt.Fprintf(
+			Output,
+			"%-4s : %10.*f%%  : %10.*f%%  : %d\n",
+			"("+face[:1]+")",
+			Precision, Percent(res[face], biasedCoinFlip.numEvents),
+			Precision, theoretical[face],
+			res[face],
+		)
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Retrieve number of events
+//
+//	Returns
+//		int : number of events
+func (biasedCoinFlip BiasedCoinFlip) getNumEvents() int {
+	return biasedCoinFlip.numEvents
+}
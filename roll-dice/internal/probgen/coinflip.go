@@ -7,9 +7,15 @@ describes coin flips
 package probgen
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"unicode/utf8"
 )
 
+var ErrInvalidCoinSides = errors.New("invalid coin sides: expected two different labels, neither empty")
+var ErrInvalidHeads = errors.New("invalid number of heads")
+
 // Potential values
 const (
 	Heads = "Heads"
@@ -32,39 +38,124 @@ var coinVisuals = map[int]string{
 		" -----\n",
 }
 
+// Event type of coin flip runs in the run history
+const CoinEventType = "coin"
+
+// Theoretical percent of heads for a fair coin
+const TheoreticalHeadsPercent = 50.0
+
+// Theoretical variance of a single fair coin flip, counting heads as 1 and
+// tails as 0: p * (1 - p)
+const TheoreticalFlipVariance = 0.25
+
+// Default convergence checkpoints as fractions of the total number of flips
+var DefaultCheckpoints = []float64{0.1, 0.5, 1.0}
+
+// Check convergence at every power of 10 flips instead of DefaultCheckpoints
+var LogConvergence = false
+
 type CoinFlip struct {
-	numEvents int // number of coin flips
+	numEvents int           // number of coin flips
+	sides     []string      // labels of the two sides, nil for Heads and Tails
+	prng      func(int) int // The Pseudo Random Number Generator to use
 }
 
 // Initialize private fields
 //
 //	Params
-//		nEvents int : number of CoinFlip events
+//		nEvents int     : number of CoinFlip events
+//		sides ...string : optional labels of the two sides, Heads and Tails
+//		                  when omitted. Ex: "Win", "Lose"
 //	Returns
 //		*CoinFlip : new CoinFlip object
-func NewCoinFlip(nEvents int) *CoinFlip {
+func NewCoinFlip(nEvents int, sides ...string) *CoinFlip {
 	return &CoinFlip{
 		numEvents: nEvents,
+		sides:     sides,
+		prng:      RandNumGen,
 	}
 }
 
 func (coinFlip CoinFlip) validate() (bool, error) {
-	// Nothing to do as Heads and Tails are already implied
+	// Heads and Tails are already implied, custom sides must be told apart
+	if coinFlip.sides == nil {
+		return true, nil
+	}
+
+	sides := coinFlip.sides
+	if len(sides) != 2 || sides[0] == "" || sides[1] == "" || sides[0] == sides[1] {
+		return false, ErrInvalidCoinSides
+	}
+
 	return true, nil
 }
 
-func (coinFlip CoinFlip) execute() error {
+// Labels of the two sides, the first standing in for Heads
+//
+//	Returns
+//		[]string : the custom sides, or Heads and Tails
+func (coinFlip CoinFlip) labels() []string {
+	if coinFlip.sides == nil {
+		return []string{Heads, Tails}
+	}
+
+	return coinFlip.sides
+}
+
+// Marks of the two sides in the results, the first letter of each label.
+// Labels starting with the same letter are shown in full instead
+//
+//	Ex: {"Win", "Lose"} -> {"W", "L"}
+//	Ex: {"High", "Hold"} -> {"High", "Hold"}
+//
+//	Returns
+//		[]string : the mark of each side, in the order of labels
+func (coinFlip CoinFlip) marks() []string {
+	labels := coinFlip.labels()
+	marks := make([]string, len(labels))
+	for i, label := range labels {
+		marks[i] = string([]rune(label)[:1])
+	}
+
+	if marks[0] == marks[1] {
+		return labels
+	}
+
+	return marks
+}
+
+func (coinFlip CoinFlip) execute() (map[string]int, error) {
 	res, err := GenerateProbabilisticEvent(
 		coinFlip.numEvents,
-		[]string{
-			Heads,
-			Tails})
+		coinFlip.labels())
 
 	if err == nil {
 		coinFlip.display(res)
+
+		// Custom sides are not Heads and Tails, keep them out of the coin history
+		if coinFlip.sides == nil {
+			recordRun(CoinEventType, coinFlip.numEvents, res)
+		}
 	}
 
-	return err
+	return res, err
+}
+
+// Flip the coins without printing anything, for use as a library
+//
+//	Params
+//		numFlips int : number of coin flips
+//	Returns
+//		map[string]int : number of Heads and Tails
+//		error          : any errors encountered during validation
+func FlipDistribution(numFlips int) (map[string]int, error) {
+	coinFlip := NewCoinFlip(numFlips)
+	ok, err := validateAll(coinFlip)
+	if !ok {
+		return nil, err
+	}
+
+	return GenerateProbabilisticEvent(numFlips, []string{Heads, Tails})
 }
 
 // Exposed endpoint to execute one coin flip and
@@ -75,7 +166,7 @@ func (coinFlip CoinFlip) execute() error {
 func DisplayOneFlipAction() int {
 	res := ExecuteOneFlipAction()
 
-	fmt.Print(coinVisuals[res])
+	fmt.Fprint(Output, coinVisual(res))
 	return res
 }
 
@@ -89,28 +180,133 @@ func ExecuteOneFlipAction() int {
 		outcomes: []string{
 			Heads,
 			Tails},
-		prng: randNumGen}
+		prng: RandNumGen}
 
 	return pe.getProbValue()
 }
 
-// Print the coin flip results. Example:
+// Print the coin flip results followed by how they compare to a fair coin,
+// each side marked by the first letter of its label. Example:
+//
+// numEvents: 10
+//
+// Face :   Observed   :   Expected   : Count
 //
-// numEvents: 123435
+// (H)  :  40.000000%  :  50.000000%  : 4
 //
-// (H) :  49.882126% : 61572
+// (T)  :  60.000000%  :  50.000000%  : 6
 //
-// (T) :  50.117874% : 61863
+// Expected  : 5.000000 per face
+//
+// Deviation : (H) -1.000000 (T) +1.000000
+//
+// Variance  : 0.266667 (theoretical 0.250000)
 //
 //	Params
 //		res map[string]int : results of coin flips
 func (coinFlip CoinFlip) display(res map[string]int) {
-	fmt.Printf(
-		"(H) : %10f%% : %d\n(T) : %10f%% : %d\n",
-		Percent(res[Heads], coinFlip.numEvents), res[Heads],
-		Percent(res[Tails], coinFlip.numEvents), res[Tails])
+	labels, marks := coinFlip.labels(), coinFlip.marks()
+
+	// Full labels are wider than the single letter marks
+	width := 4
+	for _, mark := range marks {
+		width = max(width, utf8.RuneCountInString(mark)+2)
+	}
+
+	fmt.Fprintf(Output, "%-*s :   Observed   :   Expected   : Count\n", width, "Face")
+	for i, face := range labels {
+		fmt.Fprintf(
+			Output,
+			"%-*s : %10.*f%%  : %10.*f%%  : %d\n",
+			width, "("+marks[i]+")",
+			Precision, Percent(res[face], coinFlip.numEvents),
+			Precision, ExpectedPercent(2),
+			res[face],
+		)
+	}
+
+	fmt.Fprint(Output, "\n")
+
+	expected := float64(coinFlip.numEvents) / 2
+	fmt.Fprintf(Output, "Expected  : %f per face\n", expected)
+	fmt.Fprintf(
+		Output,
+		"Deviation : (%s) %+f (%s) %+f\n",
+		marks[0], float64(res[labels[0]])-expected,
+		marks[1], float64(res[labels[1]])-expected)
+
+	if variance, ok := FlipVariance(res[labels[0]], coinFlip.numEvents); ok {
+		fmt.Fprintf(Output, "Variance  : %f (theoretical %f)\n\n", variance, TheoreticalFlipVariance)
+	} else {
+		fmt.Fprint(Output, "Variance  : n/a for a single flip\n\n")
+	}
+
+	faces := map[string]int{labels[0]: res[labels[0]], labels[1]: res[labels[1]]}
+	displayChiSquare(faces, coinFlip.numEvents)
+	displayTopOutcome(faces, coinFlip.numEvents)
+}
+
+// Make sure the number of flips and heads of ProbExactHeads are valid
+//
+//	Params
+//		flips int : number of coin flips
+//		heads int : number of heads hoped for
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateExactHeads(flips int, heads int) error {
+	if flips < 1 {
+		return ErrInvalidEvents
+	}
+
+	if heads < 0 || heads > flips {
+		return fmt.Errorf("%w: must be in range [0,%d]", ErrInvalidHeads, flips)
+	}
+
+	return nil
+}
+
+// Exact probability of a fair coin landing on Heads exactly the given
+// number of times: C(flips, heads) / 2^flips. Computed in log space so that
+// large numbers of flips do not overflow. Invalid arguments, see
+// ValidateExactHeads, have probability 0
+//
+//	Ex: 3 heads in 5 flips -> 10 / 32 = 0.3125
+//
+//	Params
+//		flips int : number of coin flips
+//		heads int : number of heads hoped for
+//	Returns
+//		float64 : probability in [0, 1]
+func ProbExactHeads(flips int, heads int) float64 {
+	if ValidateExactHeads(flips, heads) != nil {
+		return 0
+	}
+
+	lgamma := func(n int) float64 {
+		v, _ := math.Lgamma(float64(n + 1))
+		return v
+	}
+
+	return math.Exp(lgamma(flips) - lgamma(heads) - lgamma(flips-heads) - float64(flips)*math.Ln2)
+}
+
+// Sample variance of the observed flips, counting heads as 1 and tails as 0
+//
+//	Params
+//		heads int     : number of heads observed
+//		numEvents int : number of flips
+//	Returns
+//		float64 : n / (n - 1) * p * (1 - p) where p is the observed heads ratio
+//		bool    : false if there are too few flips for a sample variance
+func FlipVariance(heads int, numEvents int) (float64, bool) {
+	if numEvents < 2 {
+		return 0, false
+	}
 
-	fmt.Print("\n")
+	n := float64(numEvents)
+	p := float64(heads) / n
+
+	return n / (n - 1) * p * (1 - p), true
 }
 
 // Retrieve number of events
@@ -120,3 +316,174 @@ func (coinFlip CoinFlip) display(res map[string]int) {
 func (coinFlip CoinFlip) getNumEvents() int {
 	return coinFlip.numEvents
 }
+
+// Flip the coins in order and record the observed percent of heads after
+// each checkpoint of the run
+//
+//	Params
+//		checkpoints []float64 : ascending fractions of numEvents (0, 1]
+//	Returns
+//		[]float64 : observed heads percent at each checkpoint
+func (coinFlip CoinFlip) checkpointedHeadsPercent(checkpoints []float64) []float64 {
+	return coinFlip.headsPercentAtFlips(coinFlip.checkpointFlips(checkpoints))
+}
+
+// Flip the coins in order and record the observed percent of heads once
+// each number of flips is reached, see LogCheckpoints
+//
+//	Params
+//		flips []int : ascending numbers of flips, at most numEvents
+//	Returns
+//		[]float64 : observed heads percent at each number of flips
+func (coinFlip CoinFlip) headsPercentAtFlips(flips []int) []float64 {
+	pe := ProbEvent{
+		numEvents: 1,
+		outcomes: []string{
+			Heads,
+			Tails},
+		prng: coinFlip.prng}
+
+	percents := make([]float64, 0, len(flips))
+	flipped, heads := 0, 0
+
+	for _, checkpoint := range flips {
+		for flipped < checkpoint {
+			if pe.getProbValue() == H {
+				heads++
+			}
+			flipped++
+		}
+
+		percents = append(percents, float64(Percent(heads, flipped)))
+	}
+
+	return percents
+}
+
+// Number of flips reached at each of the given fractions of the run
+//
+//	Params
+//		checkpoints []float64 : ascending fractions of numEvents (0, 1]
+//	Returns
+//		[]int : number of flips at each checkpoint
+func (coinFlip CoinFlip) checkpointFlips(checkpoints []float64) []int {
+	flips := make([]int, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		flips = append(flips, checkpointEvents(checkpoint, coinFlip.numEvents))
+	}
+
+	return flips
+}
+
+// Logarithmic checkpoints of a run, every power of 10 below the number of
+// events followed by the number of events itself
+//
+//	Ex: 2500 -> [10, 100, 1000, 2500]
+//	Ex: 1000 -> [10, 100, 1000]
+//	Ex: 7    -> [7]
+//
+//	Params
+//		numEvents int : total number of events
+//	Returns
+//		[]int : ascending numbers of events
+func LogCheckpoints(numEvents int) []int {
+	checkpoints := []int{}
+	for checkpoint := 10; checkpoint < numEvents; checkpoint *= 10 {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	return append(checkpoints, numEvents)
+}
+
+// Number of events reached at the given checkpoint, always at least one
+// event and at most all of them
+//
+//	Params
+//		checkpoint float64 : fraction of numEvents
+//		numEvents int      : total number of events
+//	Returns
+//		int : number of events at the checkpoint
+func checkpointEvents(checkpoint float64, numEvents int) int {
+	events := int(math.Ceil(checkpoint * float64(numEvents)))
+
+	return min(max(events, 1), numEvents)
+}
+
+// Print the convergence table of the observed heads percent against the
+// theoretical percent. Example:
+//
+// numEvents: 10
+//
+// Flips      :   Observed   :  Theoretical
+//
+// 1          : 100.000000%  :  50.000000%
+//
+// 5          :  60.000000%  :  50.000000%
+//
+// 10         :  40.000000%  :  50.000000%
+//
+//	Params
+//		checkpoints []int  : ascending numbers of flips
+//		percents []float64 : observed heads percent at each checkpoint
+func (coinFlip CoinFlip) displayConvergence(checkpoints []int, percents []float64) {
+	printConvergence(checkpoints, percents, TheoreticalHeadsPercent)
+}
+
+// Print the convergence table of the observed heads percent against the
+// given theoretical percent, see CoinFlip.displayConvergence
+//
+//	Params
+//		checkpoints []int   : ascending numbers of flips
+//		percents []float64  : observed heads percent at each checkpoint
+//		theoretical float64 : percent of heads the run converges towards
+func printConvergence(checkpoints []int, percents []float64, theoretical float64) {
+	fmt.Fprintf(Output, "%-10s :   Observed   :  Theoretical\n", "Flips")
+	for i, checkpoint := range checkpoints {
+		fmt.Fprintf(
+			Output,
+			"%-10d : %10.*f%%  : %10.*f%%\n",
+			checkpoint,
+			Precision, percents[i],
+			Precision, theoretical)
+	}
+
+	fmt.Fprint(Output, "\n")
+}
+
+// Convergence checkpoints of a run of nEvents flips, the DefaultCheckpoints
+// or every power of 10 flips if LogConvergence is set
+//
+//	Params
+//		nEvents int : number of flips in the run
+//	Returns
+//		[]int : ascending numbers of flips
+func convergenceCheckpoints(nEvents int) []int {
+	if LogConvergence {
+		return LogCheckpoints(nEvents)
+	}
+
+	return CoinFlip{numEvents: nEvents}.checkpointFlips(DefaultCheckpoints)
+}
+
+// Exposed endpoint to flip the coins and print the convergence table of
+// the observed heads percent at the DefaultCheckpoints, or at every power
+// of 10 flips if LogConvergence is set
+//
+//	Params
+//		nEvents int : number of CoinFlip events
+//	Returns
+//		float64 : observed heads percent after every flip
+//		error   : any errors encountered during validation
+func ExecuteConvergence(nEvents int) (float64, error) {
+	coinFlip := NewCoinFlip(nEvents)
+	ok, err := validate(coinFlip)
+	if !ok {
+		return 0, err
+	}
+
+	checkpoints := convergenceCheckpoints(nEvents)
+	percents := coinFlip.headsPercentAtFlips(checkpoints)
+	coinFlip.displayConvergence(checkpoints, percents)
+
+	return percents[len(percents)-1], nil
+}
@@ -0,0 +1,114 @@
+/*
+color.go
+
+Optional ANSI color for the coin and
+dice visuals
+*/
+package probgen
+
+import (
+	"os"
+	"strings"
+)
+
+// ANSI escape codes
+const (
+	ColorReset  = "\033[0m"
+	ColorYellow = "\033[33m"
+	ColorCyan   = "\033[36m"
+	ColorRed    = "\033[1;31m"
+)
+
+// Color of each coin face
+var coinColors = map[int]string{
+	H: ColorYellow,
+	T: ColorCyan,
+}
+
+// Color of the pips on the dice
+const PipColor = ColorRed
+
+// Whether the visuals are printed in color. Off unless stdout is a
+// terminal and NO_COLOR is unset, so piped and captured output stays plain
+var UseColor = colorSupported()
+
+// Check whether color should be used by default
+//
+//	Returns
+//		bool : true if NO_COLOR is unset or empty and stdout is a terminal
+func colorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Color every line of the text, unless color is disabled
+//
+//	Params
+//		text string  : text to color
+//		color string : ANSI color code
+//	Returns
+//		string : colored text, or text unchanged if color is disabled
+func colorize(text string, color string) string {
+	if !UseColor {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = color + line + ColorReset
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Color each occurrence of target within the text, unless color is
+// disabled
+//
+//	Params
+//		text string   : text to search
+//		target string : substring to color. Ex: "o" for dice pips
+//		color string  : ANSI color code
+//	Returns
+//		string : highlighted text, or text unchanged if color is disabled
+func highlight(text string, target string, color string) string {
+	if !UseColor {
+		return text
+	}
+
+	return strings.ReplaceAll(text, target, color+target+ColorReset)
+}
+
+// Visual of a coin face, in color if enabled
+//
+//	Params
+//		res int : coin flip value 0:"Heads" or 1:"Tails"
+//	Returns
+//		string : the coin visual
+func coinVisual(res int) string {
+	return colorize(coinVisuals[res], coinColors[res])
+}
+
+// Visual of a 6 sided dice face, with colored pips if enabled. A single
+// glyph line when UseGlyphs is set
+//
+//	Params
+//		res int : dice value 0 -> 5
+//	Returns
+//		string : the dice visual
+func d6Visual(res int) string {
+	if UseGlyphs {
+		return colorize(d6Glyphs[res]+"\n", PipColor)
+	}
+
+	return highlight(d6Visuals[res], "o", PipColor)
+}
@@ -9,11 +9,23 @@ package probgen
 import (
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-const ErrInvalidDiceType = "invalid number of dice sides: must be one of " + ValidDiceTypes
-const ErrUnsupportedDiceType = "unsupported dice type, only support D6 for now"
+var ErrInvalidDiceType = errors.New("invalid number of dice sides: must be one of " + ValidDiceTypes)
+var ErrUnsupportedDiceType = errors.New("unsupported dice type, only support D6 for now")
+var ErrInvalidFaces = errors.New("invalid custom dice: must have at least one face")
+var ErrDuplicateFace = errors.New("invalid custom dice: duplicate face")
+var ErrInvalidTargetFace = errors.New("invalid target face")
+var ErrInvalidMaxRolls = errors.New("invalid maximum number of rolls: must be at least one roll")
+var ErrInvalidAdvantage = errors.New("invalid input: expected 'a' for advantage or 'd' for disadvantage")
+var ErrInvalidKeep = errors.New("invalid number of dice kept")
+var ErrInvalidTrials = errors.New("invalid number of trials: must be at least one trial")
+var ErrInvalidThreshold = errors.New("invalid success threshold")
 
 // Potential dice types
 const (
@@ -27,14 +39,8 @@ const (
 // Valid dice types string
 const ValidDiceTypes = "(4, 6, 10, 12, 20)"
 
-// All possible dice values
-var dicePossibleValues = []string{
-	"1", "2", "3", "4", // D4
-	"5", "6", // -> D6
-	"7", "8", "9", "10", // -> D10
-	"11", "12", // -> D12
-	"13", "14", "15", "16", "17", "18", "19", "20", // -> D20
-}
+// Every supported dice type in ascending order, see validDiceType
+var diceTypes = []int{D4, D6, D10, D12, D20}
 
 const (
 	r1  = iota // 0
@@ -93,6 +99,20 @@ var d6Visuals = map[int]string{
 		" -------\n",
 }
 
+// Compact Unicode die faces of 6 sided dice
+var d6Glyphs = map[int]string{
+	r1: "⚀",
+	r2: "⚁",
+	r3: "⚂",
+	r4: "⚃",
+	r5: "⚄",
+	r6: "⚅",
+}
+
+// Whether 6 sided dice are drawn as a single Unicode glyph instead of the
+// ASCII art, and their faces prefixed with it in the results
+var UseGlyphs = false
+
 type DiceRoll struct {
 	numEvents int // number of coin flips
 	numSides  int // number of sides on dice
@@ -115,22 +135,454 @@ func NewDiceRoll(nEvents int, nSides int) *DiceRoll {
 func (diceRoll DiceRoll) validate() (bool, error) {
 	//  Need to make sure the provided dice type is valid
 	if !validDiceType(diceRoll.numSides) {
-		return false, errors.New(ErrInvalidDiceType)
+		return false, ErrInvalidDiceType
 	}
 
 	return true, nil
 }
 
-func (diceRoll DiceRoll) execute() error {
+func (diceRoll DiceRoll) execute() (map[string]int, error) {
 	res, err := GenerateProbabilisticEvent(
 		diceRoll.numEvents,
 		possibleDiceValues(diceRoll.numSides))
 
 	if err == nil {
 		diceRoll.display(res)
+		recordRun(DiceEventType(diceRoll.numSides), diceRoll.numEvents, res)
+	}
+
+	return res, err
+}
+
+// Roll the dice without printing anything, for use as a library
+//
+//	Params
+//		numRolls int : number of dice rolls
+//		numSides int : number of sides to the dice
+//	Returns
+//		map[string]int : number of times each face was rolled
+//		error          : any errors encountered during validation
+func RollDistribution(numRolls int, numSides int) (map[string]int, error) {
+	diceRoll := NewDiceRoll(numRolls, numSides)
+	ok, err := validateAll(diceRoll)
+	if !ok {
+		return nil, err
+	}
+
+	return GenerateProbabilisticEvent(numRolls, possibleDiceValues(numSides))
+}
+
+// Make sure the dice, target face and maximum number of rolls of RollUntil
+// are valid
+//
+//	Params
+//		nSides int     : number of sides for the die
+//		targetFace int : face to roll until
+//		maxRolls int   : maximum number of rolls
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateRollUntil(nSides int, targetFace int, maxRolls int) error {
+	if !validDiceType(nSides) {
+		return ErrInvalidDiceType
+	}
+
+	if targetFace < 1 || targetFace > nSides {
+		return fmt.Errorf("%w: must be in range [1,%d]", ErrInvalidTargetFace, nSides)
+	}
+
+	if maxRolls < 1 {
+		return ErrInvalidMaxRolls
+	}
+
+	return nil
+}
+
+// Roll the die until the target face shows or the maximum number of rolls
+// is reached. Invalid arguments, see ValidateRollUntil, roll nothing
+//
+//	Params
+//		nSides int         : number of sides for the die
+//		targetFace int     : face to roll until, in the range [1, nSides]
+//		maxRolls int       : maximum number of rolls
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int  : number of rolls made
+//		bool : true if the target face was rolled
+func RollUntil(nSides int, targetFace int, maxRolls int, prng func(int) int) (rolls int, hit bool) {
+	if ValidateRollUntil(nSides, targetFace, maxRolls) != nil {
+		return 0, false
+	}
+
+	for rolls < maxRolls {
+		rolls++
+		if prng(nSides)+1 == targetFace {
+			return rolls, true
+		}
+	}
+
+	return rolls, false
+}
+
+// Exposed endpoint to roll the die until the target face shows, or the
+// maximum number of rolls is reached, and print how many rolls it took
+//
+//	Params
+//		nSides int     : number of sides for the die
+//		targetFace int : face to roll until
+//		maxRolls int   : maximum number of rolls
+//	Returns
+//		int   : number of rolls made
+//		bool  : true if the target face was rolled
+//		error : any errors encountered during validation
+func ExecuteRollUntil(nSides int, targetFace int, maxRolls int) (int, bool, error) {
+	if err := ValidateRollUntil(nSides, targetFace, maxRolls); err != nil {
+		return 0, false, err
+	}
+
+	rolls, hit := RollUntil(nSides, targetFace, maxRolls, RandNumGen)
+	if hit {
+		fmt.Fprintf(Output, "Rolled a %d on roll %d\n\n", targetFace, rolls)
+	} else {
+		fmt.Fprintf(Output, "No %d in %d rolls\n\n", targetFace, rolls)
+	}
+
+	return rolls, hit, nil
+}
+
+// Roll the die a number of times keeping every result in the order rolled,
+// unlike computeProbability which only keeps the totals
+//
+//	Params
+//		nSides int         : number of sides for the die
+//		nRolls int         : number of rolls
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		[]int : every roll in order, each in the range [1, nSides]
+//		error : any errors encountered during validation
+func ExecuteRollSequence(nSides int, nRolls int, prng func(int) int) ([]int, error) {
+	ok, err := validateAll(NewDiceRoll(nRolls, nSides))
+	if !ok {
+		return nil, err
+	}
+
+	rolls := make([]int, nRolls)
+	for i := range rolls {
+		rolls[i] = prng(nSides) + 1
+	}
+
+	return rolls, nil
+}
+
+// Join the rolls of a sequence for display
+//
+//	Params
+//		rolls []int : rolls in order
+//	Returns
+//		string : comma separated rolls. Ex: "4, 1, 6, 6, 2"
+func FormatSequence(rolls []int) string {
+	rolls_s := make([]string, len(rolls))
+	for i, roll := range rolls {
+		rolls_s[i] = strconv.Itoa(roll)
+	}
+
+	return strings.Join(rolls_s, ", ")
+}
+
+// Lowest and highest roll of a sequence. An empty sequence has no rolls,
+// both are 0
+//
+//	Ex: {4, 1, 6, 6, 2} -> 1, 6
+//
+//	Params
+//		rolls []int : rolls in order
+//	Returns
+//		int : lowest roll
+//		int : highest roll
+func MinMax(rolls []int) (min int, max int) {
+	if len(rolls) == 0 {
+		return 0, 0
+	}
+
+	return slices.Min(rolls), slices.Max(rolls)
+}
+
+// Make sure the dice pool and the number of dice kept are valid
+//
+//	Params
+//		nDice int       : number of dice in the pool
+//		nSides int      : number of sides for each die
+//		keepHighest int : number of dice kept
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidatePool(nDice int, nSides int, keepHighest int) error {
+	if nDice < 1 {
+		return ErrInvalidNumDice
+	}
+
+	if !validDiceType(nSides) {
+		return ErrInvalidDiceType
+	}
+
+	if keepHighest < 1 || keepHighest > nDice {
+		return fmt.Errorf("%w: must be in range [1,%d]", ErrInvalidKeep, nDice)
+	}
+
+	return nil
+}
+
+// Roll a pool of dice and keep the highest, such as 4d6 drop the lowest.
+// Invalid arguments, see ValidatePool, roll nothing
+//
+//	Params
+//		nDice int          : number of dice in the pool
+//		nSides int         : number of sides for each die
+//		keepHighest int    : number of highest dice kept
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		[]int : kept dice, highest first
+//		[]int : dropped dice, highest first
+//		int   : sum of the kept dice
+func RollPool(nDice int, nSides int, keepHighest int, prng func(int) int) (kept []int, dropped []int, total int) {
+	if ValidatePool(nDice, nSides, keepHighest) != nil {
+		return nil, nil, 0
+	}
+
+	pool := make([]int, nDice)
+	for i := range pool {
+		pool[i] = prng(nSides) + 1
+	}
+
+	// Highest first
+	slices.Sort(pool)
+	slices.Reverse(pool)
+
+	kept, dropped = pool[:keepHighest], pool[keepHighest:]
+	for _, die := range kept {
+		total += die
+	}
+
+	return kept, dropped, total
+}
+
+// Make sure the dice pool and the success threshold are valid, with at
+// most as many dice in the pool as events in a run, see SetMaxEvents
+//
+//	Params
+//		nDice int     : number of dice in the pool
+//		nSides int    : number of sides for each die
+//		threshold int : lowest face counted as a success
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateSuccesses(nDice int, nSides int, threshold int) error {
+	if nDice < 1 {
+		return ErrInvalidNumDice
+	}
+
+	if nDice > maxEvents {
+		return tooManyEvents()
+	}
+
+	if !validDiceType(nSides) {
+		return ErrInvalidDiceType
+	}
+
+	if threshold < 1 || threshold > nSides {
+		return fmt.Errorf("%w: must be in range [1,%d]", ErrInvalidThreshold, nSides)
+	}
+
+	return nil
+}
+
+// Roll a pool of dice and count the dice at or above the threshold as
+// successes, as in many dice pool games. Invalid arguments, see
+// ValidateSuccesses, roll nothing
+//
+//	Ex: 5d10 with threshold 8 rolling 3, 8, 10, 7, 1 -> 2 successes
+//
+//	Params
+//		nDice int          : number of dice in the pool
+//		nSides int         : number of sides for each die
+//		threshold int      : lowest face counted as a success
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int   : number of dice at or above the threshold
+//		[]int : every die in the order rolled
+func CountSuccesses(nDice int, nSides int, threshold int, prng func(int) int) (successes int, rolls []int) {
+	if ValidateSuccesses(nDice, nSides, threshold) != nil {
+		return 0, nil
+	}
+
+	rolls = make([]int, nDice)
+	for i := range rolls {
+		rolls[i] = prng(nSides) + 1
+		if rolls[i] >= threshold {
+			successes++
+		}
+	}
+
+	return successes, rolls
+}
+
+// Roll two D20 for an advantage or disadvantage roll
+//
+//	Params
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int : first D20 value in the range [1, 20]
+//		int : second D20 value in the range [1, 20]
+func RollD20Pair(prng func(int) int) (int, int) {
+	return prng(D20) + 1, prng(D20) + 1
+}
+
+// Roll two D20 and keep the higher
+//
+//	Params
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int : the higher D20 value
+func RollAdvantage(prng func(int) int) int {
+	return max(RollD20Pair(prng))
+}
+
+// Roll two D20 and keep the lower
+//
+//	Params
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int : the lower D20 value
+func RollDisadvantage(prng func(int) int) int {
+	return min(RollD20Pair(prng))
+}
+
+// Exposed endpoint to roll a D20 with advantage or disadvantage and print
+// both raw rolls and the kept result
+//
+//	Params
+//		advantage bool : true keeps the higher roll, false the lower
+//	Returns
+//		int : first D20 value
+//		int : second D20 value
+//		int : kept D20 value
+func ExecuteAdvantageRoll(advantage bool) (int, int, int) {
+	roll1, roll2 := RollD20Pair(RandNumGen)
+	kept := min(roll1, roll2)
+	if advantage {
+		kept = max(roll1, roll2)
+	}
+
+	fmt.Fprintf(Output, "Rolled %d and %d, keeping %d\n\n", roll1, roll2, kept)
+
+	return roll1, roll2, kept
+}
+
+// Make sure the die of a check is valid
+//
+//	Params
+//		nSides int : number of sides for the die
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateCheck(nSides int) error {
+	if !validDiceType(nSides) {
+		return ErrInvalidDiceType
+	}
+
+	return nil
+}
+
+// Outcome of a check against a difficulty class
+type CheckResult struct {
+	Roll      int  // the die value before the modifier
+	Total     int  // the roll plus the modifier
+	Success   bool // the total meets or beats the DC
+	Natural1  bool // a D20 rolled a 1, a critical fail whatever the modifier
+	Natural20 bool // a D20 rolled a 20, a critical success whatever the modifier
+}
+
+// Roll one die against a difficulty class, such as a D20 ability check. The
+// check succeeds when the roll plus the modifier meets or beats the DC. A
+// D20 also flags a natural 1 or 20 from the unmodified roll. An invalid die,
+// see ValidateCheck, rolls nothing
+//
+//	Ex: roll 12, modifier +3, DC 15 -> total 15, success
+//
+//	Params
+//		nSides int         : number of sides for the die
+//		modifier int       : added to the roll, may be negative
+//		dc int             : difficulty class the total must reach
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		CheckResult : the roll, the total and the outcome
+func RollCheck(nSides int, modifier int, dc int, prng func(int) int) CheckResult {
+	if ValidateCheck(nSides) != nil {
+		return CheckResult{}
+	}
+
+	roll := prng(nSides) + 1
+	total := roll + modifier
+
+	return CheckResult{
+		Roll:      roll,
+		Total:     total,
+		Success:   total >= dc,
+		Natural1:  nSides == D20 && roll == 1,
+		Natural20: nSides == D20 && roll == D20,
+	}
+}
+
+// Make sure the die, the target face and the number of trials are valid
+//
+//	Params
+//		nSides int     : number of sides for the die
+//		targetFace int : face hoped for
+//		nTrials int    : number of rolls
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateAtLeastOne(nSides int, targetFace int, nTrials int) error {
+	if !validDiceType(nSides) {
+		return ErrInvalidDiceType
+	}
+
+	if targetFace < 1 || targetFace > nSides {
+		return fmt.Errorf("%w: must be in range [1,%d]", ErrInvalidTargetFace, nSides)
 	}
 
-	return err
+	if nTrials < 1 {
+		return ErrInvalidTrials
+	}
+
+	return nil
+}
+
+// Exact probability of rolling the target face at least once in a number
+// of rolls: 1 - ((nSides-1)/nSides)^nTrials. Invalid arguments, see
+// ValidateAtLeastOne, have probability 0
+//
+//	Ex: a 6 in 4 rolls of a D6 -> 1 - (5/6)^4 = 0.517747
+//
+//	Params
+//		nSides int     : number of sides for the die
+//		targetFace int : face hoped for
+//		nTrials int    : number of rolls
+//	Returns
+//		float64 : probability in [0, 1]
+func ProbAtLeastOne(nSides int, targetFace int, nTrials int) float64 {
+	if ValidateAtLeastOne(nSides, targetFace, nTrials) != nil {
+		return 0
+	}
+
+	miss := float64(nSides-1) / float64(nSides)
+
+	return 1 - math.Pow(miss, float64(nTrials))
+}
+
+// Event type of dice roll runs in the run history
+//
+//	Params
+//		nSides int : number of sides for the die
+//	Returns
+//		string : dice type. Ex: "D6"
+func DiceEventType(nSides int) string {
+	return "D" + strconv.Itoa(nSides)
 }
 
 // Exposed endpoint to execute one dice roll and
@@ -143,15 +595,34 @@ func (diceRoll DiceRoll) execute() error {
 //	Returns
 //		int : result of dice roll
 func ExecuteAndDisplayOneRollAction(nSides int) int {
-	res := ExecuteOneRollAction(nSides)
+	return ExecuteAndDisplayOneRollActionWith(nSides, RandNumGen)
+}
+
+// Exposed endpoint to execute one dice roll with the given PRNG and
+// print out a visual of the result
+//
+// NOTE: not all dice types are supported yet
+//
+//	Params
+//		nSides int           : indicate the number of sides for the dice
+//		prng func(int) int   : the Pseudo Random Number Generator to use
+//	Returns
+//		int : result of dice roll
+func ExecuteAndDisplayOneRollActionWith(nSides int, prng func(int) int) int {
+	pe := ProbEvent{
+		numEvents: 1,
+		outcomes:  possibleDiceValues(nSides),
+		prng:      prng}
+
+	res := pe.getProbValue()
 
 	// Only support D6 for now
 	switch nSides {
 	case D6:
-		fmt.Print(d6Visuals[res])
+		fmt.Fprint(Output, d6Visual(res))
 		return res
 	default:
-		fmt.Print(ErrUnsupportedDiceType)
+		fmt.Fprint(Output, ErrUnsupportedDiceType)
 		return -1
 	}
 }
@@ -163,41 +634,130 @@ func ExecuteAndDisplayOneRollAction(nSides int) int {
 //	Returns
 //		int : dice value 0 -> nSides - 1
 func ExecuteOneRollAction(nSides int) int {
+	return ExecuteOneRollActionWith(nSides, RandNumGen)
+}
+
+// One dice roll action with the given PRNG
+//
+//	Params
+//		nSides int         : number of sides for the die
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int : dice value 0 -> nSides - 1
+func ExecuteOneRollActionWith(nSides int, prng func(int) int) int {
 	pe := ProbEvent{
 		numEvents: 1,
 		outcomes:  possibleDiceValues(nSides),
-		prng:      randNumGen}
+		prng:      prng}
 
 	return pe.getProbValue()
 }
 
+// Roll a D6 along with the faces it tumbles through before landing, so a
+// caller can print each frame with a small delay. Every frame is a separate
+// roll and the last one is the result
+//
+//	Params
+//		frames int         : number of frames, at least 1. Ex: 8
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int      : the D6 value in the range [1, 6]
+//		[]string : visual of every frame in order, ending on the result
+func RollD6Animated(frames int, prng func(int) int) (result int, frameSequence []string) {
+	frames = max(frames, 1)
+
+	frameSequence = make([]string, 0, frames)
+	for range frames {
+		result = ExecuteOneRollActionWith(D6, prng) + 1
+		frameSequence = append(frameSequence, d6Visual(result-1))
+	}
+
+	return result, frameSequence
+}
+
 // Print the dice roll results. Example:
 //
 // numEvents: 2
 //
 // numSides: 4
 //
-// [1]  :  50.00000% : 1
+// Face :   Observed   :   Expected   : Count
+//
+// [1]  :  50.000000%  :  25.000000%  : 1
 //
-// [2] :   50.00000% : 1
+// [2]  :  50.000000%  :  25.000000%  : 1
 //
-// [3] :    0.00000% : 0
+// [3]  :   0.000000%  :  25.000000%  : 0
 //
-// [4] :    0.00000% : 0
+// [4]  :   0.000000%  :  25.000000%  : 0
+//
+// A D6 prefixes each face with its glyph when UseGlyphs is set. Ex: "⚀ [1]"
 //
 //	Params
 //		res map[string]int : results of dice rolls
 func (diceRoll DiceRoll) display(res map[string]int) {
+	// Every face counts towards the chi-square, even if never rolled
+	faces := make(map[string]int)
+	expected := ExpectedPercent(diceRoll.numSides)
+	fmt.Fprint(Output, "Face :   Observed   :   Expected   : Count\n")
 	for i := 1; i <= diceRoll.numSides; i++ {
 		i_s := strconv.Itoa(i)
-		fmt.Printf(
-			"%-4s : %10f%% : %d\n",
+		faces[i_s] = res[i_s]
+		if UseGlyphs && diceRoll.numSides == D6 {
+			fmt.Fprintf(Output, "%s ", d6Glyphs[i-1])
+		}
+		fmt.Fprintf(
+			Output,
+			"%-4s : %10.*f%%  : %10.*f%%  : %d\n",
 			"["+i_s+"]",
-			Percent(res[i_s], diceRoll.numEvents),
+			Precision, Percent(res[i_s], diceRoll.numEvents),
+			Precision, expected,
 			res[i_s],
 		)
 	}
-	fmt.Print("\n")
+	fmt.Fprint(Output, "\n")
+
+	if ShowCDF {
+		diceRoll.displayCDF(res)
+	}
+
+	displayChiSquare(faces, diceRoll.numEvents)
+	displayTopOutcome(faces, diceRoll.numEvents)
+}
+
+// Print the percent of rolls less than or equal to each face, in
+// ascending order of the faces. Example:
+//
+// numEvents: 4
+//
+// numSides: 4
+//
+// Cumulative :
+//
+// [1]  :  25.000000%
+//
+// [2]  :  75.000000%
+//
+// [3]  :  75.000000%
+//
+// [4]  : 100.000000%
+//
+//	Params
+//		res map[string]int : results of dice rolls
+func (diceRoll DiceRoll) displayCDF(res map[string]int) {
+	fmt.Fprint(Output, "Cumulative :\n")
+
+	cumulative := 0
+	for _, face := range possibleDiceValues(diceRoll.numSides) {
+		cumulative += res[face]
+		fmt.Fprintf(
+			Output,
+			"%-4s : %10.*f%%\n",
+			"["+face+"]",
+			Precision, Percent(cumulative, diceRoll.numEvents),
+		)
+	}
+	fmt.Fprint(Output, "\n")
 }
 
 // Retrieve number of events
@@ -216,16 +776,26 @@ func (diceRoll DiceRoll) getNumEvents() int {
 //		bool : true if dType in {D4, D6, D10, D12, D20}
 //			   false otherwise
 func validDiceType(dType int) bool {
-	switch dType {
-	case D4, D6, D10, D12, D20:
-		return true
-	default:
-		return false
+	return slices.Contains(diceTypes, dType)
+}
+
+// Describe every supported dice type with the range of its values
+//
+//	Ex: ["D4: 1–4", "D6: 1–6", ..., "D20: 1–20"]
+//
+//	Returns
+//		[]string : one description per dice type, in ascending order
+func ListDiceTypes() []string {
+	list := make([]string, 0, len(diceTypes))
+	for _, dType := range diceTypes {
+		list = append(list, fmt.Sprintf("D%d: 1–%d", dType, dType))
 	}
+
+	return list
 }
 
-// Get all the possible values for the particular type of dice. Do not call
-// without validating first
+// Get all the possible values for the particular type of dice, numbered
+// from 1. Do not call without validating first
 //
 //	Params
 //		dType int : dice type
@@ -238,5 +808,119 @@ func validDiceType(dType int) bool {
 //				D12 [1, 12]
 //				D20 [1, 20]
 func possibleDiceValues(dType int) []string {
-	return dicePossibleValues[:dType]
+	values := make([]string, dType)
+	for i := range values {
+		values[i] = strconv.Itoa(i + 1)
+	}
+
+	return values
+}
+
+type CustomDiceRoll struct {
+	numEvents int      // number of dice rolls
+	faces     []string // all faces of the custom dice in display order
+}
+
+// Initialize private fields
+//
+//	Params
+//		nEvents int     : number of CustomDiceRoll events
+//		faces []string  : every face of the custom dice. Ex: {"+", "-", "0"}
+//	Returns
+//		*CustomDiceRoll : new CustomDiceRoll object
+func NewCustomDiceRoll(nEvents int, faces []string) *CustomDiceRoll {
+	return &CustomDiceRoll{
+		numEvents: nEvents,
+		faces:     faces,
+	}
+}
+
+func (customDiceRoll CustomDiceRoll) validate() (bool, error) {
+	// Any faces are allowed, but there must be at least one
+	if len(customDiceRoll.faces) < 1 {
+		return false, ErrInvalidFaces
+	}
+
+	// Repeated faces would be counted together and skew the expected odds
+	faces := make(map[string]bool)
+	for _, face := range customDiceRoll.faces {
+		if faces[face] {
+			return false, fmt.Errorf("%w '%s'", ErrDuplicateFace, face)
+		}
+
+		faces[face] = true
+	}
+
+	return true, nil
+}
+
+func (customDiceRoll CustomDiceRoll) execute() (map[string]int, error) {
+	res, err := GenerateProbabilisticEvent(
+		customDiceRoll.numEvents,
+		customDiceRoll.faces)
+
+	if err == nil {
+		customDiceRoll.display(res)
+	}
+
+	return res, err
+}
+
+// Width of the face column, wide enough for the longest label so that
+// labelled faces line up like numeric ones
+//
+//	Returns
+//		int : character count of the widest "[face]", at least 4
+func (customDiceRoll CustomDiceRoll) faceWidth() int {
+	width := 4
+	for _, face := range customDiceRoll.faces {
+		width = max(width, utf8.RuneCountInString(face)+2)
+	}
+
+	return width
+}
+
+// Print the custom dice roll results in the order of the faces, padding every
+// face to the longest label. Example:
+//
+// numEvents: 4
+//
+// faces: {"+", "-", "0"}
+//
+// [+]  :  50.000000% : 2
+//
+// [-]  :  25.000000% : 1
+//
+// [0]  :  25.000000% : 1
+//
+// faces: {"crit", "hit", "miss"}
+//
+// [crit] :  25.000000% : 1
+//
+// [hit]  :  50.000000% : 2
+//
+// [miss] :  25.000000% : 1
+//
+//	Params
+//		res map[string]int : results of custom dice rolls
+func (customDiceRoll CustomDiceRoll) display(res map[string]int) {
+	width := customDiceRoll.faceWidth()
+	for _, face := range customDiceRoll.faces {
+		fmt.Fprintf(
+			Output,
+			"%-*s : %10.*f%% : %d\n",
+			width, "["+face+"]",
+			Precision, Percent(res[face], customDiceRoll.numEvents),
+			res[face],
+		)
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Retrieve number of events
+//
+//	Returns
+//		int : number of events
+func (customDiceRoll CustomDiceRoll) getNumEvents() int {
+	return customDiceRoll.numEvents
 }
@@ -0,0 +1,163 @@
+/*
+mixedpool.go
+
+Rolls of several groups of dice with
+different numbers of sides at once,
+written in dice notation. Ex: 1d20+2d6
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidNotation = errors.New("invalid dice notation")
+
+// One group of a mixed pool, all dice of the same type
+//
+//	Ex: {2, D6} is 2d6
+type DiceSpec struct {
+	Count int // number of dice in the group
+	Sides int // number of sides for each die, see ValidDiceTypes
+}
+
+// Write the group in dice notation
+//
+//	Ex: {2, D6} -> "2d6"
+func (spec DiceSpec) String() string {
+	return fmt.Sprintf("%dd%d", spec.Count, spec.Sides)
+}
+
+// Parse dice notation into the groups of a mixed pool, in the order written.
+// A group without a count has a single die. Letter case and spaces are
+// ignored
+//
+//	Ex: "1d20+2d6" -> [{1, 20}, {2, 6}]
+//	Ex: "d20 + d4" -> [{1, 20}, {1, 4}]
+//
+//	Params
+//		notation string : groups joined by '+', each <count>d<sides>
+//	Returns
+//		[]DiceSpec : the groups, not yet validated, see ValidateMixedPool
+//		error      : ErrInvalidNotation if a group is not <count>d<sides>
+func ParseNotation(notation string) ([]DiceSpec, error) {
+	dice := []DiceSpec{}
+	for _, group := range strings.Split(notation, "+") {
+		group = strings.ToLower(strings.TrimSpace(group))
+
+		count_s, sides_s, ok := strings.Cut(group, "d")
+		if !ok {
+			return nil, invalidNotation(group)
+		}
+
+		count := 1
+		if count_s != "" {
+			var err error
+			if count, err = strconv.Atoi(count_s); err != nil {
+				return nil, invalidNotation(group)
+			}
+		}
+
+		sides, err := strconv.Atoi(sides_s)
+		if err != nil {
+			return nil, invalidNotation(group)
+		}
+
+		dice = append(dice, DiceSpec{Count: count, Sides: sides})
+	}
+
+	return dice, nil
+}
+
+// Wrap ErrInvalidNotation with the group that could not be parsed
+//
+//	Params
+//		group string : the group as written. Ex: "2x6"
+//	Returns
+//		error : ErrInvalidNotation with context
+func invalidNotation(group string) error {
+	return fmt.Errorf("%w '%s': expected groups like 1d20+2d6", ErrInvalidNotation, group)
+}
+
+// Make sure every group of a mixed pool can be rolled, with at most as
+// many dice in the pool as events in a run, see SetMaxEvents
+//
+//	Params
+//		dice []DiceSpec : groups of the pool
+//	Returns
+//		error : indicates any errors leading to validation failure
+func ValidateMixedPool(dice []DiceSpec) error {
+	if len(dice) == 0 {
+		return ErrInvalidNumDice
+	}
+
+	numDice := 0
+	for _, spec := range dice {
+		if spec.Count < 1 {
+			return ErrInvalidNumDice
+		}
+
+		if !validDiceType(spec.Sides) {
+			return ErrInvalidDiceType
+		}
+
+		// Compared before adding so that huge counts cannot overflow
+		if spec.Count > maxEvents-numDice {
+			return tooManyEvents()
+		}
+		numDice += spec.Count
+	}
+
+	return nil
+}
+
+// Roll every group of a mixed pool, such as 1d20+2d6. Each group keeps its
+// own rolls, even when another group has the same dice type. Invalid pools,
+// see ValidateMixedPool, roll nothing
+//
+//	Ex: 1d20+2d6 -> [[14], [3, 5]], 22
+//
+//	Params
+//		dice []DiceSpec    : groups of the pool, rolled in order
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		[][]int : rolls of each group in order, parallel to dice
+//		int     : sum of every roll
+func RollMixedPool(dice []DiceSpec, prng func(int) int) (results [][]int, total int) {
+	if ValidateMixedPool(dice) != nil {
+		return nil, 0
+	}
+
+	results = make([][]int, len(dice))
+	for i, spec := range dice {
+		for range spec.Count {
+			roll := prng(spec.Sides) + 1
+			results[i] = append(results[i], roll)
+			total += roll
+		}
+	}
+
+	return results, total
+}
+
+// Describe the rolls of a mixed pool, one group at a time in the order
+// written
+//
+//	Ex: "1d20=[14], 2d6=[3, 5]"
+//
+//	Params
+//		dice []DiceSpec : groups of the pool
+//		results [][]int : rolls of each group, see RollMixedPool
+//	Returns
+//		string : the rolls of each group
+func FormatMixedPool(dice []DiceSpec, results [][]int) string {
+	groups := make([]string, len(dice))
+	for i, spec := range dice {
+		groups[i] = fmt.Sprintf("%s=[%s]", spec, FormatSequence(results[i]))
+	}
+
+	return strings.Join(groups, ", ")
+}
@@ -7,16 +7,68 @@ computation
 package probgen
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 /// Constants
 
-const ErrInvalidEvents = "invalid number of events: must be more than one event"
-const ErrInvalidPossibilities = "invalid number of possibilities: must have at least one possible outcome"
+var ErrInvalidEvents = errors.New("invalid number of events: must be more than one event")
+var ErrInvalidPossibilities = errors.New("invalid number of possibilities: must have at least one possible outcome")
+var ErrTooManyEvents = errors.New("too many events")
+
+// Default largest number of events in a single run, guarding against a
+// mistyped count running for a very long time
+const DefaultMaxEvents = 100000000
+
+// Decimal places of the percentages printed by default
+const DefaultPrecision = 6
+
+// Number of events at which computation is fanned out across workers
+const ParallelThreshold = 1000000
+
+// Largest number of events in a single run
+var maxEvents = DefaultMaxEvents
+
+// Where every display and visual is written. Set to a buffer or file to
+// capture the output
+var Output io.Writer = stdout{}
+
+// Writer to whatever os.Stdout is at the time of writing, so that Output
+// follows a redirected os.Stdout
+type stdout struct{}
+
+func (stdout) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// Print the chi-square statistic below the coin flip and dice roll displays
+var ShowChiSquare = false
+
+// Print the cumulative distribution below the dice roll display
+var ShowCDF = false
+
+// Number of decimal places printed for the percentages of every display
+var Precision = DefaultPrecision
+
+// Called with the results of every completed coin flip and dice roll run
+// when set. Used to persist the run history
+var RecordRun func(eventType string, numEvents int, res map[string]int)
 
 // Generic probability event object
+//
+// NOTE: prng may be called concurrently by multiple workers when
+// numEvents >= ParallelThreshold, so it must be safe for concurrent use
 type ProbEvent struct {
 	numEvents int           // Number of probabilistic events
 	outcomes  []string      // Total possible outcomes of events
@@ -56,9 +108,11 @@ func (pe ProbEvent) produceEvent(out chan string) {
 //		map[string]int : aggregated results of the input channel
 func (pe ProbEvent) consumeEvents(in chan string) map[string]int {
 	results := make(map[string]int)
+	tracker := newProgressTracker(pe.numEvents)
 
 	for event := range in {
 		results[event]++
+		tracker.add(1)
 	}
 
 	return results
@@ -73,6 +127,10 @@ func (pe ProbEvent) consumeEvents(in chan string) map[string]int {
 //		and returns the number of times that outcome
 //		occurred
 func (pe ProbEvent) computeProbability() map[string]int {
+	if pe.numEvents >= ParallelThreshold {
+		return pe.computeProbabilityParallel(runtime.NumCPU())
+	}
+
 	events := make(chan string)
 
 	go pe.produceEvent(events)
@@ -80,17 +138,119 @@ func (pe ProbEvent) computeProbability() map[string]int {
 	return pe.consumeEvents(events)
 }
 
-// Generate a random number bounded by the number of outcomes
+// Compute the probability for a ProbEvent by splitting its events across
+// a number of workers, each aggregating a partial table, and merging the
+// partial tables once all workers are finished
+//
+//	Params
+//		workers int : number of goroutines to split the events across
+//	Returns
+//		map[string]int : aggregation of results into a
+//		table that is indexed by the possible outcomes
+//		and returns the number of times that outcome
+//		occurred
+func (pe ProbEvent) computeProbabilityParallel(workers int) map[string]int {
+	partials := make(chan map[string]int, workers)
+	tracker := newProgressTracker(pe.numEvents)
+	prng := lockedPRNG(pe.prng)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		// Spread the remainder over the first workers
+		nEvents := pe.numEvents / workers
+		if w < pe.numEvents%workers {
+			nEvents++
+		}
+
+		wg.Add(1)
+		go func(worker ProbEvent) {
+			defer wg.Done()
+			partials <- worker.aggregateEvents(tracker)
+		}(ProbEvent{numEvents: nEvents, outcomes: pe.outcomes, prng: prng})
+	}
+
+	wg.Wait()
+	close(partials)
+
+	results := make(map[string]int)
+	for partial := range partials {
+		for outcome, count := range partial {
+			results[outcome] += count
+		}
+	}
+
+	return results
+}
+
+// Generate and aggregate all events directly without a channel. Used by
+// each worker of computeProbabilityParallel
+//
+//	Params
+//		tracker *progressTracker : shared progress of the run, may be nil
+//	Returns
+//		map[string]int : aggregated results of numEvents events
+func (pe ProbEvent) aggregateEvents(tracker *progressTracker) map[string]int {
+	results := make(map[string]int)
+
+	for i := 0; i < pe.numEvents; i++ {
+		results[pe.getProbOutcome(pe.getProbValue())]++
+
+		// Report in batches to keep contention between workers low
+		if (i+1)%progressBatch == 0 {
+			tracker.add(progressBatch)
+		}
+	}
+	tracker.add(pe.numEvents % progressBatch)
+
+	return results
+}
+
+// Generate a random number bounded by the number of outcomes. This is the
+// generator shared by every run, including rolls outside of probgen taking
+// a prng. Ex: RollPool(4, D6, 3, RandNumGen)
 //
 // NOTE: num_outcomes is not zero based, but the possible outcomes
-// are and this is handled by the half open interval: [0, n)
+// are and this is handled by the half open interval: [0, n). This is
+// safe for concurrent use by multiple workers
 //
 //	Returns
 //		int : a number in the range: [0, n)
-func randNumGen(num_outcomes int) int {
+func RandNumGen(num_outcomes int) int {
 	return rand.Intn(num_outcomes)
 }
 
+// Create a Pseudo Random Number Generator from a seed so the same seed
+// always produces the same sequence of numbers
+//
+// NOTE: unlike RandNumGen this is not safe for concurrent use
+//
+//	Params
+//		seed int64 : seed of the generator
+//	Returns
+//		func(int) int : generator of numbers in the range [0, n)
+func NewSeededPRNG(seed int64) func(int) int {
+	return rand.New(rand.NewSource(seed)).Intn
+}
+
+// Guard a Pseudo Random Number Generator with a mutex so every worker of
+// computeProbabilityParallel can share one that is not safe for concurrent
+// use, such as NewSeededPRNG
+//
+//	Params
+//		prng func(int) int : the generator to share
+//	Returns
+//		func(int) int : generator safe for concurrent use
+func lockedPRNG(prng func(int) int) func(int) int {
+	var mu sync.Mutex
+
+	return func(num_outcomes int) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return prng(num_outcomes)
+	}
+}
+
 // Given the number of events and the possible outcomes of the events, return
 // a table of results
 //
@@ -116,35 +276,79 @@ func randNumGen(num_outcomes int) int {
 func GenerateProbabilisticEvent(events int, possibilities []string) (map[string]int, error) {
 	if len(possibilities) < 1 {
 		// Must have at least one possible outcome
-		return nil, errors.New(ErrInvalidPossibilities)
+		return nil, ErrInvalidPossibilities
 	}
 
-	probEvent := ProbEvent{numEvents: events, outcomes: possibilities, prng: randNumGen}
+	probEvent := ProbEvent{numEvents: events, outcomes: possibilities, prng: RandNumGen}
 	return probEvent.computeProbability(), nil
 }
 
 // ProbEvent interface for use in options
 type ProbEventType interface {
-	validate() (bool, error) // Check input is valid
-	execute() error          // Compute and display result
-	display(map[string]int)  // Display results
-	getNumEvents() int       // Retrieve number of events
+	validate() (bool, error)          // Check input is valid
+	execute() (map[string]int, error) // Compute and display result
+	display(map[string]int)           // Display results
+	getNumEvents() int                // Retrieve number of events
 }
 
 func ValidateAndExecute(probEventType ProbEventType) error {
-	// Generic probability event validation
-	ok, err := validate(probEventType)
+	_, err := ValidateAndExecuteResults(probEventType)
+
+	return err
+}
+
+// Validate and execute the probability event, reporting the events
+// consumed so far to progress during the run instead of to Progress
+//
+//	Params
+//		probEventType ProbEventType       : probability event to run
+//		progress func(consumed, total int) : called every ProgressInterval
+//	Returns
+//		map[string]int : aggregated results, nil when invalid
+//		error          : any errors encountered
+func ValidateAndExecuteWithProgress(
+	probEventType ProbEventType, progress func(consumed int, total int),
+) (map[string]int, error) {
+	origProgress := Progress
+	Progress = progress
+	defer func() { Progress = origProgress }()
+
+	return ValidateAndExecuteResults(probEventType)
+}
+
+// Validate and execute the probability event, returning the displayed
+// results for further use such as exporting
+//
+//	Params
+//		probEventType ProbEventType : probability event to run
+//	Returns
+//		map[string]int : aggregated results, nil when invalid
+//		error          : any errors encountered
+func ValidateAndExecuteResults(probEventType ProbEventType) (map[string]int, error) {
+	ok, err := validateAll(probEventType)
 	if !ok {
-		return err
+		return nil, err
 	}
 
-	// Specialized probability event validation
-	ok, err = probEventType.validate()
+	return probEventType.execute()
+}
+
+// Generic and then specialized ProbEvent validation
+//
+//	Params
+//		probEventType ProbEventType : probability event to check
+//	Returns
+//		bool  : valid status
+//		error : indicates any errors leading to validation failure
+func validateAll(probEventType ProbEventType) (bool, error) {
+	// Generic probability event validation
+	ok, err := validate(probEventType)
 	if !ok {
-		return err
+		return false, err
 	}
 
-	return probEventType.execute()
+	// Specialized probability event validation
+	return probEventType.validate()
 }
 
 // Generally applicable ProbEvent validation
@@ -156,17 +360,387 @@ func ValidateAndExecute(probEventType ProbEventType) error {
 //		error : indicates any errors leading to validation failure
 func validate(probEventType ProbEventType) (bool, error) {
 	if probEventType.getNumEvents() < 1 {
-		return false, errors.New(ErrInvalidEvents)
+		return false, ErrInvalidEvents
+	}
+
+	if probEventType.getNumEvents() > maxEvents {
+		return false, tooManyEvents()
 	}
 
 	return true, nil
 }
 
+// Wrap ErrTooManyEvents with the largest number of events accepted
+//
+//	Returns
+//		error : ErrTooManyEvents with context
+func tooManyEvents() error {
+	return fmt.Errorf("%w: at most %d, see SetMaxEvents", ErrTooManyEvents, maxEvents)
+}
+
+// Raise or lower the largest number of events accepted in a single run
+//
+//	Params
+//		n int : largest number of events, ex: DefaultMaxEvents
+func SetMaxEvents(n int) {
+	maxEvents = n
+}
+
+// Pass the results of a completed run to RecordRun, if set
+//
+//	Params
+//		eventType string   : type of the run. Ex: CoinEventType
+//		numEvents int      : number of events in the run
+//		res map[string]int : aggregated results of the run
+func recordRun(eventType string, numEvents int, res map[string]int) {
+	if RecordRun != nil {
+		RecordRun(eventType, numEvents, res)
+	}
+}
+
+// Write the results as CSV with a header row and one row per outcome.
+// Numeric outcomes, such as dice faces, are ordered numerically and any
+// other outcomes alphabetically, which orders Heads before Tails. Percents
+// have Precision decimal places
+//
+//	Ex: {"Heads": 1, "Tails": 3} ->
+//
+//	outcome,count,percent
+//	Heads,1,25.000000
+//	Tails,3,75.000000
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//		w io.Writer        : destination of the CSV
+//	Returns
+//		error : any error encountered writing
+func ExportCSV(res map[string]int, w io.Writer) error {
+	total := 0
+	for _, count := range res {
+		total += count
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"outcome", "count", "percent"}); err != nil {
+		return err
+	}
+
+	for _, outcome := range SortedOutcomes(res) {
+		err := writer.Write([]string{
+			outcome,
+			strconv.Itoa(res[outcome]),
+			fmt.Sprintf("%.*f", Precision, Percent(res[outcome], total)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Write the results as a GitHub flavored Markdown table with one row per
+// outcome, in the same order as ExportCSV. Pipes in outcomes are escaped and
+// percents have Precision decimal places
+//
+//	Ex: {"Heads": 1, "Tails": 3} ->
+//
+//	| Outcome | Count | Percent |
+//	| --- | ---: | ---: |
+//	| Heads | 1 | 25.000000 |
+//	| Tails | 3 | 75.000000 |
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//		w io.Writer        : destination of the table
+//	Returns
+//		error : any error encountered writing
+func ExportMarkdown(res map[string]int, w io.Writer) error {
+	total := 0
+	for _, count := range res {
+		total += count
+	}
+
+	if _, err := fmt.Fprint(w, "| Outcome | Count | Percent |\n| --- | ---: | ---: |\n"); err != nil {
+		return err
+	}
+
+	for _, outcome := range SortedOutcomes(res) {
+		_, err := fmt.Fprintf(
+			w, "| %s | %d | %.*f |\n",
+			strings.ReplaceAll(outcome, "|", "\\|"),
+			res[outcome],
+			Precision,
+			Percent(res[outcome], total))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// One outcome of a RunReport
+type OutcomeReport struct {
+	Outcome string  `json:"outcome"`
+	Count   int     `json:"count"`
+	Percent float32 `json:"percent"`
+}
+
+// Machine readable report of a probability run
+type RunReport struct {
+	EventType string          `json:"event_type"`
+	NumEvents int             `json:"num_events"`
+	Outcomes  []OutcomeReport `json:"outcomes"`
+}
+
+// Marshal the results of a run into a JSON report. Fields are in a fixed
+// order and outcomes are ordered as in ExportCSV so the output is
+// deterministic
+//
+//	Ex: "coin", 4, {"Heads": 1, "Tails": 3} ->
+//
+//	{"event_type":"coin","num_events":4,"outcomes":[
+//	{"outcome":"Heads","count":1,"percent":25},
+//	{"outcome":"Tails","count":3,"percent":75}]}
+//
+//	Params
+//		eventType string   : type of the run. Ex: CoinEventType
+//		numEvents int      : number of events in the run
+//		res map[string]int : aggregated results of the run
+//	Returns
+//		[]byte : JSON report
+//		error  : any error encountered marshaling
+func MarshalResults(eventType string, numEvents int, res map[string]int) ([]byte, error) {
+	report := RunReport{
+		EventType: eventType,
+		NumEvents: numEvents,
+		Outcomes:  make([]OutcomeReport, 0, len(res)),
+	}
+
+	for _, outcome := range SortedOutcomes(res) {
+		report.Outcomes = append(report.Outcomes, OutcomeReport{
+			Outcome: outcome,
+			Count:   res[outcome],
+			Percent: Percent(res[outcome], numEvents),
+		})
+	}
+
+	return json.Marshal(report)
+}
+
+// Order the outcomes of the results numerically when both are numbers and
+// alphabetically otherwise
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//	Returns
+//		[]string : sorted outcomes
+func SortedOutcomes(res map[string]int) []string {
+	outcomes := make([]string, 0, len(res))
+	for outcome := range res {
+		outcomes = append(outcomes, outcome)
+	}
+
+	sort.Slice(outcomes, func(i, j int) bool {
+		a, errA := strconv.Atoi(outcomes[i])
+		b, errB := strconv.Atoi(outcomes[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+
+		return outcomes[i] < outcomes[j]
+	})
+
+	return outcomes
+}
+
+// Difference in count of every outcome from the first results to the
+// second. Outcomes missing from one side count as 0 there
+//
+//	Ex: {"1": 3, "2": 5}, {"2": 4, "3": 1} -> {"1": -3, "2": -1, "3": 1}
+//
+//	Params
+//		a map[string]int : results compared against. Ex: a theoretical run
+//		b map[string]int : results compared. Ex: a seeded run
+//	Returns
+//		map[string]int : b minus a for every outcome of either results
+func CompareDistributions(a map[string]int, b map[string]int) map[string]int {
+	deltas := make(map[string]int)
+	for outcome, count := range a {
+		deltas[outcome] -= count
+	}
+	for outcome, count := range b {
+		deltas[outcome] += count
+	}
+
+	return deltas
+}
+
+// Print both counts of every outcome side by side with their difference,
+// in SortedOutcomes order. Example:
+//
+// a: {"1": 3, "2": 5}
+//
+// b: {"2": 4, "3": 1}
+//
+// Outcome    :      A     :      B     : Diff
+//
+// 1          :          3 :          0 : -3
+//
+// 2          :          5 :          4 : -1
+//
+// 3          :          0 :          1 : +1
+//
+//	Params
+//		a map[string]int : results compared against
+//		b map[string]int : results compared
+func DisplayComparison(a map[string]int, b map[string]int) {
+	deltas := CompareDistributions(a, b)
+
+	fmt.Fprintf(Output, "%-10s :      A     :      B     : Diff\n", "Outcome")
+	for _, outcome := range SortedOutcomes(deltas) {
+		fmt.Fprintf(Output, "%-10s : %10d : %10d : %+d\n", outcome, a[outcome], b[outcome], deltas[outcome])
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Find the outcome rolled or flipped most often. Ties go to the first
+// outcome in SortedOutcomes order, ie the lowest face or Heads before Tails
+//
+//	Ex: {"1": 2, "5": 4, "6": 4} -> "5", 4
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//	Returns
+//		string : the most frequent outcome, "" if there are no results
+//		int    : number of times it occurred
+func topOutcome(res map[string]int) (outcome string, count int) {
+	for _, candidate := range SortedOutcomes(res) {
+		if outcome == "" || res[candidate] > count {
+			outcome, count = candidate, res[candidate]
+		}
+	}
+
+	return outcome, count
+}
+
+// Print the most frequent outcome of the results with its percent. Example:
+//
+// Most frequent: 5 (20.000000%)
+//
+//	Params
+//		res map[string]int : aggregated results of a run
+//		numEvents int      : number of events in the run
+func displayTopOutcome(res map[string]int, numEvents int) {
+	outcome, count := topOutcome(res)
+	if outcome == "" {
+		return
+	}
+
+	fmt.Fprintf(
+		Output,
+		"Most frequent: %s (%s)\n\n",
+		outcome, FormatPercent(float64(Percent(count, numEvents))))
+}
+
+// Chi-square goodness of fit statistic of the results against a uniform
+// expectation over their outcomes. Larger values are less likely to come
+// from a fair generator
+//
+//	Ex: {"Heads": 5, "Tails": 5}, 10 -> 0
+//	Ex: {"Heads": 9, "Tails": 1}, 10 -> 6.4
+//
+//	Params
+//		res map[string]int : aggregated results, including outcomes with a
+//		                     count of 0
+//		numEvents int      : number of events in the run
+//	Returns
+//		float64 : sum over outcomes of (observed - expected)^2 / expected
+func ChiSquare(res map[string]int, numEvents int) float64 {
+	if len(res) == 0 || numEvents < 1 {
+		return 0
+	}
+
+	expected := float64(numEvents) / float64(len(res))
+	chiSquare := 0.0
+	for _, count := range res {
+		diff := float64(count) - expected
+		chiSquare += diff * diff / expected
+	}
+
+	return chiSquare
+}
+
+// Check whether the results are consistent with a uniform distribution
+//
+//	Params
+//		res map[string]int : aggregated results, including outcomes with a
+//		                     count of 0
+//		numEvents int      : number of events in the run
+//		critical float64   : critical chi-square value for the degrees of
+//		                     freedom and significance level. Ex: 11.07 for
+//		                     a D6 at 5%
+//	Returns
+//		bool : true if the chi-square statistic does not exceed critical
+func IsUniformWithin(res map[string]int, numEvents int, critical float64) bool {
+	return ChiSquare(res, numEvents) <= critical
+}
+
+// Print the chi-square statistic of the results when ShowChiSquare is set
+//
+//	Params
+//		res map[string]int : aggregated results, including outcomes with a
+//		                     count of 0
+//		numEvents int      : number of events in the run
+func displayChiSquare(res map[string]int, numEvents int) {
+	if ShowChiSquare {
+		fmt.Fprintf(Output, "Chi-square : %f\n\n", ChiSquare(res, numEvents))
+	}
+}
+
+// Format a percent with Precision decimal places, as in the displays
+//
+//	Ex: 51.774691 -> "51.774691%"
+//
+//	Params
+//		percent float64 : percent to format
+//	Returns
+//		string : the formatted percent
+func FormatPercent(percent float64) string {
+	return fmt.Sprintf("%.*f%%", Precision, percent)
+}
+
+// Percent of events expected to land on each outcome when every one of the
+// outcomes is equally likely
+//
+//	Ex: 6 -> 16.666667
+//
+//	Params
+//		nSides int : number of equally likely outcomes. Ex: 2 for a coin
+//	Returns
+//		float64 : 100 / nSides, 0 without any outcome
+func ExpectedPercent(nSides int) float64 {
+	if nSides < 1 {
+		return 0
+	}
+
+	return 100.0 / float64(nSides)
+}
+
 // Utility to compute the percent: numerator / denominator
 //
 //	Params
 //		numerator int   : divided by denominator
 //		denominator int : divides numerator
+//	Returns
+//		float32 : the percent, 0 when the denominator is 0 rather than
+//		          +Inf or NaN
 func Percent(numerator int, denominator int) float32 {
+	if denominator == 0 {
+		return 0
+	}
+
 	return float32(numerator) * 100 / float32(denominator)
 }
@@ -1,6 +1,14 @@
 package probgen
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/romansod/roll-dice/internal/testing_utils"
@@ -58,6 +66,23 @@ func PRNG_for_testing(num_outcomes int) int {
 	return getHardcodedRngNum() % num_outcomes
 }
 
+// Thread safe deterministic PRNG which cycles through every outcome in
+// order regardless of which worker calls it. Any split of N calls across
+// workers therefore yields the same totals as N serial calls
+type cyclicPRNG struct {
+	mu   sync.Mutex
+	next int
+}
+
+// The cyclic Psuedo Random Number Generator injected into ProbGen prng
+func (c *cyclicPRNG) prng(num_outcomes int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := c.next % num_outcomes
+	c.next++
+	return next
+}
+
 /// Tests for probgen
 
 func TestPRNG_for_testing(t *testing.T) {
@@ -190,23 +215,23 @@ func TestNegProbEventTypeValidate(t *testing.T) {
 
 	// Invalid number of events (negative)
 	ok, err := validate(CoinFlip{numEvents: -1})
-	expected, actual := ErrInvalidEvents, err.Error()
+	expected, actual := ErrInvalidEvents.Error(), err.Error()
 	testing_utils.AssertEQb(t, false, ok)
 	testing_utils.AssertEQ(t, expected, actual)
 
 	ok, err = validate(DiceRoll{numEvents: -1, numSides: D4})
-	expected, actual = ErrInvalidEvents, err.Error()
+	expected, actual = ErrInvalidEvents.Error(), err.Error()
 	testing_utils.AssertEQb(t, false, ok)
 	testing_utils.AssertEQ(t, expected, actual)
 
 	// Invalid number of events (zero)
 	ok, err = validate(CoinFlip{numEvents: 0})
-	expected, actual = ErrInvalidEvents, err.Error()
+	expected, actual = ErrInvalidEvents.Error(), err.Error()
 	testing_utils.AssertEQb(t, false, ok)
 	testing_utils.AssertEQ(t, expected, actual)
 
 	ok, err = validate(DiceRoll{numEvents: 0, numSides: D4})
-	expected, actual = ErrInvalidEvents, err.Error()
+	expected, actual = ErrInvalidEvents.Error(), err.Error()
 	testing_utils.AssertEQb(t, false, ok)
 	testing_utils.AssertEQ(t, expected, actual)
 }
@@ -228,6 +253,42 @@ func TestPosProbEventTypeValidate(t *testing.T) {
 	testing_utils.AssertNIL(t, err)
 }
 
+func TestMaxEventsValidate(t *testing.T) {
+	// Runs above the events limit are rejected before running
+
+	// Just under and at the default limit
+	ok, err := validate(CoinFlip{numEvents: DefaultMaxEvents - 1})
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+
+	ok, err = validate(DiceRoll{numEvents: DefaultMaxEvents, numSides: D6})
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+
+	// Just over the default limit
+	ok, err = validate(CoinFlip{numEvents: DefaultMaxEvents + 1})
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "too many events: at most 100000000, see SetMaxEvents", err.Error())
+
+	// A lowered limit is rejected before anything runs
+	SetMaxEvents(10)
+	defer SetMaxEvents(DefaultMaxEvents)
+
+	res, err := ValidateAndExecuteResults(NewCoinFlip(11))
+	testing_utils.AssertEQ(t, "too many events: at most 10, see SetMaxEvents", err.Error())
+	testing_utils.AssertEQi(t, 0, len(res))
+
+	ok, err = validate(CoinFlip{numEvents: 9})
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+
+	// A raised limit lets power users run more
+	SetMaxEvents(DefaultMaxEvents * 10)
+	ok, err = validate(CoinFlip{numEvents: DefaultMaxEvents + 1})
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+}
+
 func TestDiceRollValidate(t *testing.T) {
 	// Test the validation of dice types
 
@@ -237,19 +298,19 @@ func TestDiceRollValidate(t *testing.T) {
 	diceRoll := NewDiceRoll(3, 3)
 	ok, err := diceRoll.validate()
 	testing_utils.AssertEQb(t, false, ok)
-	testing_utils.AssertEQ(t, ErrInvalidDiceType, err.Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
 
 	// Between two valid dice types
 	diceRoll = NewDiceRoll(3, 5)
 	ok, err = diceRoll.validate()
 	testing_utils.AssertEQb(t, false, ok)
-	testing_utils.AssertEQ(t, ErrInvalidDiceType, err.Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
 
 	// Greater than the largest dice type
 	diceRoll = NewDiceRoll(3, 21)
 	ok, err = diceRoll.validate()
 	testing_utils.AssertEQb(t, false, ok)
-	testing_utils.AssertEQ(t, ErrInvalidDiceType, err.Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
 
 	// Valid dice types (D4, D6, D10, D12, D20)
 
@@ -274,6 +335,58 @@ func TestDiceRollValidate(t *testing.T) {
 	testing_utils.AssertEQb(t, true, ok)
 }
 
+func TestPossibleDiceValues(t *testing.T) {
+	// Every dice type has the faces 1 to N in order
+
+	values := possibleDiceValues(D20)
+	testing_utils.AssertEQi(t, 20, len(values))
+	testing_utils.AssertEQ(t, "1", values[0])
+	testing_utils.AssertEQ(t, "20", values[19])
+
+	testing_utils.AssertEQSlice(t, []string{"1", "2", "3", "4"}, possibleDiceValues(D4))
+	testing_utils.AssertEQSlice(t, []string{"1", "2", "3", "4", "5", "6"}, possibleDiceValues(D6))
+
+	// Not limited to the shared dice types
+	values = possibleDiceValues(100)
+	testing_utils.AssertEQi(t, 100, len(values))
+	testing_utils.AssertEQ(t, "100", values[99])
+}
+
+func TestDisplayCDF(t *testing.T) {
+	// Cumulative percents accumulate in ascending face order
+
+	res := map[string]int{"1": 1, "2": 2, "4": 1}
+	diceRoll := DiceRoll{numEvents: 4, numSides: D4}
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	diceRoll.displayCDF(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Cumulative :\n" +
+			"[1]  :  25.000000%\n" +
+			"[2]  :  75.000000%\n" +
+			"[3]  :  75.000000%\n" +
+			"[4]  : 100.000000%\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+
+	// Faces past 9 are still in numeric order and the last reaches 100%
+	res = map[string]int{"3": 1, "10": 2}
+	diceRoll = DiceRoll{numEvents: 3, numSides: D10}
+
+	origStdout, r, w = testing_utils.RedirectStdout()
+	diceRoll.displayCDF(res)
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[9]  :  33.333332%\n[10] : 100.000000%\n\n"))
+
+	// Shown below the frequency table when enabled
+	ShowCDF = true
+	origStdout, r, w = testing_utils.RedirectStdout()
+	diceRoll.display(res)
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	ShowCDF = false
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[10] :  66.666664%  :  10.000000%  : 2\n\nCumulative :\n[1]  :   0.000000%\n"))
+}
+
 func TestGrnProbEventCoinFlip(t *testing.T) {
 	// This tests the full production -> consumption of
 	// probhen.ProbEvent.computeProbability
@@ -336,6 +449,59 @@ func TestGenProbEventDiceRoll(t *testing.T) {
 	testing_utils.AssertEQi(t, expected, actual)
 }
 
+func TestPrecision(t *testing.T) {
+	// Percentages print the configured number of decimal places
+
+	coinFlip := CoinFlip{numEvents: 10000}
+	res := map[string]int{Heads: 4998, Tails: 5002}
+	diceRoll := DiceRoll{numEvents: 4, numSides: D4}
+	dice := map[string]int{"1": 1, "2": 2, "4": 1}
+
+	// Default
+	testing_utils.AssertEQi(t, DefaultPrecision, Precision)
+	origStdout, r, w := testing_utils.RedirectStdout()
+	coinFlip.display(res)
+	diceRoll.display(dice)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(output, "Face :   Observed   :   Expected   : Count\n(H)  :  49.980000%  :  50.000000%  : 4998\n(T)  :  50.020000%  :  50.000000%  : 5002\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[1]  :  25.000000%  :  25.000000%  : 1\n[2]  :  50.000000%  :  25.000000%  : 2\n"))
+
+	// Two decimal places
+	Precision = 2
+	origStdout, r, w = testing_utils.RedirectStdout()
+	coinFlip.display(res)
+	diceRoll.display(dice)
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	Precision = DefaultPrecision
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(output, "Face :   Observed   :   Expected   : Count\n(H)  :      49.98%  :      50.00%  : 4998\n(T)  :      50.02%  :      50.00%  : 5002\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "[1]  :      25.00%  :      25.00%  : 1\n[2]  :      50.00%  :      25.00%  : 2\n"))
+}
+
+func TestPercent(t *testing.T) {
+	// Percent of the denominator, guarded against dividing by 0
+
+	testing_utils.AssertEQf(t, 50, float64(Percent(1, 2)), 1e-6)
+	testing_utils.AssertEQf(t, 33.333333, float64(Percent(1, 3)), 1e-5)
+	testing_utils.AssertEQf(t, 100, float64(Percent(7, 7)), 1e-6)
+	testing_utils.AssertEQf(t, 0, float64(Percent(0, 5)), 1e-6)
+	testing_utils.AssertEQf(t, 250, float64(Percent(5, 2)), 1e-6)
+
+	// (-) No events, nothing to divide by
+	testing_utils.AssertEQf(t, 0, float64(Percent(0, 0)), 1e-6)
+	testing_utils.AssertEQf(t, 0, float64(Percent(3, 0)), 1e-6)
+	testing_utils.AssertEQ(t, "0.000000%", FormatPercent(float64(Percent(3, 0))))
+}
+
+func TestExpectedPercent(t *testing.T) {
+	// Uniform percent of each face, shown next to the observed percent
+
+	testing_utils.AssertEQf(t, 25, ExpectedPercent(D4), 1e-9)
+	testing_utils.AssertEQf(t, 16.666666, ExpectedPercent(D6), 1e-6)
+	testing_utils.AssertEQf(t, 5, ExpectedPercent(D20), 1e-9)
+	testing_utils.AssertEQf(t, TheoreticalHeadsPercent, ExpectedPercent(2), 1e-9)
+	testing_utils.AssertEQf(t, 0, ExpectedPercent(0), 1e-9)
+}
+
 func TestGenProbDisplaysCoinFlip(t *testing.T) {
 	// Test the display functions of ProbEventTypes
 
@@ -351,8 +517,13 @@ func TestGenProbDisplaysCoinFlip(t *testing.T) {
 
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected :=
-		"(H) : 100.000000% : 1\n" +
-			"(T) :   0.000000% : 0\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"(H)  : 100.000000%  :  50.000000%  : 1\n" +
+			"(T)  :   0.000000%  :  50.000000%  : 0\n\n" +
+			"Expected  : 0.500000 per face\n" +
+			"Deviation : (H) +0.500000 (T) -0.500000\n" +
+			"Variance  : n/a for a single flip\n\n" +
+			"Most frequent: Heads (100.000000%)\n\n"
 	testing_utils.AssertEQ(t, expected, output)
 
 	// 2) Small scale should have round numbers
@@ -366,8 +537,13 @@ func TestGenProbDisplaysCoinFlip(t *testing.T) {
 
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected =
-		"(H) :  40.000000% : 4\n" +
-			"(T) :  60.000000% : 6\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"(H)  :  40.000000%  :  50.000000%  : 4\n" +
+			"(T)  :  60.000000%  :  50.000000%  : 6\n\n" +
+			"Expected  : 5.000000 per face\n" +
+			"Deviation : (H) -1.000000 (T) +1.000000\n" +
+			"Variance  : 0.266667 (theoretical 0.250000)\n\n" +
+			"Most frequent: Tails (60.000000%)\n\n"
 	testing_utils.AssertEQ(t, expected, output)
 
 	// 3) Large scale, non round should handle large values
@@ -381,9 +557,73 @@ func TestGenProbDisplaysCoinFlip(t *testing.T) {
 
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected =
-		"(H) :  49.975849% : 499761\n" +
-			"(T) :  50.024151% : 500244\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"(H)  :  49.975849%  :  50.000000%  : 499761\n" +
+			"(T)  :  50.024151%  :  50.000000%  : 500244\n\n" +
+			"Expected  : 500002.500000 per face\n" +
+			"Deviation : (H) -241.500000 (T) +241.500000\n" +
+			"Variance  : 0.250000 (theoretical 0.250000)\n\n" +
+			"Most frequent: Tails (50.024151%)\n\n"
 	testing_utils.AssertEQ(t, expected, output)
+
+	// Sample variance of the flips
+	variance, ok := FlipVariance(4, 10)
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertEQf(t, 0.24*10/9, variance, 1e-9)
+
+	variance, ok = FlipVariance(5, 10)
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertEQf(t, 0.25*10/9, variance, 1e-9)
+
+	_, ok = FlipVariance(1, 1)
+	testing_utils.AssertEQb(t, false, ok)
+}
+
+func TestCoinSides(t *testing.T) {
+	// Coins with custom side labels
+
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = stdout{} }()
+
+	coinFlip := NewCoinFlip(4, "Win", "Lose")
+	coinFlip.display(map[string]int{"Win": 3, "Lose": 1})
+	expected :=
+		"Face :   Observed   :   Expected   : Count\n" +
+			"(W)  :  75.000000%  :  50.000000%  : 3\n" +
+			"(L)  :  25.000000%  :  50.000000%  : 1\n\n" +
+			"Expected  : 2.000000 per face\n" +
+			"Deviation : (W) +1.000000 (L) -1.000000\n" +
+			"Variance  : 0.250000 (theoretical 0.250000)\n\n" +
+			"Most frequent: Win (75.000000%)\n\n"
+	testing_utils.AssertEQ(t, expected, buf.String())
+
+	// Labels starting with the same letter are shown in full
+	buf.Reset()
+	NewCoinFlip(2, "High", "Hold").display(map[string]int{"High": 1, "Hold": 1})
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(
+		buf.String(),
+		"Face   :   Observed   :   Expected   : Count\n"+
+			"(High) :  50.000000%  :  50.000000%  : 1\n"+
+			"(Hold) :  50.000000%  :  50.000000%  : 1\n\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(buf.String(), "Deviation : (High) +0.000000 (Hold) +0.000000\n"))
+
+	// Flips land on the custom sides
+	buf.Reset()
+	res, err := ValidateAndExecuteResults(NewCoinFlip(10, "Win", "Lose"))
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 10, res["Win"]+res["Lose"])
+	testing_utils.AssertEQi(t, 0, res[Heads]+res[Tails])
+
+	// Heads and Tails by default
+	testing_utils.AssertEQSlice(t, []string{Heads, Tails}, NewCoinFlip(10).labels())
+	testing_utils.AssertEQSlice(t, []string{"H", "T"}, NewCoinFlip(10).marks())
+
+	// (-) Exactly two different, non empty labels
+	for _, sides := range [][]string{{"Win"}, {"Win", "Win"}, {"Win", ""}, {"A", "B", "C"}} {
+		_, err = ValidateAndExecuteResults(NewCoinFlip(10, sides...))
+		testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidCoinSides))
+	}
 }
 
 func TestGenProbDisplaysDiceRoll(t *testing.T) {
@@ -405,12 +645,14 @@ func TestGenProbDisplaysDiceRoll(t *testing.T) {
 
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected :=
-		"[1]  : 100.000000% : 1\n" +
-			"[2]  :   0.000000% : 0\n" +
-			"[3]  :   0.000000% : 0\n" +
-			"[4]  :   0.000000% : 0\n" +
-			"[5]  :   0.000000% : 0\n" +
-			"[6]  :   0.000000% : 0\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"[1]  : 100.000000%  :  16.666667%  : 1\n" +
+			"[2]  :   0.000000%  :  16.666667%  : 0\n" +
+			"[3]  :   0.000000%  :  16.666667%  : 0\n" +
+			"[4]  :   0.000000%  :  16.666667%  : 0\n" +
+			"[5]  :   0.000000%  :  16.666667%  : 0\n" +
+			"[6]  :   0.000000%  :  16.666667%  : 0\n\n" +
+			"Most frequent: 1 (100.000000%)\n\n"
 	testing_utils.AssertEQ(t, expected, output)
 
 	// 2) Small scale should have round numbers
@@ -434,18 +676,20 @@ func TestGenProbDisplaysDiceRoll(t *testing.T) {
 
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected =
-		"[1]  :  20.000000% : 2\n" +
-			"[2]  :   0.000000% : 0\n" +
-			"[3]  :  40.000000% : 4\n" +
-			"[4]  :   0.000000% : 0\n" +
-			"[5]  :  20.000000% : 2\n" +
-			"[6]  :   0.000000% : 0\n" +
-			"[7]  :  10.000000% : 1\n" +
-			"[8]  :   0.000000% : 0\n" +
-			"[9]  :   0.000000% : 0\n" +
-			"[10] :   0.000000% : 0\n" +
-			"[11] :  10.000000% : 1\n" +
-			"[12] :  10.000000% : 1\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"[1]  :  20.000000%  :   8.333333%  : 2\n" +
+			"[2]  :   0.000000%  :   8.333333%  : 0\n" +
+			"[3]  :  40.000000%  :   8.333333%  : 4\n" +
+			"[4]  :   0.000000%  :   8.333333%  : 0\n" +
+			"[5]  :  20.000000%  :   8.333333%  : 2\n" +
+			"[6]  :   0.000000%  :   8.333333%  : 0\n" +
+			"[7]  :  10.000000%  :   8.333333%  : 1\n" +
+			"[8]  :   0.000000%  :   8.333333%  : 0\n" +
+			"[9]  :   0.000000%  :   8.333333%  : 0\n" +
+			"[10] :   0.000000%  :   8.333333%  : 0\n" +
+			"[11] :  10.000000%  :   8.333333%  : 1\n" +
+			"[12] :  10.000000%  :   8.333333%  : 1\n\n" +
+			"Most frequent: 3 (40.000000%)\n\n"
 	testing_utils.AssertEQ(t, expected, output)
 
 	// 3) Large scale, non round should handle large values
@@ -461,13 +705,106 @@ func TestGenProbDisplaysDiceRoll(t *testing.T) {
 
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
 	expected =
-		"[1]  :  25.006374% : 250065\n" +
-			"[2]  :  24.982475% : 249826\n" +
-			"[3]  :  24.957375% : 249575\n" +
-			"[4]  :  25.053774% : 250539\n\n"
+		"Face :   Observed   :   Expected   : Count\n" +
+			"[1]  :  25.006374%  :  25.000000%  : 250065\n" +
+			"[2]  :  24.982475%  :  25.000000%  : 249826\n" +
+			"[3]  :  24.957375%  :  25.000000%  : 249575\n" +
+			"[4]  :  25.053774%  :  25.000000%  : 250539\n\n" +
+			"Most frequent: 4 (25.053774%)\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestCompareDistributions(t *testing.T) {
+	// Test the per outcome differences of two results, including outcomes
+	// only one side has
+
+	a := map[string]int{"1": 3, "2": 5, "10": 2}
+	b := map[string]int{"2": 4, "3": 1, "10": 2}
+
+	deltas := CompareDistributions(a, b)
+	testing_utils.AssertEQi(t, 4, len(deltas))
+	testing_utils.AssertEQi(t, -3, deltas["1"])
+	testing_utils.AssertEQi(t, -1, deltas["2"])
+	testing_utils.AssertEQi(t, 1, deltas["3"])
+	testing_utils.AssertEQi(t, 0, deltas["10"])
+
+	// Inputs are left untouched
+	testing_utils.AssertEQi(t, 3, len(a))
+	testing_utils.AssertEQi(t, 3, len(b))
+
+	// Nothing to compare
+	testing_utils.AssertEQi(t, 0, len(CompareDistributions(map[string]int{}, nil)))
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	DisplayComparison(a, b)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Outcome    :      A     :      B     : Diff\n" +
+			"1          :          3 :          0 : -3\n" +
+			"2          :          5 :          4 : -1\n" +
+			"3          :          0 :          1 : +1\n" +
+			"10         :          2 :          2 : +0\n\n"
 	testing_utils.AssertEQ(t, expected, output)
 }
 
+func TestTopOutcome(t *testing.T) {
+	// Test the most frequent outcome and its tie breaking
+
+	// Clear winner
+	outcome, count := topOutcome(map[string]int{"1": 2, "2": 7, "3": 1})
+	testing_utils.AssertEQ(t, "2", outcome)
+	testing_utils.AssertEQi(t, 7, count)
+
+	// Ties go to the lowest face, compared numerically
+	outcome, count = topOutcome(map[string]int{"10": 4, "2": 1, "9": 4, "12": 4})
+	testing_utils.AssertEQ(t, "9", outcome)
+	testing_utils.AssertEQi(t, 4, count)
+
+	// Ties go to Heads before Tails
+	outcome, count = topOutcome(map[string]int{Tails: 5, Heads: 5})
+	testing_utils.AssertEQ(t, Heads, outcome)
+	testing_utils.AssertEQi(t, 5, count)
+
+	// Nothing rolled
+	outcome, count = topOutcome(map[string]int{})
+	testing_utils.AssertEQ(t, "", outcome)
+	testing_utils.AssertEQi(t, 0, count)
+
+	// Displayed after the results
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = stdout{} }()
+	CoinFlip{numEvents: 10}.display(map[string]int{Heads: 5, Tails: 5})
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(buf.String(), "Most frequent: Heads (50.000000%)\n\n"))
+}
+
+func TestOutput(t *testing.T) {
+	// Test that displays are written to Output instead of os.Stdout
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = stdout{} }()
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	BiasedCoinFlip{numEvents: 4, headsProbability: 0.7}.display(map[string]int{Heads: 3, Tails: 1})
+	DisplayComparison(map[string]int{"1": 2}, map[string]int{"1": 3})
+	stdoutput := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+
+	testing_utils.AssertEQ(t, "", stdoutput)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(
+		buf.String(),
+		"Face :   Observed   :  Theoretical : Count\n"+
+			"(H)  :  75.000000%  :  70.000000%  : 3\n"+
+			"(T)  :  25.000000%  :  30.000000%  : 1\n\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(buf.String(), "Outcome    :      A     :      B     : Diff\n"))
+
+	// Restored to follow os.Stdout
+	Output = stdout{}
+	origStdout, r, w = testing_utils.RedirectStdout()
+	fmt.Fprint(Output, "to stdout")
+	stdoutput = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "to stdout", stdoutput)
+}
+
 func TestDisplayOneCoinFlip(t *testing.T) {
 	// Test the proper coin handling for single action
 
@@ -489,7 +826,7 @@ func TestDisplayOneDiceRoll(t *testing.T) {
 
 	ExecuteAndDisplayOneRollAction(D4)
 	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
-	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType)
+	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType.Error())
 
 	// Single D6 (+)
 	origStdout, r, w = testing_utils.RedirectStdout()
@@ -505,19 +842,1369 @@ func TestDisplayOneDiceRoll(t *testing.T) {
 
 	ExecuteAndDisplayOneRollAction(D10)
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
-	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType)
+	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType.Error())
 
 	// Single D12 (-)
 	origStdout, r, w = testing_utils.RedirectStdout()
 
 	ExecuteAndDisplayOneRollAction(D12)
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
-	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType)
+	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType.Error())
 
 	// Single D20 (-)
 	origStdout, r, w = testing_utils.RedirectStdout()
 
 	ExecuteAndDisplayOneRollAction(D20)
 	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
-	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType)
+	testing_utils.AssertEQb(t, false, output != ErrUnsupportedDiceType.Error())
+}
+
+func TestRollD6Animated(t *testing.T) {
+	// Test the tumbling frames end on the rolled result
+
+	initHardcodedRngNums([]int{2, 0, 5, 3})
+	result, frames := RollD6Animated(4, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 4, result)
+	testing_utils.AssertEQi(t, 4, len(frames))
+	testing_utils.AssertEQ(t, d6Visuals[result-1], frames[len(frames)-1])
+	testing_utils.AssertEQ(t, d6Visuals[r3], frames[0])
+	testing_utils.AssertEQ(t, d6Visuals[r1], frames[1])
+	testing_utils.AssertEQ(t, d6Visuals[r6], frames[2])
+
+	// Always at least the result
+	initHardcodedRngNums([]int{5})
+	result, frames = RollD6Animated(0, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 6, result)
+	testing_utils.AssertEQi(t, 1, len(frames))
+	testing_utils.AssertEQ(t, d6Visuals[r6], frames[0])
+}
+
+func TestGlyphs(t *testing.T) {
+	// Glyphs replace the ASCII art when enabled
+
+	origColor := UseColor
+	UseColor = false
+	UseGlyphs = true
+	defer func() { UseColor, UseGlyphs = origColor, false }()
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	res := ExecuteAndDisplayOneRollActionWith(D6, func(int) int { return r4 })
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQi(t, r4, res)
+	testing_utils.AssertEQ(t, "⚃\n", output)
+
+	// Each face of the results is prefixed with its glyph
+	origStdout, r, w = testing_utils.RedirectStdout()
+	DiceRoll{numEvents: 2, numSides: D6}.display(map[string]int{"1": 1, "6": 1})
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(output, "Face :   Observed   :   Expected   : Count\n⚀ [1]  :  50.000000%  :  16.666667%  : 1\n⚁ [2]  :   0.000000%  :  16.666667%  : 0\n"))
+	testing_utils.AssertEQb(t, true, strings.Contains(output, "⚅ [6]  :  50.000000%  :  16.666667%  : 1\n"))
+
+	// Other dice have no glyphs
+	origStdout, r, w = testing_utils.RedirectStdout()
+	DiceRoll{numEvents: 1, numSides: D4}.display(map[string]int{"1": 1})
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(output, "Face :   Observed   :   Expected   : Count\n[1]  : 100.000000%  :  25.000000%  : 1\n"))
+
+	// Colored glyphs keep the pip color
+	UseColor = true
+	testing_utils.AssertEQ(t, PipColor+"⚅"+ColorReset+"\n", d6Visual(r6))
+}
+
+func TestComputeProbabilityParallel(t *testing.T) {
+	// The parallel path must yield the same totals as the serial path
+	// for a fixed deterministic generator
+	//
+	// - 1003 dice roll test across 4 workers
+
+	serialPRNG, parallelPRNG := cyclicPRNG{}, cyclicPRNG{}
+	outcomes := []string{"1", "2", "3", "4", "5", "6"}
+
+	serial := ProbEvent{numEvents: 1003, outcomes: outcomes, prng: serialPRNG.prng}
+	parallel := ProbEvent{numEvents: 1003, outcomes: outcomes, prng: parallelPRNG.prng}
+
+	expected, actual := serial.computeProbability(), parallel.computeProbabilityParallel(4)
+
+	for _, outcome := range outcomes {
+		testing_utils.AssertEQi(t, expected[outcome], actual[outcome])
+	}
+
+	// 1003 = 6 * 167 + 1
+	testing_utils.AssertEQi(t, 168, actual["1"])
+	testing_utils.AssertEQi(t, 167, actual["6"])
+
+	// More workers than events still covers every event
+	parallelPRNG = cyclicPRNG{}
+	small := ProbEvent{numEvents: 3, outcomes: outcomes, prng: parallelPRNG.prng}
+	res := small.computeProbabilityParallel(8)
+	testing_utils.AssertEQi(t, 1, res["1"])
+	testing_utils.AssertEQi(t, 1, res["2"])
+	testing_utils.AssertEQi(t, 1, res["3"])
+	testing_utils.AssertEQi(t, 0, res["4"])
+}
+
+func TestComputeProbabilityParallelSeeded(t *testing.T) {
+	// Workers share a seeded generator, which is not safe for concurrent
+	// use on its own. Run with -race to catch unguarded calls
+	//
+	// - 10000 coin flip test across 4 workers
+
+	pe := ProbEvent{numEvents: 10000, outcomes: []string{Heads, Tails}, prng: NewSeededPRNG(42)}
+	res := pe.computeProbabilityParallel(4)
+	testing_utils.AssertEQi(t, 10000, res[Heads]+res[Tails])
+
+	// Same totals as the serial path for the same seed
+	serial := ProbEvent{numEvents: 10000, outcomes: []string{Heads, Tails}, prng: NewSeededPRNG(42)}
+	testing_utils.AssertEQi(t, serial.computeProbability()[Heads], res[Heads])
+}
+
+func BenchmarkComputeProbabilitySerial(b *testing.B) {
+	pe := ProbEvent{numEvents: ParallelThreshold, outcomes: []string{Heads, Tails}, prng: RandNumGen}
+	for i := 0; i < b.N; i++ {
+		events := make(chan string)
+		go pe.produceEvent(events)
+		pe.consumeEvents(events)
+	}
+}
+
+func BenchmarkComputeProbabilityParallel(b *testing.B) {
+	pe := ProbEvent{numEvents: ParallelThreshold, outcomes: []string{Heads, Tails}, prng: RandNumGen}
+
+	for i := 0; i < b.N; i++ {
+		pe.computeProbability()
+	}
+}
+
+func TestCheckpointedHeadsPercent(t *testing.T) {
+	// Observed heads percent at each checkpoint of an ordered run
+	//
+	// - 10 coin flip test : H T T H T H T T T T
+
+	initHardcodedRngNums([]int{0, 1, 3, 2, 5, 4, 7, 9, 11, 13})
+	coinFlip := CoinFlip{numEvents: 10, prng: PRNG_for_testing}
+
+	percents := coinFlip.checkpointedHeadsPercent(DefaultCheckpoints)
+	testing_utils.AssertEQi(t, 3, len(percents))
+
+	// 1 flip  : H          -> 100%
+	testing_utils.AssertEQ(t, "100.000000", fmt.Sprintf("%f", percents[0]))
+	// 5 flips : H T T H T  -> 40%
+	testing_utils.AssertEQ(t, "40.000000", fmt.Sprintf("%f", percents[1]))
+	// 10 flips: 3 heads    -> 30%
+	testing_utils.AssertEQ(t, "30.000000", fmt.Sprintf("%f", percents[2]))
+
+	// Display the convergence table
+	origStdout, r, w := testing_utils.RedirectStdout()
+	checkpoints := coinFlip.checkpointFlips(DefaultCheckpoints)
+	testing_utils.AssertEQSlice(t, []int{1, 5, 10}, checkpoints)
+	coinFlip.displayConvergence(checkpoints, percents)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Flips      :   Observed   :  Theoretical\n" +
+			"1          : 100.000000%  :  50.000000%\n" +
+			"5          :  40.000000%  :  50.000000%\n" +
+			"10         :  30.000000%  :  50.000000%\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestLogCheckpoints(t *testing.T) {
+	// Running heads percent at every power of 10 flips of an ordered run
+
+	testing_utils.AssertEQSlice(t, []int{10, 100, 1000, 2500}, LogCheckpoints(2500))
+	testing_utils.AssertEQSlice(t, []int{10, 100, 1000}, LogCheckpoints(1000))
+	testing_utils.AssertEQSlice(t, []int{10}, LogCheckpoints(10))
+	testing_utils.AssertEQSlice(t, []int{7}, LogCheckpoints(7))
+
+	// - 250 coin flip test : flips 1-10 are H T H T H T H T H H (6 heads),
+	//   flips 11-100 alternate T H (45 more heads), flips 101-250 are all T
+
+	rngNums := []int{0, 1, 0, 1, 0, 1, 0, 1, 0, 0}
+	for i := 10; i < 100; i++ {
+		rngNums = append(rngNums, 1-i%2)
+	}
+	for i := 100; i < 250; i++ {
+		rngNums = append(rngNums, 1)
+	}
+	initHardcodedRngNums(rngNums)
+	coinFlip := CoinFlip{numEvents: 250, prng: PRNG_for_testing}
+
+	checkpoints := LogCheckpoints(coinFlip.numEvents)
+	percents := coinFlip.headsPercentAtFlips(checkpoints)
+	testing_utils.AssertEQi(t, 3, len(percents))
+
+	// 10 flips  : 6 heads  -> 60%
+	testing_utils.AssertEQ(t, "60.000000", fmt.Sprintf("%f", percents[0]))
+	// 100 flips : 51 heads -> 51%
+	testing_utils.AssertEQ(t, "51.000000", fmt.Sprintf("%f", percents[1]))
+	// 250 flips : 51 heads -> 20.4%
+	testing_utils.AssertEQ(t, "20.400000", fmt.Sprintf("%f", percents[2]))
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	coinFlip.displayConvergence(checkpoints, percents)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Flips      :   Observed   :  Theoretical\n" +
+			"10         :  60.000000%  :  50.000000%\n" +
+			"100        :  51.000000%  :  50.000000%\n" +
+			"250        :  20.400000%  :  50.000000%\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestCustomDiceRoll(t *testing.T) {
+	// Test validation and display of custom dice faces
+
+	// (-) No faces
+	customDiceRoll := NewCustomDiceRoll(3, []string{})
+	ok, err := customDiceRoll.validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrInvalidFaces.Error(), err.Error())
+
+	// (-) Duplicate faces
+	ok, err = NewCustomDiceRoll(3, []string{"+", "+", "-"}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid custom dice: duplicate face '+'", err.Error())
+
+	// (+) Non numeric faces bypass the dice type validation
+	customDiceRoll = NewCustomDiceRoll(3, []string{"+", "-", "0"})
+	ok, err = customDiceRoll.validate()
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+
+	// (+) Odd number of faces
+	ok, _ = NewCustomDiceRoll(3, []string{"1", "2", "3", "4", "5", "6", "7"}).validate()
+	testing_utils.AssertEQb(t, true, ok)
+
+	// Deterministic rolls aggregate into the provided faces
+	//
+	// - 6 fudge dice roll test
+
+	initHardcodedRngNums([]int{0, 3, 5, 22, 7, 4})
+	pe := ProbEvent{
+		numEvents: 6,
+		outcomes:  customDiceRoll.faces,
+		prng:      PRNG_for_testing}
+
+	res := pe.computeProbability()
+
+	// 0, 3 -> 2 x +
+	testing_utils.AssertEQi(t, 2, res["+"])
+	// 22, 7, 4 -> 3 x -
+	testing_utils.AssertEQi(t, 3, res["-"])
+	// 5 -> 1 x 0
+	testing_utils.AssertEQi(t, 1, res["0"])
+
+	// Three row distribution in the order of the faces
+	origStdout, r, w := testing_utils.RedirectStdout()
+	customDiceRoll = NewCustomDiceRoll(6, []string{"+", "-", "0"})
+	customDiceRoll.display(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"[+]  :  33.333332% : 2\n" +
+			"[-]  :  50.000000% : 3\n" +
+			"[0]  :  16.666666% : 1\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestCustomDiceLabels(t *testing.T) {
+	// Test that labelled faces of mixed lengths line up in one column and
+	// are counted under their labels
+
+	faces := []string{"crit", "hit", "miss", "glancing", "hit", "miss"}
+	initHardcodedRngNums([]int{0, 1, 4, 3, 2, 7})
+	pe := ProbEvent{
+		numEvents: 6,
+		outcomes:  faces,
+		prng:      PRNG_for_testing}
+
+	res := pe.computeProbability()
+	testing_utils.AssertEQi(t, 1, res["crit"])
+	testing_utils.AssertEQi(t, 3, res["hit"])
+	testing_utils.AssertEQi(t, 1, res["miss"])
+	testing_utils.AssertEQi(t, 1, res["glancing"])
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	NewCustomDiceRoll(6, []string{"crit", "hit", "miss", "glancing"}).display(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"[crit]     :  16.666666% : 1\n" +
+			"[hit]      :  50.000000% : 3\n" +
+			"[miss]     :  16.666666% : 1\n" +
+			"[glancing] :  16.666666% : 1\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+
+	// Multi-byte labels are padded by character, not byte
+	origStdout, r, w = testing_utils.RedirectStdout()
+	NewCustomDiceRoll(2, []string{"★", "miss"}).display(map[string]int{"★": 1, "miss": 1})
+	output = testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected =
+		"[★]    :  50.000000% : 1\n" +
+			"[miss] :  50.000000% : 1\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestSpinnerValidate(t *testing.T) {
+	// Test validation of spinner segments
+
+	// (-) No segments
+	ok, err := NewSpinner(3, []Segment{}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrInvalidSegments.Error(), err.Error())
+
+	// (-) Empty label
+	ok, err = NewSpinner(3, []Segment{{"Car", 1}, {"", 2}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrEmptyLabel.Error(), err.Error())
+
+	// (-) Duplicate label
+	ok, err = NewSpinner(3, []Segment{{"Car", 1}, {"Car", 2}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: duplicate segment label 'Car'", err.Error())
+
+	// (-) Zero and negative weights
+	ok, err = NewSpinner(3, []Segment{{"Car", 0}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: segment weights must be positive and finite, 'Car' has weight 0", err.Error())
+
+	ok, err = NewSpinner(3, []Segment{{"Car", 1}, {"Bike", -0.5}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: segment weights must be positive and finite, 'Bike' has weight -0.5", err.Error())
+
+	// (-) NaN and infinite weights
+	ok, err = NewSpinner(3, []Segment{{"Car", math.NaN()}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: segment weights must be positive and finite, 'Car' has weight NaN", err.Error())
+
+	ok, err = NewSpinner(3, []Segment{{"Car", 1}, {"Bike", math.Inf(1)}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: segment weights must be positive and finite, 'Bike' has weight +Inf", err.Error())
+
+	ok, err = NewSpinner(3, []Segment{{"Car", math.Inf(-1)}}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid spinner: segment weights must be positive and finite, 'Car' has weight -Inf", err.Error())
+
+	// (+) Fractional weights
+	ok, err = NewSpinner(3, []Segment{{"Car", 0.1}, {"Bike", 0.9}}).validate()
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+}
+
+func TestSpinnerWeighted(t *testing.T) {
+	// Test the weighted aggregation of a skewed spinner
+	//
+	// - 8 spin test over cumulative weights {0.125, 0.5, 1}
+
+	initHardcodedRngNums([]int{
+		0,                      // 0      -> Car
+		SpinResolution / 16,    // 0.0625 -> Car
+		SpinResolution / 8,     // 0.125  -> Bike (boundary)
+		SpinResolution / 4,     // 0.25   -> Bike
+		SpinResolution / 2,     // 0.5    -> Nothing (boundary)
+		SpinResolution * 5 / 8, // 0.625  -> Nothing
+		SpinResolution * 3 / 4, // 0.75   -> Nothing
+		SpinResolution - 1,     // ~1     -> Nothing
+	})
+	spinner := NewSpinner(8, []Segment{{"Car", 1}, {"Bike", 3}, {"Nothing", 4}})
+	spinner.prng = PRNG_for_testing
+
+	res := spinner.spin()
+	testing_utils.AssertEQi(t, 2, res["Car"])
+	testing_utils.AssertEQi(t, 2, res["Bike"])
+	testing_utils.AssertEQi(t, 4, res["Nothing"])
+
+	// Observed next to the normalized expected frequency
+	origStdout, r, w := testing_utils.RedirectStdout()
+	spinner.display(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Segment    :   Observed   :   Expected   : Count\n" +
+			"Car        :  25.000000%  :  12.500000%  : 2\n" +
+			"Bike       :  25.000000%  :  37.500000%  : 2\n" +
+			"Nothing    :  50.000000%  :  50.000000%  : 4\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestDistributions(t *testing.T) {
+	// Library endpoints return the aggregated results without printing
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	flips, flipErr := FlipDistribution(100)
+	rolls, rollErr := RollDistribution(200, D4)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(t, "", output)
+
+	testing_utils.AssertNIL(t, flipErr)
+	testing_utils.AssertEQi(t, 100, flips[Heads]+flips[Tails])
+	for outcome := range flips {
+		testing_utils.AssertEQb(t, true, outcome == Heads || outcome == Tails)
+	}
+
+	testing_utils.AssertNIL(t, rollErr)
+	total := 0
+	for face, count := range rolls {
+		testing_utils.AssertEQb(t, true, slices.Contains([]string{"1", "2", "3", "4"}, face))
+		total += count
+	}
+	testing_utils.AssertEQi(t, 200, total)
+
+	// (-) Existing validation errors
+	_, err := FlipDistribution(0)
+	testing_utils.AssertEQ(t, ErrInvalidEvents.Error(), err.Error())
+	_, err = RollDistribution(0, D6)
+	testing_utils.AssertEQ(t, ErrInvalidEvents.Error(), err.Error())
+	_, err = RollDistribution(10, 7)
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
+}
+
+func TestSumDiceRoll(t *testing.T) {
+	// Test validation and the tally of summed dice
+
+	// (-) No dice
+	ok, err := NewSumDiceRoll(3, 0, D6).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrInvalidNumDice.Error(), err.Error())
+
+	// (-) Invalid dice type
+	ok, err = NewSumDiceRoll(3, 2, 7).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
+
+	// - 4 x 2d6 roll test
+
+	initHardcodedRngNums([]int{
+		0, 1, // 1 + 2 -> 3
+		5, 5, // 6 + 6 -> 12
+		2, 3, // 3 + 4 -> 7
+		7, 4, // 2 + 5 -> 7
+	})
+	sumDiceRoll := NewSumDiceRoll(4, 2, D6)
+	sumDiceRoll.prng = PRNG_for_testing
+
+	res := sumDiceRoll.roll()
+	testing_utils.AssertEQi(t, 3, len(res))
+	testing_utils.AssertEQi(t, 1, res["3"])
+	testing_utils.AssertEQi(t, 1, res["12"])
+	testing_utils.AssertEQi(t, 2, res["7"])
+
+	// Every possible sum is displayed
+	origStdout, r, w := testing_utils.RedirectStdout()
+	sumDiceRoll.display(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"[2]  :   0.000000% : 0\n" +
+			"[3]  :  25.000000% : 1\n" +
+			"[4]  :   0.000000% : 0\n" +
+			"[5]  :   0.000000% : 0\n" +
+			"[6]  :   0.000000% : 0\n" +
+			"[7]  :  50.000000% : 2\n" +
+			"[8]  :   0.000000% : 0\n" +
+			"[9]  :   0.000000% : 0\n" +
+			"[10] :   0.000000% : 0\n" +
+			"[11] :   0.000000% : 0\n" +
+			"[12] :  25.000000% : 1\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+
+	// A single die sums to its face
+	initHardcodedRngNums([]int{3})
+	testing_utils.AssertEQi(t, 4, RollSum(1, D6, PRNG_for_testing))
+}
+
+func TestExportCSV(t *testing.T) {
+	// Results are written as CSV in a stable order
+
+	var out bytes.Buffer
+
+	// Dice faces are ordered numerically
+	testing_utils.AssertNIL(t, ExportCSV(map[string]int{"10": 1, "2": 2, "1": 1}, &out))
+	expected :=
+		"outcome,count,percent\n" +
+			"1,1,25.000000\n" +
+			"2,2,50.000000\n" +
+			"10,1,25.000000\n"
+	testing_utils.AssertEQ(t, expected, out.String())
+
+	// Heads then Tails
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportCSV(map[string]int{Tails: 3, Heads: 1}, &out))
+	expected =
+		"outcome,count,percent\n" +
+			"Heads,1,25.000000\n" +
+			"Tails,3,75.000000\n"
+	testing_utils.AssertEQ(t, expected, out.String())
+
+	// Outcomes needing quotes are escaped
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportCSV(map[string]int{"a,b": 2}, &out))
+	testing_utils.AssertEQ(t, "outcome,count,percent\n\"a,b\",2,100.000000\n", out.String())
+
+	// Percents follow the precision of the displays
+	Precision = 2
+	defer func() { Precision = DefaultPrecision }()
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportCSV(map[string]int{Tails: 2, Heads: 1}, &out))
+	testing_utils.AssertEQ(t, "outcome,count,percent\nHeads,1,33.33\nTails,2,66.67\n", out.String())
+}
+
+func TestExportMarkdown(t *testing.T) {
+	// Results are written as a Markdown table in a stable order
+
+	var out bytes.Buffer
+
+	// Dice faces are ordered numerically
+	testing_utils.AssertNIL(t, ExportMarkdown(map[string]int{"10": 1, "2": 2, "1": 1}, &out))
+	expected :=
+		"| Outcome | Count | Percent |\n" +
+			"| --- | ---: | ---: |\n" +
+			"| 1 | 1 | 25.000000 |\n" +
+			"| 2 | 2 | 50.000000 |\n" +
+			"| 10 | 1 | 25.000000 |\n"
+	testing_utils.AssertEQ(t, expected, out.String())
+
+	// Heads then Tails
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportMarkdown(map[string]int{Tails: 3, Heads: 1}, &out))
+	expected =
+		"| Outcome | Count | Percent |\n" +
+			"| --- | ---: | ---: |\n" +
+			"| Heads | 1 | 25.000000 |\n" +
+			"| Tails | 3 | 75.000000 |\n"
+	testing_utils.AssertEQ(t, expected, out.String())
+
+	// Pipes would split the cell and are escaped
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportMarkdown(map[string]int{"a|b": 2}, &out))
+	testing_utils.AssertEQ(
+		t, "| Outcome | Count | Percent |\n| --- | ---: | ---: |\n| a\\|b | 2 | 100.000000 |\n", out.String())
+
+	// Percents follow the precision of the displays
+	Precision = 2
+	defer func() { Precision = DefaultPrecision }()
+	out.Reset()
+	testing_utils.AssertNIL(t, ExportMarkdown(map[string]int{Tails: 2, Heads: 1}, &out))
+	testing_utils.AssertEQb(t, true, strings.HasSuffix(out.String(), "| Heads | 1 | 33.33 |\n| Tails | 2 | 66.67 |\n"))
+}
+
+func TestMarshalResults(t *testing.T) {
+	// Reports are deterministic down to the bytes
+
+	data, err := MarshalResults(CoinEventType, 4, map[string]int{Tails: 3, Heads: 1})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQ(
+		t,
+		`{"event_type":"coin","num_events":4,"outcomes":[`+
+			`{"outcome":"Heads","count":1,"percent":25},`+
+			`{"outcome":"Tails","count":3,"percent":75}]}`,
+		string(data))
+
+	data, err = MarshalResults(DiceEventType(D4), 3, map[string]int{"4": 1, "1": 1, "3": 1, "2": 0})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQ(
+		t,
+		`{"event_type":"D4","num_events":3,"outcomes":[`+
+			`{"outcome":"1","count":1,"percent":33.333332},`+
+			`{"outcome":"2","count":0,"percent":0},`+
+			`{"outcome":"3","count":1,"percent":33.333332},`+
+			`{"outcome":"4","count":1,"percent":33.333332}]}`,
+		string(data))
+
+	// Empty results still have an outcomes array
+	data, err = MarshalResults(CoinEventType, 0, map[string]int{})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQ(t, `{"event_type":"coin","num_events":0,"outcomes":[]}`, string(data))
+}
+
+func TestChiSquare(t *testing.T) {
+	// Chi-square statistic against a uniform expectation
+
+	// Perfectly uniform
+	uniform := map[string]int{"1": 10, "2": 10, "3": 10, "4": 10, "5": 10, "6": 10}
+	testing_utils.AssertEQ(t, "0.000000", fmt.Sprintf("%f", ChiSquare(uniform, 60)))
+	testing_utils.AssertEQb(t, true, IsUniformWithin(uniform, 60, 11.07))
+
+	// Heavily skewed
+	skewed := map[string]int{"1": 60, "2": 0, "3": 0, "4": 0, "5": 0, "6": 0}
+	testing_utils.AssertEQ(t, "300.000000", fmt.Sprintf("%f", ChiSquare(skewed, 60)))
+	testing_utils.AssertEQb(t, false, IsUniformWithin(skewed, 60, 11.07))
+
+	// Slightly off coin
+	coin := map[string]int{Heads: 9, Tails: 1}
+	testing_utils.AssertEQ(t, "6.400000", fmt.Sprintf("%f", ChiSquare(coin, 10)))
+
+	// Nothing to compare
+	testing_utils.AssertEQ(t, "0.000000", fmt.Sprintf("%f", ChiSquare(map[string]int{}, 0)))
+
+	// Optionally displayed, counting faces never rolled
+	ShowChiSquare = true
+	origStdout, r, w := testing_utils.RedirectStdout()
+	DiceRoll{numEvents: 12, numSides: D4}.display(map[string]int{"1": 12})
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	ShowChiSquare = false
+	expected :=
+		"Face :   Observed   :   Expected   : Count\n" +
+			"[1]  : 100.000000%  :  25.000000%  : 12\n" +
+			"[2]  :   0.000000%  :  25.000000%  : 0\n" +
+			"[3]  :   0.000000%  :  25.000000%  : 0\n" +
+			"[4]  :   0.000000%  :  25.000000%  : 0\n\n" +
+			"Chi-square : 36.000000\n\n" +
+			"Most frequent: 1 (100.000000%)\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestRollUntil(t *testing.T) {
+	// Roll until the target face shows
+
+	// - target 4 shows on the third roll
+	initHardcodedRngNums([]int{0, 5, 3, 3})
+	rolls, hit := RollUntil(D6, 4, 10, PRNG_for_testing)
+	testing_utils.AssertEQb(t, true, hit)
+	testing_utils.AssertEQi(t, 3, rolls)
+
+	// - target never shows within the maximum
+	initHardcodedRngNums([]int{0, 1, 2})
+	rolls, hit = RollUntil(D6, 6, 3, PRNG_for_testing)
+	testing_utils.AssertEQb(t, false, hit)
+	testing_utils.AssertEQi(t, 3, rolls)
+
+	// (-) Invalid arguments roll nothing
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidateRollUntil(7, 1, 10).Error())
+	testing_utils.AssertEQ(t, "invalid target face: must be in range [1,6]", ValidateRollUntil(D6, 0, 10).Error())
+	testing_utils.AssertEQ(t, "invalid target face: must be in range [1,6]", ValidateRollUntil(D6, 7, 10).Error())
+	testing_utils.AssertEQ(t, ErrInvalidMaxRolls.Error(), ValidateRollUntil(D6, 1, 0).Error())
+	testing_utils.AssertNIL(t, ValidateRollUntil(D20, 20, 1))
+
+	rolls, hit = RollUntil(D6, 7, 10, PRNG_for_testing)
+	testing_utils.AssertEQb(t, false, hit)
+	testing_utils.AssertEQi(t, 0, rolls)
+}
+
+func TestAdvantage(t *testing.T) {
+	// Advantage keeps the higher and disadvantage the lower of two D20
+
+	// (5, 17)
+	initHardcodedRngNums([]int{4, 16, 4, 16})
+	testing_utils.AssertEQi(t, 17, RollAdvantage(PRNG_for_testing))
+	testing_utils.AssertEQi(t, 5, RollDisadvantage(PRNG_for_testing))
+
+	// (20, 1)
+	initHardcodedRngNums([]int{19, 0, 19, 0})
+	testing_utils.AssertEQi(t, 20, RollAdvantage(PRNG_for_testing))
+	testing_utils.AssertEQi(t, 1, RollDisadvantage(PRNG_for_testing))
+
+	// Both raw rolls
+	initHardcodedRngNums([]int{11, 11})
+	roll1, roll2 := RollD20Pair(PRNG_for_testing)
+	testing_utils.AssertEQi(t, 12, roll1)
+	testing_utils.AssertEQi(t, 12, roll2)
+}
+
+func TestWeightedDiceRoll(t *testing.T) {
+	// Test validation and weighted selection of loaded dice faces
+
+	// (-) Negative weight
+	ok, err := NewWeightedDiceRoll(3, []int{1, -1, 2}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, "invalid weighted dice: face weights must not be negative, face 2 has weight -1", err.Error())
+
+	// (-) All zero weights
+	ok, err = NewWeightedDiceRoll(3, []int{0, 0, 0}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrZeroWeights.Error(), err.Error())
+
+	// (-) No faces
+	ok, err = NewWeightedDiceRoll(3, []int{}).validate()
+	testing_utils.AssertEQb(t, false, ok)
+	testing_utils.AssertEQ(t, ErrZeroWeights.Error(), err.Error())
+
+	// (+) Impossible faces are allowed
+	weightedDiceRoll := NewWeightedDiceRoll(8, []int{1, 0, 2, 5})
+	ok, err = weightedDiceRoll.validate()
+	testing_utils.AssertEQb(t, true, ok)
+	testing_utils.AssertNIL(t, err)
+
+	testing_utils.AssertEQSlice(t, []int{1, 1, 3, 8}, weightedDiceRoll.cumulativeWeights())
+
+	// Positions in [0, 8) map onto the cumulative weights
+	//
+	// face 1 : [0, 1)
+	// face 2 : never
+	// face 3 : [1, 3)
+	// face 4 : [3, 8)
+	initHardcodedRngNums([]int{0, 1, 2, 3, 7, 4, 6, 5})
+	weightedDiceRoll.prng = PRNG_for_testing
+	res := weightedDiceRoll.roll()
+
+	testing_utils.AssertEQi(t, 1, res["1"])
+	testing_utils.AssertEQi(t, 0, res["2"])
+	testing_utils.AssertEQi(t, 2, res["3"])
+	testing_utils.AssertEQi(t, 5, res["4"])
+
+	// Every face is displayed with its expected frequency
+	origStdout, r, w := testing_utils.RedirectStdout()
+	weightedDiceRoll.display(res)
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Face :   Observed   :   Expected   : Count\n" +
+			"[1]  :  12.500000%  :  12.500000%  : 1\n" +
+			"[2]  :   0.000000%  :   0.000000%  : 0\n" +
+			"[3]  :  25.000000%  :  25.000000%  : 2\n" +
+			"[4]  :  62.500000%  :  62.500000%  : 5\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+
+	// Completed runs are passed to RecordRun
+	recorded := ""
+	RecordRun = func(eventType string, numEvents int, res map[string]int) {
+		recorded = fmt.Sprintf("%s %d %d", eventType, numEvents, res["4"])
+	}
+	defer func() { RecordRun = nil }()
+
+	initHardcodedRngNums([]int{0, 1, 2, 3, 7, 4, 6, 5})
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	_, err = weightedDiceRoll.execute()
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQ(t, "Weighted D4 8 5", recorded)
+}
+
+func TestBiasedCoinFlip(t *testing.T) {
+	// Test validation and biased selection of Heads
+
+	// (-) Probability outside [0,1]
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		ok, err := NewBiasedCoinFlip(4, p).validate()
+		testing_utils.AssertEQb(t, false, ok)
+		testing_utils.AssertEQ(t, ErrInvalidHeadsProbability.Error(), err.Error())
+	}
+
+	// (+) Certain outcomes are allowed
+	for _, p := range []float64{0, 1} {
+		ok, err := NewBiasedCoinFlip(4, p).validate()
+		testing_utils.AssertEQb(t, true, ok)
+		testing_utils.AssertNIL(t, err)
+	}
+
+	// Positions below 700000 of the 1000000 land on Heads
+	biasedCoinFlip := NewBiasedCoinFlip(4, 0.7)
+	testing_utils.AssertEQi(t, 700000, biasedCoinFlip.headsThreshold())
+	initHardcodedRngNums([]int{0, 699999, 700000, 999999})
+	biasedCoinFlip.prng = PRNG_for_testing
+	res := biasedCoinFlip.flip()
+	testing_utils.AssertEQi(t, 2, res[Heads])
+	testing_utils.AssertEQi(t, 2, res[Tails])
+
+	// A certain coin never lands on the other face
+	initHardcodedRngNums([]int{0, 999999})
+	biasedCoinFlip = NewBiasedCoinFlip(2, 1)
+	biasedCoinFlip.prng = PRNG_for_testing
+	testing_utils.AssertEQi(t, 2, biasedCoinFlip.flip()[Heads])
+
+	initHardcodedRngNums([]int{0, 999999})
+	biasedCoinFlip = NewBiasedCoinFlip(2, 0)
+	biasedCoinFlip.prng = PRNG_for_testing
+	testing_utils.AssertEQi(t, 2, biasedCoinFlip.flip()[Tails])
+
+	// Observed and theoretical percents side by side
+	origStdout, r, w := testing_utils.RedirectStdout()
+	NewBiasedCoinFlip(4, 0.7).display(map[string]int{Heads: 3, Tails: 1})
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	expected :=
+		"Face :   Observed   :  Theoretical : Count\n" +
+			"(H)  :  75.000000%  :  70.000000%  : 3\n" +
+			"(T)  :  25.000000%  :  30.000000%  : 1\n\n"
+	testing_utils.AssertEQ(t, expected, output)
+}
+
+func TestResult(t *testing.T) {
+	// Results keep the order of their outcomes and their event type
+
+	result := NewResult(DiceEventType(D4), possibleDiceValues(D4), map[string]int{"2": 1, "4": 3})
+	testing_utils.AssertEQ(t, "D4", result.EventType)
+	testing_utils.AssertEQSlice(t, []string{"1", "2", "3", "4"}, result.Outcomes)
+	testing_utils.AssertEQ(t, "map[1:0 2:1 3:0 4:3]", fmt.Sprint(result.Counts))
+	testing_utils.AssertEQi(t, 4, result.NumEvents)
+	testing_utils.AssertEQf(t, 75, result.Percent("4"), 1e-9)
+	testing_utils.AssertEQf(t, 25, result.Percent("2"), 1e-9)
+	testing_utils.AssertEQf(t, 0, result.Percent("1"), 1e-9)
+	testing_utils.AssertEQf(t, 0, result.Percent("7"), 1e-9)
+	testing_utils.AssertEQ(t, "4", result.Top())
+
+	// Ties go to the first outcome in order, here Tails before Heads
+	result = NewResult(CoinEventType, []string{Tails, Heads}, map[string]int{Heads: 2, Tails: 2})
+	testing_utils.AssertEQ(t, Tails, result.Top())
+	testing_utils.AssertEQf(t, 50, result.Percent(Heads), 1e-9)
+
+	// Outcomes counted but not listed are kept after the listed ones
+	result = NewResult(CoinEventType, []string{Heads}, map[string]int{Tails: 1, Heads: 1, "Edge": 1})
+	testing_utils.AssertEQSlice(t, []string{Heads, "Edge", Tails}, result.Outcomes)
+	testing_utils.AssertEQi(t, 3, result.NumEvents)
+
+	// No events
+	result = NewResult(CoinEventType, []string{Heads, Tails}, map[string]int{})
+	testing_utils.AssertEQ(t, "", result.Top())
+	testing_utils.AssertEQf(t, 0, result.Percent(Heads), 1e-9)
+
+	// Generated results count every event
+	result, err := GenerateResult(CoinEventType, 10, []string{Heads, Tails})
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 10, result.NumEvents)
+	testing_utils.AssertEQSlice(t, []string{Heads, Tails}, result.Outcomes)
+	testing_utils.AssertEQb(t, true, result.Top() != "")
+
+	_, err = GenerateResult(CoinEventType, 10, []string{})
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidPossibilities))
+}
+
+func TestBiasedConvergence(t *testing.T) {
+	// The observed heads percent converges towards the bias, 70%, rather
+	// than towards 50%. Positions below 700000 land on Heads:
+	//
+	// H T H H T | H H T H H
+
+	positions := []int{0, 800000, 100, 699999, 900000, 5, 5, 700000, 1, 2}
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = stdout{} }()
+
+	initHardcodedRngNums(positions)
+	heads, err := ExecuteBiasedConvergence(10, 0.7, PRNG_for_testing)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQf(t, 70, heads, 1e-9)
+	expected :=
+		"Flips      :   Observed   :  Theoretical\n" +
+			"1          : 100.000000%  :  70.000000%\n" +
+			"5          :  60.000000%  :  70.000000%\n" +
+			"10         :  70.000000%  :  70.000000%\n\n"
+	testing_utils.AssertEQ(t, expected, buf.String())
+
+	// Same flips reported directly at each checkpoint
+	biasedCoinFlip := NewBiasedCoinFlip(10, 0.7)
+	initHardcodedRngNums(positions)
+	biasedCoinFlip.prng = PRNG_for_testing
+	testing_utils.AssertEQSlice(
+		t,
+		[]float64{50, 75, 60, 70},
+		biasedCoinFlip.headsPercentAtFlips([]int{2, 4, 5, 10}))
+
+	// Logarithmic checkpoints are shared with ExecuteConvergence
+	LogConvergence = true
+	defer func() { LogConvergence = false }()
+	buf.Reset()
+	initHardcodedRngNums(positions)
+	_, err = ExecuteBiasedConvergence(10, 0.7, PRNG_for_testing)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQ(
+		t,
+		"Flips      :   Observed   :  Theoretical\n"+
+			"10         :  70.000000%  :  70.000000%\n\n",
+		buf.String())
+
+	// (-) Invalid runs print nothing
+	buf.Reset()
+	_, err = ExecuteBiasedConvergence(10, 1.5, PRNG_for_testing)
+	testing_utils.AssertEQ(t, ErrInvalidHeadsProbability.Error(), err.Error())
+	_, err = ExecuteBiasedConvergence(0, 0.7, PRNG_for_testing)
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidEvents))
+	testing_utils.AssertEQ(t, "", buf.String())
+}
+
+func TestColorVisuals(t *testing.T) {
+	// Visuals are plain when color is disabled and only gain escape
+	// codes when it is enabled
+
+	origColor := UseColor
+
+	UseColor = false
+	testing_utils.AssertEQ(t, coinVisuals[H], coinVisual(H))
+	testing_utils.AssertEQ(t, coinVisuals[T], coinVisual(T))
+	for res, visual := range d6Visuals {
+		testing_utils.AssertEQ(t, visual, d6Visual(res))
+	}
+
+	origStdout, r, w := testing_utils.RedirectStdout()
+	res := ExecuteAndDisplayOneRollActionWith(D6, func(int) int { return r4 })
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQi(t, r4, res)
+	testing_utils.AssertEQ(t, d6Visuals[r4], output)
+
+	UseColor = true
+	testing_utils.AssertEQ(
+		t,
+		ColorYellow+" -----"+ColorReset+"\n"+
+			ColorYellow+"/     \\"+ColorReset+"\n"+
+			ColorYellow+"|  H  |"+ColorReset+"\n"+
+			ColorYellow+"\\     /"+ColorReset+"\n"+
+			ColorYellow+" -----"+ColorReset+"\n",
+		coinVisual(H))
+	testing_utils.AssertEQb(t, true, strings.HasPrefix(coinVisual(T), ColorCyan))
+	testing_utils.AssertEQ(
+		t,
+		" -------\n"+
+			"|       |\n"+
+			"|   "+PipColor+"o"+ColorReset+"   |\n"+
+			"|       |\n"+
+			" -------\n",
+		d6Visual(r1))
+
+	UseColor = origColor
+
+	// NO_COLOR always disables color
+	t.Setenv("NO_COLOR", "1")
+	testing_utils.AssertEQb(t, false, colorSupported())
+}
+
+func TestProgress(t *testing.T) {
+	// Progress is reported once per interval of the events consumed
+
+	calls, last := 0, 0
+	progress := func(consumed int, total int) {
+		calls++
+		last = consumed
+		testing_utils.AssertEQi(t, 100, total)
+	}
+
+	// 100 flips every 5% -> 20 reports
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	res, err := ValidateAndExecuteWithProgress(NewCoinFlip(100), progress)
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 100, res[Heads]+res[Tails])
+	testing_utils.AssertEQi(t, 20, calls)
+	testing_utils.AssertEQi(t, 100, last)
+
+	// The hook is only in place for the run
+	testing_utils.AssertEQb(t, true, Progress == nil)
+
+	// 100 events every 12.5% -> step of 13, reports at 13, 26, ..., 91
+	origInterval := ProgressInterval
+	ProgressInterval = 0.125
+	calls, last = 0, 0
+	Progress = progress
+	pe := ProbEvent{numEvents: 100, outcomes: []string{Heads, Tails}, prng: RandNumGen}
+	pe.computeProbability()
+	testing_utils.AssertEQi(t, 7, calls)
+	testing_utils.AssertEQi(t, 91, last)
+	Progress, ProgressInterval = nil, origInterval
+
+	// Parallel workers report in batches, never more than once per interval
+	var mu sync.Mutex
+	calls, last = 0, 0
+	Progress = func(consumed int, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		last = max(last, consumed)
+	}
+	pe = ProbEvent{numEvents: 10000, outcomes: []string{Heads, Tails}, prng: RandNumGen}
+	pe.computeProbabilityParallel(4)
+	Progress = nil
+	testing_utils.AssertEQb(t, true, calls > 0 && calls <= 20)
+	testing_utils.AssertEQi(t, 10000, last)
+
+	testing_utils.AssertEQi(t, 1, progressStep(10, 0.05))
+	testing_utils.AssertEQi(t, 50000, progressStep(1000000, 0.05))
+}
+
+func TestRollSequence(t *testing.T) {
+	// Rolls are kept in the order rolled
+
+	initHardcodedRngNums([]int{3, 0, 5, 11, 1})
+	rolls, err := ExecuteRollSequence(D6, 5, PRNG_for_testing)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQSlice(t, []int{4, 1, 6, 6, 2}, rolls)
+	testing_utils.AssertEQ(t, "4, 1, 6, 6, 2", FormatSequence(rolls))
+
+	// (-) Invalid dice type
+	_, err = ExecuteRollSequence(7, 5, PRNG_for_testing)
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), err.Error())
+
+	// (-) No rolls
+	rolls, err = ExecuteRollSequence(D6, 0, PRNG_for_testing)
+	testing_utils.AssertEQ(t, ErrInvalidEvents.Error(), err.Error())
+	testing_utils.AssertEQSlice(t, nil, rolls)
+
+	testing_utils.AssertEQ(t, "", FormatSequence([]int{}))
+}
+
+func TestMinMax(t *testing.T) {
+	// Extremes of an injected roll sequence
+
+	initHardcodedRngNums([]int{3, 0, 5, 5, 1})
+	rolls, err := ExecuteRollSequence(D6, 5, PRNG_for_testing)
+	testing_utils.AssertNIL(t, err)
+	min, max := MinMax(rolls)
+	testing_utils.AssertEQi(t, 1, min)
+	testing_utils.AssertEQi(t, 6, max)
+
+	// A single roll is both extremes
+	initHardcodedRngNums([]int{3})
+	rolls, _ = ExecuteRollSequence(D6, 1, PRNG_for_testing)
+	min, max = MinMax(rolls)
+	testing_utils.AssertEQi(t, 4, min)
+	testing_utils.AssertEQi(t, 4, max)
+
+	// Nothing rolled
+	min, max = MinMax([]int{})
+	testing_utils.AssertEQi(t, 0, min)
+	testing_utils.AssertEQi(t, 0, max)
+}
+
+func TestRollCheck(t *testing.T) {
+	// A check succeeds when the roll plus the modifier reaches the DC
+
+	// Exactly meets the DC
+	initHardcodedRngNums([]int{11})
+	testing_utils.AssertEQ(
+		t, fmt.Sprintf("%+v", CheckResult{Roll: 12, Total: 15, Success: true}),
+		fmt.Sprintf("%+v", RollCheck(D20, 3, 15, PRNG_for_testing)))
+
+	// Beats the DC
+	initHardcodedRngNums([]int{16})
+	testing_utils.AssertEQ(
+		t, fmt.Sprintf("%+v", CheckResult{Roll: 17, Total: 17, Success: true}),
+		fmt.Sprintf("%+v", RollCheck(D20, 0, 15, PRNG_for_testing)))
+
+	// Fails, a negative modifier pulls the total below the DC
+	initHardcodedRngNums([]int{14})
+	testing_utils.AssertEQ(
+		t, fmt.Sprintf("%+v", CheckResult{Roll: 15, Total: 14}),
+		fmt.Sprintf("%+v", RollCheck(D20, -1, 15, PRNG_for_testing)))
+
+	// (-) Invalid die rolls nothing
+	testing_utils.AssertEQ(
+		t, fmt.Sprintf("%+v", CheckResult{}),
+		fmt.Sprintf("%+v", RollCheck(7, 0, 1, PRNG_for_testing)))
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidateCheck(7).Error())
+	testing_utils.AssertNIL(t, ValidateCheck(D20))
+}
+
+func TestRollCheckNatural(t *testing.T) {
+	// Naturals key off the unmodified D20 roll, not the total
+
+	// A natural 1 is flagged even when the modifier still beats the DC
+	initHardcodedRngNums([]int{0})
+	result := RollCheck(D20, 15, 10, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 16, result.Total)
+	testing_utils.AssertEQb(t, true, result.Success)
+	testing_utils.AssertEQb(t, true, result.Natural1)
+	testing_utils.AssertEQb(t, false, result.Natural20)
+
+	// A natural 20 is flagged even when the modifier misses the DC
+	initHardcodedRngNums([]int{19})
+	result = RollCheck(D20, -5, 25, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 15, result.Total)
+	testing_utils.AssertEQb(t, false, result.Success)
+	testing_utils.AssertEQb(t, false, result.Natural1)
+	testing_utils.AssertEQb(t, true, result.Natural20)
+
+	// A total of 20 from a modifier is not a natural 20
+	initHardcodedRngNums([]int{14})
+	result = RollCheck(D20, 5, 10, PRNG_for_testing)
+	testing_utils.AssertEQb(t, false, result.Natural20)
+
+	// Only a D20 has naturals
+	initHardcodedRngNums([]int{0})
+	result = RollCheck(D6, 0, 1, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 1, result.Roll)
+	testing_utils.AssertEQb(t, false, result.Natural1)
+}
+
+func TestRunBatch(t *testing.T) {
+	// Each spec runs in order and returns its own results
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+	defer testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	specs := []ExperimentSpec{{CoinEventType, 1000}, {"D6", 1000}, {"D20", 1000}}
+	results, err := RunBatch(specs)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, len(specs), len(results))
+
+	expected := [][]string{{Heads, Tails}, possibleDiceValues(D6), possibleDiceValues(D20)}
+	for i, res := range results {
+		total := 0
+		for outcome, count := range res {
+			testing_utils.AssertEQb(t, true, slices.Contains(expected[i], outcome))
+			total += count
+		}
+		testing_utils.AssertEQi(t, specs[i].NumEvents, total)
+	}
+
+	// (-) Stops at the first invalid experiment, keeping earlier results
+	results, err = RunBatch([]ExperimentSpec{{"D6", 10}, {"D7", 10}, {"D20", 10}})
+	testing_utils.AssertEQ(t, "experiment 1: "+ErrInvalidDiceType.Error(), err.Error())
+	testing_utils.AssertEQi(t, 1, len(results))
+
+	_, err = RunBatch([]ExperimentSpec{{"spinner", 10}})
+	testing_utils.AssertEQ(
+		t, "experiment 0: unknown event type 'spinner': expected 'coin' or a dice type. Ex: D6", err.Error())
+
+	_, err = RunBatch([]ExperimentSpec{{CoinEventType, 0}})
+	testing_utils.AssertEQ(t, "experiment 0: "+ErrInvalidEvents.Error(), err.Error())
+}
+
+func TestProbAtLeastOne(t *testing.T) {
+	// Closed form chance of the target face coming up at least once
+
+	// A 6 in 4 rolls of a D6, de Méré's bet
+	testing_utils.AssertEQf(t, 1-625.0/1296, ProbAtLeastOne(D6, 6, 4), 1e-12)
+	testing_utils.AssertEQf(t, 0.517747, ProbAtLeastOne(D6, 6, 4), 1e-6)
+
+	// A single roll is the chance of the face itself
+	testing_utils.AssertEQf(t, 1.0/6, ProbAtLeastOne(D6, 1, 1), 1e-12)
+	testing_utils.AssertEQf(t, 0.05, ProbAtLeastOne(D20, 20, 1), 1e-12)
+
+	// A 4 in 2 rolls of a D4
+	testing_utils.AssertEQf(t, 1-9.0/16, ProbAtLeastOne(D4, 4, 2), 1e-12)
+
+	// (-) Invalid arguments have probability 0
+	testing_utils.AssertEQf(t, 0, ProbAtLeastOne(7, 1, 4), 0)
+	testing_utils.AssertEQf(t, 0, ProbAtLeastOne(D6, 7, 4), 0)
+	testing_utils.AssertEQf(t, 0, ProbAtLeastOne(D6, 6, 0), 0)
+
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidateAtLeastOne(7, 1, 4).Error())
+	testing_utils.AssertEQ(t, "invalid target face: must be in range [1,6]", ValidateAtLeastOne(D6, 0, 4).Error())
+	testing_utils.AssertEQ(t, ErrInvalidTrials.Error(), ValidateAtLeastOne(D6, 6, 0).Error())
+
+	testing_utils.AssertEQ(t, "51.774691%", FormatPercent(ProbAtLeastOne(D6, 6, 4)*100))
+}
+
+func TestProbExactHeads(t *testing.T) {
+	// Binomial chance of exactly a number of heads with a fair coin
+
+	testing_utils.AssertEQf(t, 0.3125, ProbExactHeads(5, 3), 1e-12)
+	testing_utils.AssertEQf(t, 0.5, ProbExactHeads(1, 1), 1e-12)
+	testing_utils.AssertEQf(t, 1.0/1024, ProbExactHeads(10, 0), 1e-12)
+	testing_utils.AssertEQf(t, 252.0/1024, ProbExactHeads(10, 5), 1e-12)
+
+	// Every number of heads adds up to certainty
+	total := 0.0
+	for heads := 0; heads <= 20; heads++ {
+		total += ProbExactHeads(20, heads)
+	}
+	testing_utils.AssertEQf(t, 1, total, 1e-9)
+
+	// Large numbers of flips do not overflow
+	testing_utils.AssertEQf(t, 0.0079786, ProbExactHeads(10000, 5000), 1e-7)
+
+	// (-) Invalid arguments have probability 0
+	testing_utils.AssertEQf(t, 0, ProbExactHeads(5, 6), 0)
+	testing_utils.AssertEQf(t, 0, ProbExactHeads(5, -1), 0)
+	testing_utils.AssertEQf(t, 0, ProbExactHeads(0, 0), 0)
+
+	testing_utils.AssertEQ(t, ErrInvalidEvents.Error(), ValidateExactHeads(0, 0).Error())
+	testing_utils.AssertEQ(t, "invalid number of heads: must be in range [0,5]", ValidateExactHeads(5, 6).Error())
+	testing_utils.AssertEQb(t, true, errors.Is(ValidateExactHeads(5, -1), ErrInvalidHeads))
+}
+
+func TestCountSuccesses(t *testing.T) {
+	// Dice at or above the threshold are successes
+
+	// 5d10 rolling 3, 8, 10, 7, 1 with threshold 8
+	initHardcodedRngNums([]int{2, 7, 9, 6, 0})
+	hits, rolls := CountSuccesses(5, D10, 8, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 2, hits)
+	testing_utils.AssertEQSlice(t, []int{3, 8, 10, 7, 1}, rolls)
+
+	// Thresholds at the edges of the dice
+	initHardcodedRngNums([]int{0, 5})
+	hits, _ = CountSuccesses(2, D6, 1, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 2, hits)
+
+	initHardcodedRngNums([]int{0, 5})
+	hits, _ = CountSuccesses(2, D6, 6, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 1, hits)
+
+	// (-) Invalid arguments roll nothing
+	hits, rolls = CountSuccesses(2, D6, 7, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 0, hits)
+	testing_utils.AssertEQSlice(t, nil, rolls)
+
+	testing_utils.AssertEQ(t, "invalid success threshold: must be in range [1,6]", ValidateSuccesses(2, D6, 0).Error())
+	testing_utils.AssertEQ(t, ErrInvalidNumDice.Error(), ValidateSuccesses(0, D6, 1).Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidateSuccesses(2, 7, 1).Error())
+
+	// (-) At most as many dice as events in a run
+	SetMaxEvents(3)
+	testing_utils.AssertNIL(t, ValidateSuccesses(3, D6, 1))
+	testing_utils.AssertEQ(t, "too many events: at most 3, see SetMaxEvents", ValidateSuccesses(4, D6, 1).Error())
+	SetMaxEvents(DefaultMaxEvents)
+}
+
+func TestRollPool(t *testing.T) {
+	// 4d6 drop the lowest
+
+	initHardcodedRngNums([]int{2, 5, 0, 3})
+	kept, dropped, total := RollPool(4, D6, 3, PRNG_for_testing)
+	testing_utils.AssertEQSlice(t, []int{6, 4, 3}, kept)
+	testing_utils.AssertEQSlice(t, []int{1}, dropped)
+	testing_utils.AssertEQi(t, 13, total)
+
+	// Keeping every die drops nothing
+	initHardcodedRngNums([]int{2, 5})
+	kept, dropped, total = RollPool(2, D6, 2, PRNG_for_testing)
+	testing_utils.AssertEQSlice(t, []int{6, 3}, kept)
+	testing_utils.AssertEQSlice(t, []int{}, dropped)
+	testing_utils.AssertEQi(t, 9, total)
+
+	// (-) Keeping more dice than rolled rolls nothing
+	kept, dropped, total = RollPool(2, D6, 3, PRNG_for_testing)
+	testing_utils.AssertEQSlice(t, nil, kept)
+	testing_utils.AssertEQSlice(t, nil, dropped)
+	testing_utils.AssertEQi(t, 0, total)
+
+	testing_utils.AssertEQ(t, "invalid number of dice kept: must be in range [1,2]", ValidatePool(2, D6, 3).Error())
+	testing_utils.AssertEQ(t, "invalid number of dice kept: must be in range [1,2]", ValidatePool(2, D6, 0).Error())
+	testing_utils.AssertEQ(t, ErrInvalidNumDice.Error(), ValidatePool(0, D6, 0).Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidatePool(4, 7, 3).Error())
+}
+
+func TestErrorsIs(t *testing.T) {
+	// Test that errors returned with context still match their sentinel
+
+	err := ValidateRollUntil(D6, 7, 10)
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidTargetFace))
+	testing_utils.AssertEQb(t, false, errors.Is(err, ErrInvalidDiceType))
+
+	err = ValidatePool(2, D6, 3)
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidKeep))
+
+	// Wrapped once by validation and again by the batch
+	SetMaxEvents(10)
+	_, err = RunBatch([]ExperimentSpec{{"D6", 5}, {CoinEventType, 11}})
+	SetMaxEvents(DefaultMaxEvents)
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrTooManyEvents))
+	testing_utils.AssertEQb(t, false, errors.Is(err, ErrInvalidEvents))
+
+	_, err = RunBatch([]ExperimentSpec{{"spinner", 10}})
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrUnknownEventType))
+
+	_, err = NewSpinner(3, []Segment{{"Car", 1}, {"Car", 2}}).validate()
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrDuplicateLabel))
+
+	// Sentinels sharing a message prefix remain distinct
+	_, err = NewSpinner(3, []Segment{{"Car", 0}}).validate()
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidWeight))
+	testing_utils.AssertEQb(t, false, errors.Is(err, ErrDuplicateLabel))
+}
+
+func TestListDiceTypes(t *testing.T) {
+	// Test that the listed dice types are exactly the valid ones
+
+	expected := []string{"D4: 1–4", "D6: 1–6", "D10: 1–10", "D12: 1–12", "D20: 1–20"}
+	testing_utils.AssertEQSlice(t, expected, ListDiceTypes())
+
+	// Every dice type up to the largest is listed if and only if valid
+	listed := strings.Join(ListDiceTypes(), "\n") + "\n"
+	for dType := -1; dType <= D20+1; dType++ {
+		testing_utils.AssertEQb(
+			t,
+			validDiceType(dType),
+			strings.Contains(listed, fmt.Sprintf("D%d: 1–%d\n", dType, dType)))
+	}
+
+	// The error message names the same dice types
+	sides := []string{}
+	for _, dType := range diceTypes {
+		sides = append(sides, strconv.Itoa(dType))
+	}
+	testing_utils.AssertEQ(t, "("+strings.Join(sides, ", ")+")", ValidDiceTypes)
+}
+
+func TestMixedPool(t *testing.T) {
+	// Test parsing, validating and rolling dice of different types together
+
+	dice, err := ParseNotation("1d20+2d6")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQSlice(t, []DiceSpec{{1, D20}, {2, D6}}, dice)
+
+	dice, err = ParseNotation(" D20 + d4+3D6 ")
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQSlice(t, []DiceSpec{{1, D20}, {1, D4}, {3, D6}}, dice)
+
+	// (-) Groups must be <count>d<sides>
+	for _, notation := range []string{"", "2x6", "1d20+", "ad6", "2d", "1d20+2d6+1"} {
+		_, err = ParseNotation(notation)
+		testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidNotation))
+	}
+	_, err = ParseNotation("1d20+2x6")
+	testing_utils.AssertEQ(t, "invalid dice notation '2x6': expected groups like 1d20+2d6", err.Error())
+
+	// (-) Every group is validated
+	testing_utils.AssertNIL(t, ValidateMixedPool([]DiceSpec{{1, D20}, {2, D6}}))
+	testing_utils.AssertEQ(t, ErrInvalidNumDice.Error(), ValidateMixedPool([]DiceSpec{}).Error())
+	testing_utils.AssertEQ(t, ErrInvalidNumDice.Error(), ValidateMixedPool([]DiceSpec{{1, D20}, {0, D6}}).Error())
+	testing_utils.AssertEQ(t, ErrInvalidDiceType.Error(), ValidateMixedPool([]DiceSpec{{1, D20}, {2, 7}}).Error())
+
+	// (-) At most as many dice as events in a run
+	SetMaxEvents(3)
+	testing_utils.AssertNIL(t, ValidateMixedPool([]DiceSpec{{1, D20}, {2, D6}}))
+	err = ValidateMixedPool([]DiceSpec{{2, D20}, {2, D6}})
+	testing_utils.AssertEQ(t, "too many events: at most 3, see SetMaxEvents", err.Error())
+	SetMaxEvents(DefaultMaxEvents)
+	err = ValidateMixedPool([]DiceSpec{{DefaultMaxEvents, D20}, {math.MaxInt, D6}})
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrTooManyEvents))
+
+	// 1d20+2d6+1d6 : 14, then 3 and 5, then 2 kept apart from the 2d6
+	initHardcodedRngNums([]int{13, 2, 4, 1})
+	dice = []DiceSpec{{1, D20}, {2, D6}, {1, D6}}
+	results, total := RollMixedPool(dice, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 3, len(results))
+	testing_utils.AssertEQSlice(t, []int{14}, results[0])
+	testing_utils.AssertEQSlice(t, []int{3, 5}, results[1])
+	testing_utils.AssertEQSlice(t, []int{2}, results[2])
+	testing_utils.AssertEQi(t, 24, total)
+	testing_utils.AssertEQ(t, "1d20=[14], 2d6=[3, 5], 1d6=[2]", FormatMixedPool(dice, results))
+
+	// (-) Invalid pools roll nothing
+	results, total = RollMixedPool([]DiceSpec{{1, 7}}, PRNG_for_testing)
+	testing_utils.AssertEQi(t, 0, len(results))
+	testing_utils.AssertEQi(t, 0, total)
+}
+
+func TestStreamingStats(t *testing.T) {
+	// Test the running mean and variance against a batch computation
+
+	// Known values
+	stats := StreamingStats{}
+	testing_utils.AssertEQf(t, 0, stats.Mean(), 1e-9)
+	testing_utils.AssertEQf(t, 0, stats.Variance(), 1e-9)
+	for _, value := range []int{2, 4, 4, 4, 5, 5, 7, 9} {
+		stats.Add(value)
+	}
+	testing_utils.AssertEQi(t, 8, stats.Count())
+	testing_utils.AssertEQf(t, 5, stats.Mean(), 1e-9)
+	testing_utils.AssertEQf(t, 32.0/7, stats.Variance(), 1e-9)
+
+	// Same rolls streamed and aggregated into a table
+	streamed, err := RollStats(5000, D20, NewSeededPRNG(42))
+	testing_utils.AssertNIL(t, err)
+
+	pe := ProbEvent{numEvents: 5000, outcomes: possibleDiceValues(D20), prng: NewSeededPRNG(42)}
+	res := pe.computeProbability()
+
+	sum := 0
+	for face, count := range res {
+		value, _ := strconv.Atoi(face)
+		sum += value * count
+	}
+	mean := float64(sum) / 5000
+
+	squares := 0.0
+	for face, count := range res {
+		value, _ := strconv.Atoi(face)
+		squares += float64(count) * (float64(value) - mean) * (float64(value) - mean)
+	}
+
+	testing_utils.AssertEQi(t, 5000, streamed.Count())
+	testing_utils.AssertEQf(t, mean, streamed.Mean(), 1e-9)
+	testing_utils.AssertEQf(t, squares/4999, streamed.Variance(), 1e-9)
+
+	// A single roll has no sample variance
+	streamed, err = RollStats(1, D6, NewSeededPRNG(42))
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQf(t, 0, streamed.Variance(), 1e-9)
+
+	// (-) Rolls are validated
+	_, err = RollStats(10, 7, NewSeededPRNG(42))
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidDiceType))
+	_, err = RollStats(0, D6, NewSeededPRNG(42))
+	testing_utils.AssertEQb(t, true, errors.Is(err, ErrInvalidEvents))
 }
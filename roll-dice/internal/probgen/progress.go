@@ -0,0 +1,101 @@
+/*
+progress.go
+
+Periodic progress reports of the events
+consumed during long probability runs
+*/
+package probgen
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Called periodically with the number of events consumed so far when set.
+// Used to show that long runs are still making progress
+var Progress func(consumed int, total int)
+
+// Fraction of the total number of events between progress reports
+var ProgressInterval = 0.05
+
+// Number of events a parallel worker consumes before reporting them
+const progressBatch = 1024
+
+// Tracks the events consumed across workers and reports each time another
+// interval of the total is reached
+type progressTracker struct {
+	mu       sync.Mutex
+	report   func(consumed int, total int) // Progress at the start of the run
+	total    int                           // total number of events
+	step     int                           // events between reports
+	consumed int                           // events consumed so far
+	next     int                           // consumed count of the next report
+}
+
+// Create a tracker for a run, or nil if there is no Progress to report to.
+// A nil tracker ignores every update so the run pays almost nothing
+//
+//	Params
+//		total int : total number of events in the run
+//	Returns
+//		*progressTracker : the tracker, nil without Progress
+func newProgressTracker(total int) *progressTracker {
+	if Progress == nil || total < 1 {
+		return nil
+	}
+
+	step := progressStep(total, ProgressInterval)
+
+	return &progressTracker{report: Progress, total: total, step: step, next: step}
+}
+
+// Number of events between progress reports, always at least one event
+//
+//	Params
+//		total int          : total number of events
+//		interval float64   : fraction of total between reports
+//	Returns
+//		int : events between reports
+func progressStep(total int, interval float64) int {
+	return max(int(math.Ceil(float64(total)*interval)), 1)
+}
+
+// Count consumed events, reporting once if another interval was reached
+//
+//	Params
+//		n int : number of events consumed since the last call
+func (tracker *progressTracker) add(n int) {
+	if tracker == nil {
+		return
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	tracker.consumed += n
+	if tracker.consumed < tracker.next {
+		return
+	}
+
+	// Skip over every interval reached at once
+	for tracker.next <= tracker.consumed {
+		tracker.next += tracker.step
+	}
+
+	tracker.report(tracker.consumed, tracker.total)
+}
+
+// Print the progress of runs that are long enough to appear stuck. Meant to
+// be assigned to Progress
+//
+// Ex: Progress :  45% (450000/1000000)
+//
+//	Params
+//		consumed int : events consumed so far
+//		total int    : total number of events
+func PrintProgress(consumed int, total int) {
+	if total >= ParallelThreshold {
+		fmt.Fprintf(Output, "Progress : %3.0f%% (%d/%d)\n", Percent(consumed, total), consumed, total)
+	}
+}
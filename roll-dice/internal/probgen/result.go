@@ -0,0 +1,102 @@
+/*
+result.go
+
+Results of a run kept together with the
+order of their outcomes and their event
+type, rather than as a bare table
+*/
+package probgen
+
+// Aggregated results of a run
+//
+//	Ex: {CoinEventType, [Heads, Tails], {Heads: 2, Tails: 1}, 3}
+type Result struct {
+	EventType string         // type of the events. Ex: CoinEventType or "D6"
+	Outcomes  []string       // every possible outcome, in display order
+	Counts    map[string]int // number of times each outcome occurred
+	NumEvents int            // number of events in the run
+}
+
+// Build the result of a run from its table of counts. Outcomes counted but
+// missing from the ordered outcomes are kept after them, in SortedOutcomes
+// order
+//
+//	Params
+//		eventType string   : type of the events. Ex: DiceEventType(6)
+//		outcomes []string  : every possible outcome, in display order
+//		res map[string]int : number of times each outcome occurred
+//	Returns
+//		Result : the results, counting every outcome
+func NewResult(eventType string, outcomes []string, res map[string]int) Result {
+	result := Result{
+		EventType: eventType,
+		Outcomes:  append([]string{}, outcomes...),
+		Counts:    make(map[string]int, len(res)),
+	}
+
+	for _, outcome := range outcomes {
+		result.Counts[outcome] = res[outcome]
+	}
+
+	for _, outcome := range SortedOutcomes(res) {
+		if _, ok := result.Counts[outcome]; !ok {
+			result.Outcomes = append(result.Outcomes, outcome)
+			result.Counts[outcome] = res[outcome]
+		}
+	}
+
+	for _, count := range result.Counts {
+		result.NumEvents += count
+	}
+
+	return result
+}
+
+// Same as GenerateProbabilisticEvent, keeping the results with their
+// outcome order and event type
+//
+//	Params
+//		eventType string       : type of the events. Ex: CoinEventType
+//		events int             : number of probability events taking place
+//		possibilities []string : all the possible outcomes, in display order
+//	Returns
+//		Result : the results of the events
+//		error  : any errors encountered
+func GenerateResult(eventType string, events int, possibilities []string) (Result, error) {
+	res, err := GenerateProbabilisticEvent(events, possibilities)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return NewResult(eventType, possibilities, res), nil
+}
+
+// Percent of the events with the given outcome
+//
+//	Params
+//		outcome string : the outcome. Ex: Heads
+//	Returns
+//		float64 : percent in [0, 100], 0 for an unknown outcome or no events
+func (result Result) Percent(outcome string) float64 {
+	if result.NumEvents == 0 {
+		return 0
+	}
+
+	return float64(result.Counts[outcome]) * 100 / float64(result.NumEvents)
+}
+
+// Outcome that occurred most often. Ties go to the first in Outcomes order,
+// unlike topOutcome which orders by SortedOutcomes
+//
+//	Returns
+//		string : the most frequent outcome, "" if there are no events
+func (result Result) Top() string {
+	top, count := "", 0
+	for _, outcome := range result.Outcomes {
+		if result.Counts[outcome] > count {
+			top, count = outcome, result.Counts[outcome]
+		}
+	}
+
+	return top
+}
@@ -0,0 +1,193 @@
+/*
+spinner.go
+
+Spinner is a ProbEventType which
+describes spins of a wheel with labeled
+segments of different weights
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var ErrInvalidSegments = errors.New("invalid spinner: must have at least one segment")
+var ErrEmptyLabel = errors.New("invalid spinner: segment labels must not be empty")
+var ErrDuplicateLabel = errors.New("invalid spinner: duplicate segment label")
+var ErrInvalidWeight = errors.New("invalid spinner: segment weights must be positive and finite")
+
+// Number of evenly spaced positions a spin can land on. The prng is asked
+// for a position in [0, SpinResolution) which is scaled into [0, 1)
+const SpinResolution = 1 << 30
+
+// Labeled segment of a spinner with a relative weight
+type Segment struct {
+	Label  string  // name displayed for the segment
+	Weight float64 // relative odds of landing on the segment
+}
+
+type Spinner struct {
+	numEvents int           // number of spins
+	segments  []Segment     // all segments of the spinner in display order
+	prng      func(int) int // The Pseudo Random Number Generator to use
+}
+
+// Initialize private fields
+//
+//	Params
+//		nEvents int          : number of Spinner events
+//		segments []Segment   : labeled and weighted segments of the spinner
+//	Returns
+//		*Spinner : new Spinner object
+func NewSpinner(nEvents int, segments []Segment) *Spinner {
+	return &Spinner{
+		numEvents: nEvents,
+		segments:  segments,
+		prng:      RandNumGen,
+	}
+}
+
+func (spinner Spinner) validate() (bool, error) {
+	if len(spinner.segments) < 1 {
+		return false, ErrInvalidSegments
+	}
+
+	// Labels must be non empty and unique, weights must be positive and
+	// finite. NaN fails every comparison, so it is rejected as not positive
+	labels := make(map[string]bool)
+	for _, segment := range spinner.segments {
+		if segment.Label == "" {
+			return false, ErrEmptyLabel
+		}
+
+		if labels[segment.Label] {
+			return false, fmt.Errorf("%w '%s'", ErrDuplicateLabel, segment.Label)
+		}
+
+		if !(segment.Weight > 0) || math.IsInf(segment.Weight, 0) {
+			return false, fmt.Errorf("%w, '%s' has weight %g", ErrInvalidWeight, segment.Label, segment.Weight)
+		}
+
+		labels[segment.Label] = true
+	}
+
+	return true, nil
+}
+
+func (spinner Spinner) execute() (map[string]int, error) {
+	res := spinner.spin()
+	spinner.display(res)
+
+	return res, nil
+}
+
+// Spin the spinner numEvents times
+//
+//	Returns
+//		map[string]int : number of times each label was landed on
+func (spinner Spinner) spin() map[string]int {
+	labels := make([]string, len(spinner.segments))
+	for i, segment := range spinner.segments {
+		labels[i] = segment.Label
+	}
+
+	pe := ProbEvent{
+		numEvents: spinner.numEvents,
+		outcomes:  labels,
+		prng:      spinner.weightedPrng()}
+
+	return pe.computeProbability()
+}
+
+// Wrap the prng so that it selects a segment index based on the weights
+// instead of uniformly over the outcomes
+//
+//	Returns
+//		func(int) int : prng compatible weighted segment selection
+func (spinner Spinner) weightedPrng() func(int) int {
+	cumulative := spinner.cumulativeWeights()
+
+	return func(int) int {
+		position := float64(spinner.prng(SpinResolution)) / SpinResolution
+
+		return sort.Search(len(cumulative), func(i int) bool {
+			return cumulative[i] > position
+		})
+	}
+}
+
+// Normalize the weights of the segments and accumulate them
+//
+//	Ex: weights {1, 1, 2} -> {0.25, 0.5, 1}
+//
+//	Returns
+//		[]float64 : cumulative normalized weights, the last always 1
+func (spinner Spinner) cumulativeWeights() []float64 {
+	cumulative := make([]float64, len(spinner.segments))
+	total, running := spinner.totalWeight(), 0.0
+
+	for i, segment := range spinner.segments {
+		running += segment.Weight
+		cumulative[i] = running / total
+	}
+
+	// Guard against floating point error leaving a gap below 1
+	cumulative[len(cumulative)-1] = 1
+
+	return cumulative
+}
+
+// Sum of all segment weights
+//
+//	Returns
+//		float64 : total weight
+func (spinner Spinner) totalWeight() float64 {
+	total := 0.0
+	for _, segment := range spinner.segments {
+		total += segment.Weight
+	}
+
+	return total
+}
+
+// Print the spinner results with the expected frequencies. Example:
+//
+// numEvents: 4
+//
+// segments: {"Car", 1}, {"Nothing", 3}
+//
+// Segment    :   Observed   :   Expected   : Count
+//
+// Car        :   0.000000%  :  25.000000%  : 0
+//
+// Nothing    : 100.000000%  :  75.000000%  : 4
+//
+//	Params
+//		res map[string]int : results of spins
+func (spinner Spinner) display(res map[string]int) {
+	total := spinner.totalWeight()
+
+	fmt.Fprintf(Output, "%-10s :   Observed   :   Expected   : Count\n", "Segment")
+	for _, segment := range spinner.segments {
+		fmt.Fprintf(
+			Output,
+			"%-10s : %10.*f%%  : %10.*f%%  : %d\n",
+			segment.Label,
+			Precision, Percent(res[segment.Label], spinner.numEvents),
+			Precision, segment.Weight*100/total,
+			res[segment.Label],
+		)
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Retrieve number of events
+//
+//	Returns
+//		int : number of events
+func (spinner Spinner) getNumEvents() int {
+	return spinner.numEvents
+}
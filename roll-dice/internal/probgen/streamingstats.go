@@ -0,0 +1,109 @@
+/*
+streamingstats.go
+
+Running mean and variance of numeric dice
+rolls, computed one roll at a time without
+keeping the rolls or a table of outcomes
+*/
+package probgen
+
+import (
+	"strconv"
+)
+
+// Running mean and variance of a stream of values, updated with Welford's
+// online algorithm so that enormous runs need constant memory
+type StreamingStats struct {
+	count int     // number of values added
+	mean  float64 // mean of the values added so far
+	m2    float64 // sum of squared differences from the mean
+}
+
+// Add the next value of the stream
+//
+//	Params
+//		value int : the value. Ex: a dice roll in [1, nSides]
+func (stats *StreamingStats) Add(value int) {
+	stats.count++
+	delta := float64(value) - stats.mean
+	stats.mean += delta / float64(stats.count)
+	stats.m2 += delta * (float64(value) - stats.mean)
+}
+
+// Number of values added so far
+//
+//	Returns
+//		int : number of values
+func (stats StreamingStats) Count() int {
+	return stats.count
+}
+
+// Mean of the values added so far
+//
+//	Returns
+//		float64 : the mean, 0 before any value is added
+func (stats StreamingStats) Mean() float64 {
+	return stats.mean
+}
+
+// Sample variance of the values added so far, as in FlipVariance
+//
+//	Returns
+//		float64 : sum of squared differences from the mean / (count - 1),
+//		          0 with fewer than two values
+func (stats StreamingStats) Variance() float64 {
+	if stats.count < 2 {
+		return 0
+	}
+
+	return stats.m2 / float64(stats.count-1)
+}
+
+// Reduce a stream of numeric outcomes into their running statistics,
+// without aggregating a table like consumeEvents
+//
+//	Params
+//		in chan string : input channel of numeric outcomes. Ex: "4"
+//	Returns
+//		StreamingStats : statistics of every outcome of the input channel
+func (pe ProbEvent) consumeStats(in chan string) StreamingStats {
+	stats := StreamingStats{}
+	tracker := newProgressTracker(pe.numEvents)
+
+	for event := range in {
+		// Dice outcomes are always numeric, see possibleDiceValues
+		value, _ := strconv.Atoi(event)
+		stats.Add(value)
+		tracker.add(1)
+	}
+
+	return stats
+}
+
+// Roll the dice and compute the mean and variance of the rolls as they are
+// made, for runs too large to revisit afterwards
+//
+//	Params
+//		numRolls int       : number of dice rolls
+//		numSides int       : number of sides to the dice
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		StreamingStats : statistics of the rolls
+//		error          : any errors encountered during validation
+func RollStats(numRolls int, numSides int, prng func(int) int) (StreamingStats, error) {
+	ok, err := validateAll(NewDiceRoll(numRolls, numSides))
+	if !ok {
+		return StreamingStats{}, err
+	}
+
+	pe := ProbEvent{
+		numEvents: numRolls,
+		outcomes:  possibleDiceValues(numSides),
+		prng:      prng}
+
+	events := make(chan string)
+
+	go pe.produceEvent(events)
+
+	return pe.consumeStats(events), nil
+}
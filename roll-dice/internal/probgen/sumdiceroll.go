@@ -0,0 +1,149 @@
+/*
+sumdiceroll.go
+
+SumDiceRoll is a ProbEventType which
+describes the sum of several dice rolled
+together, such as 2d6
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrInvalidNumDice = errors.New("invalid number of dice: must roll at least one die")
+
+type SumDiceRoll struct {
+	numEvents int           // number of times the dice are rolled together
+	numDice   int           // number of dice summed per event
+	numSides  int           // number of sides on each die
+	prng      func(int) int // The Pseudo Random Number Generator to use
+}
+
+// Initialize private fields
+//
+//	Params
+//		nEvents int : number of SumDiceRoll events
+//		nDice int   : number of dice summed per event
+//		nSides int  : number of sides to each die
+//	Returns
+//		*SumDiceRoll : new SumDiceRoll object
+func NewSumDiceRoll(nEvents int, nDice int, nSides int) *SumDiceRoll {
+	return &SumDiceRoll{
+		numEvents: nEvents,
+		numDice:   nDice,
+		numSides:  nSides,
+		prng:      RandNumGen,
+	}
+}
+
+func (sumDiceRoll SumDiceRoll) validate() (bool, error) {
+	if sumDiceRoll.numDice < 1 {
+		return false, ErrInvalidNumDice
+	}
+
+	//  Need to make sure the provided dice type is valid
+	if !validDiceType(sumDiceRoll.numSides) {
+		return false, ErrInvalidDiceType
+	}
+
+	return true, nil
+}
+
+func (sumDiceRoll SumDiceRoll) execute() (map[string]int, error) {
+	res := sumDiceRoll.roll()
+	sumDiceRoll.display(res)
+	recordRun(
+		strconv.Itoa(sumDiceRoll.numDice)+DiceEventType(sumDiceRoll.numSides),
+		sumDiceRoll.numEvents,
+		res)
+
+	return res, nil
+}
+
+// Roll the dice together numEvents times
+//
+//	Returns
+//		map[string]int : number of times each sum was rolled
+func (sumDiceRoll SumDiceRoll) roll() map[string]int {
+	sums := make([]string, sumDiceRoll.maxSum()-sumDiceRoll.numDice+1)
+	for i := range sums {
+		sums[i] = strconv.Itoa(sumDiceRoll.numDice + i)
+	}
+
+	pe := ProbEvent{
+		numEvents: sumDiceRoll.numEvents,
+		outcomes:  sums,
+		prng: func(int) int {
+			// Index of the sum among the outcomes
+			return RollSum(sumDiceRoll.numDice, sumDiceRoll.numSides, sumDiceRoll.prng) - sumDiceRoll.numDice
+		}}
+
+	return pe.computeProbability()
+}
+
+// Largest possible sum of the dice
+//
+//	Returns
+//		int : numDice * numSides
+func (sumDiceRoll SumDiceRoll) maxSum() int {
+	return sumDiceRoll.numDice * sumDiceRoll.numSides
+}
+
+// Roll several dice together and sum their values. This is the target
+// rolled in Shut the Box
+//
+//	Params
+//		numDice int        : number of dice to roll
+//		nSides int         : number of sides for each die
+//		prng func(int) int : the Pseudo Random Number Generator to use
+//	Returns
+//		int : sum of the dice values, in the range [numDice, numDice * nSides]
+func RollSum(numDice int, nSides int, prng func(int) int) int {
+	sum := 0
+	for i := 0; i < numDice; i++ {
+		sum += prng(nSides) + 1
+	}
+
+	return sum
+}
+
+// Print every possible sum of the dice with its results. Example:
+//
+// numEvents: 4
+//
+// numDice: 2, numSides: 4
+//
+// [2]  :   0.000000% : 0
+//
+// [3]  :  25.000000% : 1
+//
+// ...
+//
+// [8]  :  25.000000% : 1
+//
+//	Params
+//		res map[string]int : results of the summed dice rolls
+func (sumDiceRoll SumDiceRoll) display(res map[string]int) {
+	for sum := sumDiceRoll.numDice; sum <= sumDiceRoll.maxSum(); sum++ {
+		sum_s := strconv.Itoa(sum)
+		fmt.Fprintf(
+			Output,
+			"%-4s : %10.*f%% : %d\n",
+			"["+sum_s+"]",
+			Precision, Percent(res[sum_s], sumDiceRoll.numEvents),
+			res[sum_s],
+		)
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Retrieve number of events
+//
+//	Returns
+//		int : number of events
+func (sumDiceRoll SumDiceRoll) getNumEvents() int {
+	return sumDiceRoll.numEvents
+}
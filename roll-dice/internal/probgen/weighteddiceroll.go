@@ -0,0 +1,173 @@
+/*
+weighteddiceroll.go
+
+WeightedDiceRoll is a ProbEventType which
+describes rolls of a loaded dice whose
+faces have different relative weights
+*/
+package probgen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrNegativeFaceWeight = errors.New("invalid weighted dice: face weights must not be negative")
+var ErrZeroWeights = errors.New("invalid weighted dice: at least one face must have a positive weight")
+
+type WeightedDiceRoll struct {
+	numEvents int           // number of dice rolls
+	weights   []int         // relative weight of face i+1
+	prng      func(int) int // The Pseudo Random Number Generator to use
+}
+
+// Initialize private fields
+//
+//	Params
+//		nEvents int   : number of WeightedDiceRoll events
+//		weights []int : relative weight of each face. Ex: {1, 1, 1, 1, 1, 5}
+//	Returns
+//		*WeightedDiceRoll : new WeightedDiceRoll object
+func NewWeightedDiceRoll(nEvents int, weights []int) *WeightedDiceRoll {
+	return &WeightedDiceRoll{
+		numEvents: nEvents,
+		weights:   weights,
+		prng:      RandNumGen,
+	}
+}
+
+func (weightedDiceRoll WeightedDiceRoll) validate() (bool, error) {
+	// Faces may be impossible to roll, but not all of them
+	for i, weight := range weightedDiceRoll.weights {
+		if weight < 0 {
+			return false, fmt.Errorf("%w, face %d has weight %d", ErrNegativeFaceWeight, i+1, weight)
+		}
+	}
+
+	if weightedDiceRoll.totalWeight() == 0 {
+		return false, ErrZeroWeights
+	}
+
+	return true, nil
+}
+
+func (weightedDiceRoll WeightedDiceRoll) execute() (map[string]int, error) {
+	res := weightedDiceRoll.roll()
+	weightedDiceRoll.display(res)
+	recordRun(
+		"Weighted "+DiceEventType(len(weightedDiceRoll.weights)),
+		weightedDiceRoll.numEvents,
+		res)
+
+	return res, nil
+}
+
+// Roll the weighted dice numEvents times
+//
+//	Returns
+//		map[string]int : number of times each face was rolled
+func (weightedDiceRoll WeightedDiceRoll) roll() map[string]int {
+	pe := ProbEvent{
+		numEvents: weightedDiceRoll.numEvents,
+		outcomes:  weightedDiceRoll.faces(),
+		prng:      weightedDiceRoll.weightedPrng()}
+
+	return pe.computeProbability()
+}
+
+// Wrap the prng so that it selects a face index based on the weights
+// instead of uniformly over the outcomes
+//
+//	Returns
+//		func(int) int : prng compatible weighted face selection
+func (weightedDiceRoll WeightedDiceRoll) weightedPrng() func(int) int {
+	cumulative := weightedDiceRoll.cumulativeWeights()
+	total := cumulative[len(cumulative)-1]
+
+	return func(int) int {
+		position := weightedDiceRoll.prng(total)
+
+		return sort.Search(len(cumulative), func(i int) bool {
+			return cumulative[i] > position
+		})
+	}
+}
+
+// Accumulate the face weights
+//
+//	Ex: weights {1, 0, 2} -> {1, 1, 3}
+//
+//	Returns
+//		[]int : cumulative weights, the last being the total weight
+func (weightedDiceRoll WeightedDiceRoll) cumulativeWeights() []int {
+	cumulative := make([]int, len(weightedDiceRoll.weights))
+	running := 0
+
+	for i, weight := range weightedDiceRoll.weights {
+		running += weight
+		cumulative[i] = running
+	}
+
+	return cumulative
+}
+
+// Sum of all face weights
+//
+//	Returns
+//		int : total weight
+func (weightedDiceRoll WeightedDiceRoll) totalWeight() int {
+	total := 0
+	for _, weight := range weightedDiceRoll.weights {
+		total += weight
+	}
+
+	return total
+}
+
+// Faces of the dice numbered from 1
+//
+//	Returns
+//		[]string : Ex: {"1", "2", "3"} for 3 weights
+func (weightedDiceRoll WeightedDiceRoll) faces() []string {
+	return possibleDiceValues(len(weightedDiceRoll.weights))
+}
+
+// Print the weighted dice results with the expected frequencies. Example:
+//
+// numEvents: 4
+//
+// weights: {1, 3}
+//
+// Face :   Observed   :   Expected   : Count
+//
+// [1]  :   0.000000%  :  25.000000%  : 0
+//
+// [2]  : 100.000000%  :  75.000000%  : 4
+//
+//	Params
+//		res map[string]int : results of weighted dice rolls
+func (weightedDiceRoll WeightedDiceRoll) display(res map[string]int) {
+	total := weightedDiceRoll.totalWeight()
+
+	fmt.Fprint(Output, "Face :   Observed   :   Expected   : Count\n")
+	for i, face := range weightedDiceRoll.faces() {
+		fmt.Fprintf(
+			Output,
+			"%-4s : %10.*f%%  : %10.*f%%  : %d\n",
+			"["+face+"]",
+			Precision, Percent(res[face], weightedDiceRoll.numEvents),
+			Precision, Percent(weightedDiceRoll.weights[i], total),
+			res[face],
+		)
+	}
+	fmt.Fprint(Output, "\n")
+}
+
+// Retrieve number of events
+//
+//	Returns
+//		int : number of events
+func (weightedDiceRoll WeightedDiceRoll) getNumEvents() int {
+	return weightedDiceRoll.numEvents
+}
@@ -7,6 +7,8 @@ package testing_utils
 */
 import (
 	"bytes"
+	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"testing"
@@ -87,6 +89,46 @@ func CaptureAndRestoreOutput(r *os.File, w *os.File, origStdout *os.File) string
 	return buf.String()
 }
 
+// Redirect stdin to a pipe pre-filled with the given input so code reading
+// os.Stdin directly can be driven by tests. Called in conjunction with
+// RestoreStdin
+//
+//	Params
+//		input string : contents to be read from stdin
+//	Returns
+//		*os.File : original stdin for restoring later
+//		*os.File : read end of pipe
+func RedirectStdin(input string) (*os.File, *os.File) {
+	origStdin := os.Stdin
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		panic(err)
+	}
+
+	// Input is small enough to fit in the pipe buffer
+	_, err = w.WriteString(input)
+	if err != nil {
+		panic(err)
+	}
+	w.Close()
+
+	os.Stdin = r
+
+	return origStdin, r
+}
+
+// Restore stdin and close the read end of the pipe. Called in
+// conjunction with RedirectStdin
+//
+//	Params
+//		origStdin *os.File : original stdin to restore
+//		r *os.File         : read end of pipe to close
+func RestoreStdin(origStdin *os.File, r *os.File) {
+	os.Stdin = origStdin
+	r.Close()
+}
+
 // Assert that Expected == Actual. If false then
 // report an error
 //
@@ -129,6 +171,88 @@ func AssertEQb(t *testing.T, exp bool, act bool) {
 	}
 }
 
+// Assert that Expected == Actual within epsilon. If false then
+// report an error
+//
+//	Params
+//		t *testing.T    : needed for calling Errorf
+//		exp float64     : Expected value
+//		act float64     : Actual value
+//		epsilon float64 : largest allowed difference between exp and act
+func AssertEQf(t *testing.T, exp float64, act float64, epsilon float64) {
+	if !withinEpsilon(exp, act, epsilon) {
+		_, file, line, _ := runtime.Caller(1)
+		t.Errorf(AssertFailed, file, line, exp, act)
+	}
+}
+
+// Check whether two floats differ by no more than epsilon. NaN is never
+// within epsilon of anything
+//
+//	Params
+//		exp float64     : Expected value
+//		act float64     : Actual value
+//		epsilon float64 : largest allowed difference between exp and act
+//	Returns
+//		bool : true if |exp - act| <= epsilon
+func withinEpsilon(exp float64, act float64, epsilon float64) bool {
+	return math.Abs(exp-act) <= epsilon
+}
+
+// Assert that Expected == Actual element by element. Nil and empty
+// slices are not equal. If false then report an error along with where
+// the slices first differ
+//
+//	Params
+//		t *testing.T : needed for calling Errorf
+//		exp []T      : Expected value
+//		act []T      : Actual value
+func AssertEQSlice[T comparable](t *testing.T, exp []T, act []T) {
+	if diff := sliceDiff(exp, act); diff != "" {
+		_, file, line, _ := runtime.Caller(1)
+		t.Errorf(AssertFailed+"%s\n", file, line, sliceString(exp), sliceString(act), diff)
+	}
+}
+
+// Describe the first difference between two slices
+//
+//	Params
+//		exp []T : Expected value
+//		act []T : Actual value
+//	Returns
+//		string : the difference, empty if the slices are equal
+func sliceDiff[T comparable](exp []T, act []T) string {
+	if (exp == nil) != (act == nil) {
+		return fmt.Sprintf("nil mismatch : expected %s, actual %s", sliceString(exp), sliceString(act))
+	}
+
+	if len(exp) != len(act) {
+		return fmt.Sprintf("length mismatch : expected %d, actual %d", len(exp), len(act))
+	}
+
+	for i := range exp {
+		if exp[i] != act[i] {
+			return fmt.Sprintf("first difference at index %d : expected %v, actual %v", i, exp[i], act[i])
+		}
+	}
+
+	return ""
+}
+
+// Format a slice, distinguishing nil from empty
+//
+//	Params
+//		s []T : slice to format
+//	Returns
+//		string : "nil" for a nil slice, otherwise its values. Ex: "[1 2 3]"
+func sliceString[T any](s []T) string {
+	if s == nil {
+		return "nil"
+	}
+
+	return fmt.Sprint(s)
+}
+
 // Assert that err is nil, ie no error occurred. If false then
 // report an error
 //
@@ -0,0 +1,45 @@
+package testing_utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAssertEQf(t *testing.T) {
+	// Floats are equal when within epsilon of each other
+
+	AssertEQf(t, 0.5, 0.5, 0)
+	AssertEQf(t, 33.33, 100.0/3, 0.01)
+	AssertEQf(t, -1.0, -1.0005, 0.001)
+
+	AssertEQb(t, true, withinEpsilon(1.0, 1.1, 0.1+1e-9))
+	AssertEQb(t, false, withinEpsilon(1.0, 1.2, 0.1))
+	AssertEQb(t, false, withinEpsilon(33.3, 100.0/3, 0.01))
+	AssertEQb(t, false, withinEpsilon(math.NaN(), math.NaN(), 1))
+}
+
+func TestAssertEQSlice(t *testing.T) {
+	// Slices are equal when they match element by element
+
+	AssertEQSlice(t, []int{1, 2, 3}, []int{1, 2, 3})
+	AssertEQSlice(t, []string{}, []string{})
+	AssertEQSlice[string](t, nil, nil)
+
+	AssertEQ(t, "", sliceDiff([]string{"a"}, []string{"a"}))
+	AssertEQ(
+		t,
+		"length mismatch : expected 3, actual 2",
+		sliceDiff([]int{1, 2, 3}, []int{1, 2}))
+	AssertEQ(
+		t,
+		"first difference at index 1 : expected 2, actual 5",
+		sliceDiff([]int{1, 2, 3}, []int{1, 5, 3}))
+	AssertEQ(
+		t,
+		"nil mismatch : expected nil, actual []",
+		sliceDiff(nil, []int{}))
+	AssertEQ(
+		t,
+		"nil mismatch : expected [], actual nil",
+		sliceDiff([]int{}, nil))
+}
@@ -1,19 +1,66 @@
 package utilities
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"os"
 	"strconv"
+	"strings"
 )
 
+/// Input Commands
+
+// Kind of input entered by the user
+type InputCmd int
+
+const (
+	CmdValue InputCmd = iota // regular input value
+	CmdDone                  // empty input, stop the current operation
+	CmdQuit                  // quit the entire program
+	CmdEOF                   // input closed, no more input will arrive
+)
+
+// Inputs recognized as a request to quit the entire program
+var QuitCmds = []string{"quit", ":q"}
+
+// Whether the user is asked to confirm before quitting
+var ConfirmQuit = true
+
+// Terminates the program. Replaced in tests to observe termination
+var Exit = os.Exit
+
+// Number of consecutive invalid inputs a prompt accepts before aborting
+var MaxAttempts = 10
+
+const ErrTooManyAttempts = "aborting after %d consecutive invalid inputs"
+const ErrExpectedInt = "syntax error: expected integer"
+const ErrOutOfRange = "invalid input '%d': must be in range [%d,%d]"
+const ErrBelowMin = "invalid input '%d': must be at least %d"
+
+// Check whether a prompt has seen too many consecutive invalid inputs and
+// should abort instead of prompting again
+//
+//	Params
+//		attempts int : consecutive invalid inputs so far
+//	Returns
+//		error : ErrTooManyAttempts once MaxAttempts is reached, otherwise nil
+func CheckAttempts(attempts int) error {
+	if attempts >= MaxAttempts {
+		return fmt.Errorf(ErrTooManyAttempts, attempts)
+	}
+
+	return nil
+}
+
 // Process user number input
 //
 //	Params
 //		stdin io.Reader : holds user input
 //
 //	Returns
-//		bool  : true if user indicates they are done
+//		bool  : true if user indicates they are done, or input is closed
 //		int   : option as number
 //		error : any error encountered by string to int conversion
 func ProcessInputInt(stdin io.Reader) (bool, int, error) {
@@ -28,23 +75,205 @@ func ProcessInputInt(stdin io.Reader) (bool, int, error) {
 	return false, input_i, err
 }
 
+// Process user number input that must fall within [min,max]. A max of
+// math.MaxInt leaves the input unbounded above
+//
+//	Params
+//		stdin io.Reader : holds user input
+//		min int         : lowest accepted value
+//		max int         : highest accepted value
+//
+//	Returns
+//		bool  : true if user indicates they are done, or input is closed
+//		int   : the input as number, -1 on error
+//		error : ErrExpectedInt for non-numeric input, ErrOutOfRange or
+//		        ErrBelowMin for a number outside [min,max]
+func ProcessInputIntRange(stdin io.Reader, min int, max int) (done bool, value int, err error) {
+	done, value, err = ProcessInputInt(stdin)
+	if done {
+		return true, -1, nil
+	}
+
+	if err != nil {
+		return false, -1, errors.New(ErrExpectedInt)
+	}
+
+	if value < min || value > max {
+		if max == math.MaxInt {
+			return false, -1, fmt.Errorf(ErrBelowMin, value, min)
+		}
+
+		return false, -1, fmt.Errorf(ErrOutOfRange, value, min, max)
+	}
+
+	return false, value, nil
+}
+
 // Process user string input
 //
 //	Params
 //		stdin io.Reader : holds user input
 //
 //	Returns
-//		bool   : true if user indicates they are done
+//		bool   : true if user indicates they are done, or input is closed
 //		string : option as number
 func ProcessInputStr(stdin io.Reader) (bool, string) {
-	scanner := bufio.NewScanner(stdin)
-	scanner.Scan()
-	if scanner.Text() == "" {
-		// User is done providing inputs
-		fmt.Print("Stopping current operation\n")
-		return true, ""
-	}
-	// Add extra space after input to avoid clutter
-	fmt.Print("\n")
-	return false, scanner.Text()
+	cmd, input := ProcessInputCmd(stdin)
+
+	return cmd != CmdValue, input
+}
+
+// Answers accepted by yes or no questions, matched case-insensitively
+var yesNoAnswers = map[string]bool{"y": true, "yes": true, "n": false, "no": false}
+
+// Ask the user a yes or no question. Answers are case-insensitive and
+// invalid inputs prompt again, up to MaxAttempts times
+//
+//	Params
+//		stdin io.Reader  : holds user input
+//		question string : question asked. Ex: "Would you like to keep playing?"
+//	Returns
+//		bool : true if user indicates they are done, or after too many
+//		       invalid inputs
+//		bool : true if the user answers 'y' or 'yes'
+func PromptYesNo(stdin io.Reader, question string) (done bool, yes bool) {
+	for attempts := 1; ; attempts++ {
+		fmt.Printf("%s [y/n]\n", question)
+		done, input := ProcessInputStr(stdin)
+
+		// Inform caller we are done
+		if done {
+			return true, false
+		}
+
+		if yes, ok := yesNoAnswers[strings.ToLower(input)]; ok {
+			return false, yes
+		}
+
+		fmt.Printf("input error: expected 'y' or 'n'\n")
+		if err := CheckAttempts(attempts); err != nil {
+			// Too many invalid inputs, inform caller we are done
+			fmt.Print(err.Error() + "\n")
+			return true, false
+		}
+	}
+}
+
+// Process user input and classify it as a value or a command. A quit
+// command terminates the program through Exit once confirmed. A blank line
+// is the user being done, while a closed or failing input is CmdEOF
+//
+//	Params
+//		stdin io.Reader : holds user input
+//
+//	Returns
+//		InputCmd : the kind of input entered
+//		string   : the input value, empty for commands
+func ProcessInputCmd(stdin io.Reader) (InputCmd, string) {
+	for {
+		input, eof := readLine(stdin)
+
+		switch {
+		case eof:
+			// Nothing more can be read, callers must not prompt again
+			fmt.Print("End of input reached\n")
+			return CmdEOF, ""
+		case input == "":
+			// User is done providing inputs
+			fmt.Print("Stopping current operation\n")
+			return CmdDone, ""
+		case isQuitCmd(input):
+			quit, ended := true, false
+			if ConfirmQuit {
+				quit, ended = confirmQuit(stdin)
+			}
+
+			if ended {
+				// No answer can arrive, callers must not prompt again
+				fmt.Print("End of input reached\n")
+				return CmdEOF, ""
+			}
+
+			if quit {
+				fmt.Print("Quitting now\n")
+				Exit(0)
+				// Only reached when Exit is replaced, unwind to the caller
+				return CmdQuit, ""
+			}
+
+			fmt.Print("Quit cancelled. Please enter your input again:\n")
+		default:
+			// Add extra space after input to avoid clutter
+			fmt.Print("\n")
+			return CmdValue, input
+		}
+	}
+}
+
+// Check whether the input is one of the QuitCmds
+//
+//	Params
+//		input string : user input
+//	Returns
+//		bool : true if the input requests to quit
+func isQuitCmd(input string) bool {
+	for _, cmd := range QuitCmds {
+		if input == cmd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ask the user to confirm they want to quit the program. Answers are
+// case-insensitive like PromptYesNo, but anything other than yes cancels
+// instead of prompting again
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		bool : true only if the user answers 'y' or 'yes'
+//		bool : true if the input ended before an answer was given
+func confirmQuit(stdin io.Reader) (bool, bool) {
+	fmt.Print("Are you sure you want to quit? [y/n]\n")
+
+	input, ended := readLine(stdin)
+	if ended {
+		return false, true
+	}
+
+	return yesNoAnswers[strings.ToLower(input)], false
+}
+
+// Read a single line of input without its line ending. Reads one byte at a
+// time so no input past the line is consumed, which lets several prompts
+// share the same piped or scripted input
+//
+//	Params
+//		stdin io.Reader : holds user input
+//	Returns
+//		string : the line, empty at the end of the input
+//		bool   : true if the input ended or failed before any of the line
+//		         was read, as opposed to a blank line
+func readLine(stdin io.Reader) (string, bool) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := stdin.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+
+			line = append(line, b[0])
+		}
+
+		if err != nil {
+			// A final line without a line ending is still a line
+			return strings.TrimSuffix(string(line), "\r"), len(line) == 0
+		}
+	}
+
+	return strings.TrimSuffix(string(line), "\r"), false
 }
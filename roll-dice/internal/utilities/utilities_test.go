@@ -0,0 +1,122 @@
+package utilities
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/romansod/roll-dice/internal/testing_utils"
+)
+
+func TestPromptYesNo(t *testing.T) {
+	// Yes or no answers are case-insensitive, anything else prompts again
+
+	origStdout, ignoreOut := testing_utils.IgnoreStdout()
+
+	cases := []struct {
+		input string
+		done  bool
+		yes   bool
+	}{
+		{"y\n", false, true},
+		{"n\n", false, false},
+		{"YES\n", false, true},
+		{"No\n", false, false},
+		{"\n", true, false},
+		{"maybe\nyes\n", false, true},
+		{"1\n2\nn\n", false, false},
+	}
+
+	for _, c := range cases {
+		stdin := bytes.NewBufferString(c.input)
+		done, yes := PromptYesNo(stdin, "Continue?")
+		testing_utils.AssertEQb(t, c.done, done)
+		testing_utils.AssertEQb(t, c.yes, yes)
+		testing_utils.AssertEQi(t, 0, stdin.Len())
+	}
+
+	// Too many invalid answers is done
+	origMax := MaxAttempts
+	MaxAttempts = 2
+	done, yes := PromptYesNo(bytes.NewBufferString("a\nb\ny\n"), "Continue?")
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertEQb(t, false, yes)
+	MaxAttempts = origMax
+
+	testing_utils.IgnoreStdoutClose(origStdout, ignoreOut)
+
+	// The question is asked again after an invalid answer
+	origStdout, r, w := testing_utils.RedirectStdout()
+	PromptYesNo(bytes.NewBufferString("maybe\ny\n"), "Continue?")
+	output := testing_utils.CaptureAndRestoreOutput(r, w, origStdout)
+	testing_utils.AssertEQ(
+		t,
+		"Continue? [y/n]\n\ninput error: expected 'y' or 'n'\nContinue? [y/n]\n\n",
+		output)
+}
+
+func TestProcessInputIntRange(t *testing.T) {
+	// Integers are accepted only within the bounds
+
+	// In range, including both bounds
+	for _, input := range []string{"1\n", "3\n", "6\n"} {
+		done, value, err := ProcessInputIntRange(bytes.NewBufferString(input), 1, 6)
+		testing_utils.AssertNIL(t, err)
+		testing_utils.AssertEQb(t, false, done)
+		testing_utils.AssertEQ(t, input, fmt.Sprintf("%d\n", value))
+	}
+
+	// Below range
+	done, value, err := ProcessInputIntRange(bytes.NewBufferString("0\n"), 1, 6)
+	testing_utils.AssertEQb(t, false, done)
+	testing_utils.AssertEQi(t, -1, value)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrOutOfRange, 0, 1, 6), err.Error())
+
+	// Above range
+	_, _, err = ProcessInputIntRange(bytes.NewBufferString("7\n"), 1, 6)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrOutOfRange, 7, 1, 6), err.Error())
+
+	// Unbounded above only reports the min
+	_, value, err = ProcessInputIntRange(bytes.NewBufferString("1000000\n"), 1, math.MaxInt)
+	testing_utils.AssertNIL(t, err)
+	testing_utils.AssertEQi(t, 1000000, value)
+	_, _, err = ProcessInputIntRange(bytes.NewBufferString("-2\n"), 1, math.MaxInt)
+	testing_utils.AssertEQ(t, fmt.Sprintf(ErrBelowMin, -2, 1), err.Error())
+
+	// Non-numeric is a syntax error, not a range error
+	_, value, err = ProcessInputIntRange(bytes.NewBufferString("six\n"), 1, 6)
+	testing_utils.AssertEQi(t, -1, value)
+	testing_utils.AssertEQ(t, ErrExpectedInt, err.Error())
+
+	// Done
+	done, _, err = ProcessInputIntRange(bytes.NewBufferString("\n"), 1, 6)
+	testing_utils.AssertEQb(t, true, done)
+	testing_utils.AssertNIL(t, err)
+}
+
+func TestReadLine(t *testing.T) {
+	// Lines are read one at a time from scripted input, so several prompts
+	// share the same reader
+
+	stdin := bytes.NewBufferString("flip\r\n10\n\nlast")
+
+	for _, expected := range []string{"flip", "10", ""} {
+		line, ended := readLine(stdin)
+		testing_utils.AssertEQ(t, expected, line)
+		testing_utils.AssertEQb(t, false, ended)
+	}
+
+	// Nothing past the line is consumed
+	testing_utils.AssertEQ(t, "last", stdin.String())
+
+	// A final line without a line ending is still a line
+	line, ended := readLine(stdin)
+	testing_utils.AssertEQ(t, "last", line)
+	testing_utils.AssertEQb(t, false, ended)
+
+	// Ended input is not a blank line
+	line, ended = readLine(stdin)
+	testing_utils.AssertEQ(t, "", line)
+	testing_utils.AssertEQb(t, true, ended)
+}
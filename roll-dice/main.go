@@ -1,21 +1,92 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
 	"os"
 
+	"github.com/romansod/roll-dice/internal/history"
 	"github.com/romansod/roll-dice/internal/options"
+	"github.com/romansod/roll-dice/internal/probgen"
 )
 
 const instructions string = "\nSelect the menu option using the associated\n" +
 	"integer. Additionally, an empty input\n" +
 	"indicates you are 'done' while executing an\n" +
-	"operation, returning execution to the main menu\n\n"
+	"operation, returning execution to the main menu.\n" +
+	"Enter 'quit' or ':q' at any prompt to quit\n\n"
+
+// Register the flags setting the display and game options of the packages
+//
+//	Params
+//		flags *flag.FlagSet : flag set to register with. Ex: flag.CommandLine
+func registerFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&options.VerboseMenu, "verbose", false, "describe each option in the menu")
+	flags.BoolVar(&options.VerboseShutTheBox, "verbose-box", false, "print each Shut the Box die and move")
+	flags.BoolVar(&options.TimedShutTheBox, "timed-box", false, "time every Shut the Box move and print each player's total")
+	flags.BoolVar(&options.HighlightShutTheBox, "highlight-box", false, "color the Shut the Box slots closed by the last move")
+	flags.BoolVar(&probgen.UseGlyphs, "glyphs", false, "draw single D6 rolls as a Unicode die face")
+	flags.BoolVar(&probgen.LogConvergence, "log-convergence", false, "show Coin Convergence at every power of 10 flips")
+	flags.BoolVar(&probgen.ShowCDF, "cdf", false, "show the cumulative percent of each face below dice roll results")
+	flags.BoolVar(&probgen.ShowChiSquare, "chi-square", false, "show the chi-square goodness of fit below coin flip and dice roll results")
+	flags.IntVar(&probgen.Precision, "precision", probgen.DefaultPrecision, "decimal places of printed percentages")
+	flags.IntVar(&options.MaxPlayers, "max-players", options.DefaultMaxPlayers, "largest number of Shut the Box players, including AI")
+}
 
 func main() {
+	registerFlags(flag.CommandLine)
+	maxEvents := flag.Int("max-events", probgen.DefaultMaxEvents, "largest number of flips or rolls in a single run")
+	script := flag.String("script", "", "read all input from this file instead of stdin")
+	op := flag.String("op", "", "run a single operation and exit instead of the menu: "+options.OpFlip+" or "+options.OpRoll)
+	sides := flag.Int("sides", probgen.D6, "number of dice sides for -op "+options.OpRoll)
+	events := flag.Int("n", 1, "number of flips or rolls for -op")
+	flag.Parse()
+
+	if probgen.Precision < 0 {
+		log.Fatalf("invalid precision '%d': must not be negative", probgen.Precision)
+	}
+
+	probgen.SetMaxEvents(*maxEvents)
+
+	if options.MaxPlayers < 1 {
+		log.Fatalf("invalid max players '%d': must have at least one player", options.MaxPlayers)
+	}
+
+	// Keep the persisted run history within its retention limit
+	settings := history.DefaultSettings
+	if err := history.PruneHistory(settings.Path, settings.MaxRecords); err != nil {
+		log.Printf("failed to prune history '%s': %v", settings.Path, err)
+	}
+
+	// Persist every completed run
+	probgen.RecordRun = settings.Record
+
+	// Show long runs are not stuck
+	probgen.Progress = probgen.PrintProgress
+
+	// A single operation for scripts, no menu
+	if *op != "" {
+		if _, err := options.RunOperation(*op, *sides, *events); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
 	fmt.Print("--------------- Welcome ---------------\n")
 	fmt.Print(instructions)
 
-	options.Menu()
-	os.Exit(0)
+	if *script == "" {
+		options.Menu()
+		os.Exit(0)
+	}
+
+	// Drive the whole session from the script, ex: for demos
+	file, err := os.Open(*script)
+	if err != nil {
+		log.Fatalf("failed to open script '%s': %v", *script, err)
+	}
+	defer file.Close()
+
+	options.MenuWith(file)
 }
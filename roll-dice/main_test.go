@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/romansod/roll-dice/internal/probgen"
+	"github.com/romansod/roll-dice/internal/testing_utils"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	// Display options are set from the command line
+
+	defer func() {
+		probgen.ShowCDF = false
+		probgen.ShowChiSquare = false
+		probgen.Precision = probgen.DefaultPrecision
+	}()
+
+	flags := flag.NewFlagSet("roll-dice", flag.ContinueOnError)
+	registerFlags(flags)
+	testing_utils.AssertNIL(t, flags.Parse([]string{"-cdf", "-chi-square", "-precision", "2"}))
+	testing_utils.AssertEQb(t, true, probgen.ShowCDF)
+	testing_utils.AssertEQb(t, true, probgen.ShowChiSquare)
+	testing_utils.AssertEQi(t, 2, probgen.Precision)
+
+	// Off unless given
+	flags = flag.NewFlagSet("roll-dice", flag.ContinueOnError)
+	registerFlags(flags)
+	testing_utils.AssertNIL(t, flags.Parse([]string{}))
+	testing_utils.AssertEQb(t, false, probgen.ShowCDF)
+	testing_utils.AssertEQb(t, false, probgen.ShowChiSquare)
+}